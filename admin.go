@@ -0,0 +1,108 @@
+package aralog
+
+import (
+    "encoding/json"
+    "net/http"
+)
+
+type levelBody struct {
+    Logger string `json:"logger,omitempty"`
+    Level  string `json:"level"`
+}
+
+// LevelHandler returns an http.Handler reporting and changing the levels of
+// the registered loggers at runtime, to be mounted on an admin mux:
+//
+//	mux.Handle("/loglevel", aralog.LevelHandler())
+//
+// GET returns the levels of all loggers as a JSON object, or with
+// ?logger=name the level of one logger. PUT sets the level given as
+// ?level=debug or as the JSON body {"level":"debug"} on the logger named by
+// ?logger=name, or on all registered loggers without it.
+func LevelHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        name := r.URL.Query().Get("logger")
+        var targets []*Logger
+        if len(name) > 0 {
+            l := Lookup(name)
+            if l == nil {
+                http.Error(w, "unknown logger " + name, http.StatusNotFound)
+                return
+            }
+            targets = []*Logger{l}
+        }
+
+        switch r.Method {
+        case http.MethodGet:
+        case http.MethodPut:
+            body := levelBody{Level: r.URL.Query().Get("level")}
+            if len(body.Level) == 0 {
+                if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+                    http.Error(w, "invalid body: " + err.Error(), http.StatusBadRequest)
+                    return
+                }
+            }
+            level, err := ParseLevel(body.Level)
+            if err != nil {
+                http.Error(w, err.Error(), http.StatusBadRequest)
+                return
+            }
+            if targets == nil {
+                SetGlobalLevel(level)
+            }
+            for _, l := range targets {
+                l.SetLevel(level)
+            }
+        default:
+            w.Header().Set("Allow", "GET, PUT")
+            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+            return
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        if len(name) > 0 {
+            json.NewEncoder(w).Encode(levelBody{Logger: name, Level: targets[0].Level().String()})
+            return
+        }
+        levels := map[string]string{}
+        for _, n := range LoggerNames() {
+            if l := Lookup(n); l != nil {
+                levels[n] = l.Level().String()
+            }
+        }
+        json.NewEncoder(w).Encode(levels)
+    })
+}
+
+type healthBody struct {
+    Healthy bool   `json:"healthy"`
+    Error   string `json:"error,omitempty"`
+}
+
+// HealthHandler returns an http.Handler for readiness probes: it responds
+// 200 if the sinks of all registered loggers are healthy and 503 otherwise,
+// with the state of each logger as a JSON object.
+func HealthHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        status := http.StatusOK
+        states := map[string]healthBody{}
+        for _, name := range LoggerNames() {
+            l := Lookup(name)
+            if l == nil {
+                continue
+            }
+            h := healthBody{Healthy: l.Healthy()}
+            if !h.Healthy {
+                status = http.StatusServiceUnavailable
+                if err := SinkLastError(l.Sink()); err != nil {
+                    h.Error = err.Error()
+                }
+            }
+            states[name] = h
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(status)
+        json.NewEncoder(w).Encode(states)
+    })
+}
@@ -0,0 +1,43 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	db := New(ioutil.Discard, "", 0)
+	Register("test.db", db)
+	defer Unregister("test.db")
+	srv := httptest.NewServer(LevelHandler())
+	defer srv.Close()
+
+	req, _ := http.NewRequest("PUT", srv.URL+"?logger=test.db", strings.NewReader(`{"level":"warn"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if db.Level() != LevelWarn {
+		t.Fatalf("expected WARN, got %v", db.Level())
+	}
+
+	resp, err = http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(b), `"test.db":"WARN"`) {
+		t.Errorf("unexpected levels %s", b)
+	}
+
+	resp, _ = http.Get(srv.URL + "?logger=missing")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
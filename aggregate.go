@@ -0,0 +1,137 @@
+package aralog
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// OccurrencesKey is the key of the field holding the number of errors in a
+// summary entry of an ErrorAggregator.
+const OccurrencesKey = "occurrences"
+
+// ErrorAggregator keeps error storms readable: within each window, only the
+// first error entry of a group is written, the others are counted and
+// summarized at the end of the window by one error entry such as
+// "db timeout occurred 1,204 times in the last 5m0s". Errors are grouped by
+// call site if the logger records it, and by message with digits ignored,
+// so "user 42 not found" and "user 7 not found" are one group.
+type ErrorAggregator struct {
+    mu       sync.Mutex
+    logger   *Logger
+    window   time.Duration
+    groups   map[string]*errorGroup
+    order    int // of the next new group
+    stop     chan struct{}
+    stopOnce sync.Once
+}
+
+type errorGroup struct {
+    message string // of the first entry
+    count   uint64
+    order   int
+}
+
+// AggregateErrors installs an ErrorAggregator on l with the given window.
+// Loggers created from l by With afterwards share it.
+func AggregateErrors(l *Logger, window time.Duration) *ErrorAggregator {
+    a := &ErrorAggregator{logger: l, window: window, groups: map[string]*errorGroup{}, stop: make(chan struct{})}
+    l.Use(a.aggregate)
+    go a.run()
+    return a
+}
+
+func (a *ErrorAggregator) run() {
+    ticker := time.NewTicker(a.window)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-a.stop:
+            return
+        case <-ticker.C:
+            a.Flush()
+        }
+    }
+}
+
+// aggregate is the Middleware keeping the first error of each group.
+func (a *ErrorAggregator) aggregate(e *Entry) bool {
+    if e.Level != LevelError {
+        return true
+    }
+    for _, f := range e.Fields {
+        if f.Key == OccurrencesKey {
+            return true // a summary
+        }
+    }
+
+    key := templateKey(e.Message)
+    if e.File != "" {
+        key = e.File + ":" + strconv.Itoa(e.Line) + " " + key
+    }
+
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    g := a.groups[key]
+    if g == nil {
+        g = &errorGroup{message: e.Message, order: a.order}
+        a.order++
+        a.groups[key] = g
+    }
+    g.count++
+    return g.count == 1
+}
+
+// templateKey returns s with the runs of digits replaced by #.
+func templateKey(s string) string {
+    b := make([]byte, 0, len(s))
+    for i := 0; i < len(s); i++ {
+        if s[i] < '0' || s[i] > '9' {
+            b = append(b, s[i])
+        } else if i == 0 || s[i - 1] < '0' || s[i - 1] > '9' {
+            b = append(b, '#')
+        }
+    }
+    return string(b)
+}
+
+// Flush writes the summaries of the groups with suppressed errors and
+// starts a new window.
+func (a *ErrorAggregator) Flush() {
+    a.mu.Lock()
+    groups := make([]*errorGroup, 0, len(a.groups))
+    for _, g := range a.groups {
+        if g.count > 1 {
+            groups = append(groups, g)
+        }
+    }
+    a.groups = map[string]*errorGroup{}
+    a.order = 0
+    a.mu.Unlock()
+
+    sort.Slice(groups, func(i, j int) bool { return groups[i].order < groups[j].order })
+    for _, g := range groups {
+        msg := fmt.Sprintf("%s occurred %s times in the last %v", g.message, groupDigits(g.count), a.window)
+        a.logger.outputFields(2, LevelError, msg, []Field{F(OccurrencesKey, g.count)})
+    }
+}
+
+// Stop stops the aggregation windows and writes the pending summaries.
+// Errors are still grouped, until the next Flush.
+func (a *ErrorAggregator) Stop() {
+    a.stopOnce.Do(func() {
+        close(a.stop)
+        a.Flush()
+    })
+}
+
+// groupDigits formats n with commas between groups of three digits.
+func groupDigits(n uint64) string {
+    s := strconv.FormatUint(n, 10)
+    for i := len(s) - 3; i > 0; i -= 3 {
+        s = s[:i] + "," + s[i:]
+    }
+    return s
+}
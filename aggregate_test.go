@@ -0,0 +1,41 @@
+package aralog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestAggregateErrors(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	a := AggregateErrors(logger, time.Hour)
+	defer a.Stop()
+
+	for i := 0; i < 1204; i++ {
+		logger.Error("user %d not found", i)
+	}
+	logger.Error("db down")
+	logger.Warn("slow")
+	logger.Warn("slow")
+	a.Flush()
+	logger.Error("user 1 not found")
+
+	want := "user 0 not found\n" +
+		"db down\n" +
+		"slow\n" +
+		"slow\n" +
+		"user 0 not found occurred 1,204 times in the last 1h0m0s occurrences=1204\n" +
+		"user 1 not found\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestGroupDigits(t *testing.T) {
+	for n, want := range map[uint64]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567"} {
+		if got := groupDigits(n); got != want {
+			t.Errorf("groupDigits(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
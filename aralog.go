@@ -1,13 +1,15 @@
 package aralog
 
 import (
+    "bytes"
     "io"
     "os"
     "runtime"
+    "strconv"
+    "strings"
     "sync"
+    "sync/atomic"
     "time"
-    "strings"
-    "path/filepath"
     "fmt"
 )
 
@@ -22,32 +24,107 @@ const (
     Lmicroseconds                 // microsecond resolution: 01:23:23.123123.  assumes Ltime.
     Llongfile                     // full file name and line number: /a/b/c/d.go:23
     Lshortfile                    // final file name element and line number: d.go:23. overrides Llongfile
+    LUTC                          // if Ldate or Ltime is set, use UTC rather than the local time zone
+    LRFC3339                      // the date and time as RFC3339: 2009-01-23T01:23:23+08:00. overrides Ldate and Ltime
+    LEpochMillis                  // milliseconds since the Unix epoch: 1232673803123. overrides the formatted time flags
+    LEpochNanos                   // nanoseconds since the Unix epoch: 1232673803123123123. overrides LEpochMillis
+    Lmsgprefix                    // move the prefix from the beginning of the line to before the message
+    Lfuncname                     // calling function with its package name: db.(*Conn).Query
+    Llongfuncname                 // calling function with its full import path: github.com/a/b/db.(*Conn).Query. overrides Lfuncname
+    Lgoroutine                    // id of the logging goroutine: g42
+    Lhostname                     // host name of the machine: web01
+    Lpid                          // process id, syslog style after the host name if any: web01[1234]
+    Lseq                          // sequence number of the entry in this logger, starting at 1: #42
+    Lelapsed                      // time elapsed since the logger was created: +00:03:12.450
+    Ldelta                        // time elapsed since the previous entry of the logger: +1.204s
+    Lcallerlast                   // move file and line to the end of the line: message (d.go:23)
+    Lsanitize                     // escape newlines and control characters in the message: a\nb
     LstdFlags = Ldate | Ltime // initial values for the standard logger
 )
 
+// process information for Lhostname and Lpid, looked up once.
+var (
+    pid      = os.Getpid()
+    hostname = lookupHostname()
+)
+
+func lookupHostname() string {
+    h, err := os.Hostname()
+    if err != nil {
+        return "localhost"
+    }
+    return h
+}
+
+// HeaderHook appends custom tokens to the header of an entry, after the
+// tokens controlled by the flags and before the message. The entry has no
+// Formatted line yet.
+type HeaderHook func(buf *[]byte, e Entry)
+
+// rfc3339Micro is RFC3339 with a fixed width microsecond fraction, used when
+// both LRFC3339 and Lmicroseconds are set.
+const rfc3339Micro = "2006-01-02T15:04:05.000000Z07:00"
+
 // A Logger represents an active logging object that generates lines of
-// output to an io.Writer.  Each logging operation makes a single call to
-// the Writer's Write method.  A Logger can be used simultaneously from
-// multiple goroutines; it guarantees to serialize access to the Writer.
+// output to a Sink.  Each logging operation makes a single call to
+// the Sink's Write method.  A Logger can be used simultaneously from
+// multiple goroutines; it guarantees to serialize access to the Sink.
 type Logger struct {
-    mu      sync.Mutex // ensures atomic writes; protects the following fields
-    prefix  string     // prefix to write at beginning of each line
-    flag    int        // properties
-    out     io.Writer  // destination for output
-    buf     []byte     // for accumulating text to write
-    size    uint       // current size of log file
-    path    string     // file path if output to a file
-    maxsize uint       // minimal maxsize should >= 1MB
+    level       int32                // minimal Level written, accessed atomically
+    pkgMin      int32                // minimal Level of pkgLevels, accessed atomically
+    hasEnabler  int32                // 1 if enabler is set, accessed atomically
+    mu          sync.Mutex           // ensures atomic writes; protects the following fields
+    prefix      string               // prefix to write at beginning of each line
+    flag        int                  // properties
+    sink        Sink                 // destination for output
+    buf         []byte               // for accumulating text to write
+    layout      string               // custom time layout, overrides the time flags if set
+    loc         *time.Location       // time zone of the header, nil for local time
+    seq         uint64               // sequence number of the last entry
+    start       time.Time            // creation time, for Lelapsed
+    last        time.Time            // time of the previous entry, for Ldelta
+    fields      []Field              // fields attached to every entry
+    hook        HeaderHook           // appends custom header tokens, may be nil
+    enc         Encoder              // renders entries instead of the text format, may be nil
+    maxlen      int                  // max characters of a message, 0 for no limit
+    name        string               // name the logger is registered under
+    pkgLevels   []packageLevel       // levels by package, longest prefix first
+    filter      MessageFilter        // drops entries by message
+    rule        *Rule                // drops entries not matching it, may be nil
+    enabler     LevelEnabler         // decides instead of the levels, may be nil
+    debugTimer  *time.Timer          // restores the level after EnableDebugFor
+    debugPrev   Level                // level before EnableDebugFor
+    stats       *counters            // entries of the logger and its children
+    audit       bool                 // write every entry, set at creation only
+    redact      []Redaction          // applied to every message
+    fieldMasks  map[string]FieldMask // masks of redacted field keys, lowercase; replaced, never modified
+    keepSecrets bool                 // don't mask secrets in messages
+    utf8Mode    UTF8Mode             // handling of invalid UTF-8 in messages and string fields
+    hooks       []boundHook          // called with every entry; replaced, never modified
+    middleware  []Middleware         // may change or drop every entry; replaced, never modified
+    clock       func() time.Time     // time source, time.Now if nil
 }
 
-var currentOutFile *os.File
-
 // New creates a new Logger.   The out variable sets the
 // destination to which log data will be written.
 // The prefix appears at the beginning of each generated log line.
 // The flag argument defines the logging properties.
 func New(out io.Writer, prefix string, flag int) *Logger {
-    return &Logger{out: out, prefix: prefix, flag: flag}
+    return NewSinkLogger(NewWriterSink(out), prefix, flag)
+}
+
+// NewSinkLogger creates a new Logger which writes its entries to sink.
+func NewSinkLogger(sink Sink, prefix string, flag int) *Logger {
+    now := time.Now()
+    return &Logger{
+        sink:   sink,
+        prefix: prefix,
+        flag:   flag,
+        start:  now,
+        last:   now,
+        pkgMin: int32(LevelFatal + 1),
+        stats:  &counters{},
+    }
 }
 
 // NewFileLogger create a new Logger which output to a file specified
@@ -58,33 +135,163 @@ func NewFileLogger(path string, flag int) (*Logger, error) {
 // NewRollFileLogger create a new Logger which output to a file specified path,
 // and roll at specified size
 func NewRollFileLogger(path string, maxsize uint, flag int) (*Logger, error) {
-    if strings.ContainsAny(path, string(filepath.Separator)) {
-        dir := path
-        // not ended by "/", ex: abc/d/e/x.log
-        if strings.HasSuffix(path, string(filepath.Separator)) {
-            path = path + "aralog.log" // the default log file name if not provided
-        } else {
-            i := strings.LastIndex(path, string(filepath.Separator))
-            paths := strings.SplitAfterN(path, string(filepath.Separator), i)
-            dir = paths[0]
-        }
-
-        os.MkdirAll(dir, 0600)
-    }
-
-    out, err := os.OpenFile(path, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0600)
+    sink, err := NewRollFileSink(path, maxsize)
     if err != nil {
         return nil, err
     }
 
-    currentOutFile = out
+    return NewSinkLogger(sink, "", flag), nil
+}
+
+// clone returns a copy of the logger configuration, sharing its sink.
+// l.mu must be held.
+func (l *Logger) clone() *Logger {
+    return &Logger{
+        level:       int32(l.Level()),
+        pkgMin:      atomic.LoadInt32(&l.pkgMin),
+        hasEnabler:  atomic.LoadInt32(&l.hasEnabler),
+        prefix:      l.prefix,
+        flag:        l.flag,
+        sink:        l.sink,
+        layout:      l.layout,
+        loc:         l.loc,
+        start:       l.start,
+        last:        l.last,
+        fields:      l.fields,
+        hook:        l.hook,
+        enc:         l.enc,
+        maxlen:      l.maxlen,
+        name:        l.name,
+        pkgLevels:   l.pkgLevels,
+        filter:      l.filter,
+        rule:        l.rule,
+        enabler:     l.enabler,
+        stats:       l.stats,
+        audit:       l.audit,
+        redact:      l.redact,
+        fieldMasks:  l.fieldMasks,
+        keepSecrets: l.keepSecrets,
+        utf8Mode:    l.utf8Mode,
+        hooks:       l.hooks,
+        middleware:  l.middleware,
+        clock:       l.clock,
+    }
+}
+
+// Flags returns the output flags for the logger.
+func (l *Logger) Flags() int {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.flag
+}
+
+// SetFlags sets the output flags for the logger.
+func (l *Logger) SetFlags(flag int) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.flag = flag
+}
+
+// Prefix returns the output prefix for the logger.
+func (l *Logger) Prefix() string {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.prefix
+}
+
+// SetPrefix sets the output prefix for the logger.
+func (l *Logger) SetPrefix(prefix string) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.prefix = prefix
+}
+
+// TimeFormat returns the custom time layout of the logger, empty if the
+// time flags are used.
+func (l *Logger) TimeFormat() string {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.layout
+}
+
+// SetTimeFormat sets a custom time layout, as accepted by time.Format, for the
+// header. It overrides the time flags; an empty layout restores them.
+func (l *Logger) SetTimeFormat(layout string) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.layout = layout
+}
+
+// Location returns the time zone of the header, nil for local time.
+func (l *Logger) Location() *time.Location {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.loc
+}
+
+// SetLocation sets the time zone the header times are rendered in,
+// regardless of the host time zone. nil restores local time. LUTC takes
+// precedence over it.
+func (l *Logger) SetLocation(loc *time.Location) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.loc = loc
+}
 
-    // minimal maxsize should >= 1MB
-    if maxsize < 1024 * 1024 {
-        maxsize = 1024 * 1024 * 10
+// SetClock sets the time source of the logger, nil restores time.Now. The
+// time of the entries stamps their headers and drives the time based
+// decisions of the sinks, ex: the names of rolled files, so tests can fix
+// it. Lelapsed counts from the time of the new clock at the call.
+func (l *Logger) SetClock(now func() time.Time) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.clock = now
+    if now != nil {
+        l.start = now()
     }
+}
+
+// SetMaxMessageLength limits messages to n characters, longer ones are cut
+// on a grapheme cluster boundary and marked with "…". 0 removes the limit.
+func (l *Logger) SetMaxMessageLength(n int) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.maxlen = n
+}
+
+// SetHeaderHook sets a hook appending custom header tokens, nil removes it.
+func (l *Logger) SetHeaderHook(hook HeaderHook) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.hook = hook
+}
 
-    return &Logger{out: out, prefix: "", flag: flag, path: path, maxsize: maxsize}, nil
+// Sink returns the destination of the Logger.
+func (l *Logger) Sink() Sink {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.sink
+}
+
+// SetSink sets the destination of the Logger.
+func (l *Logger) SetSink(sink Sink) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.sink = sink
+}
+
+// Flush flushes any buffered entries of the Logger's sink.
+func (l *Logger) Flush() error {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.sink.Flush()
+}
+
+// Close flushes and closes the Logger's sink.
+func (l *Logger) Close() error {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.sink.Close()
 }
 
 //var std = New(os.Stderr, "", LstdFlags)
@@ -109,9 +316,34 @@ func itoa(buf *[]byte, i int, wid int) {
     *buf = append(*buf, b[bp:]...)
 }
 
-func (l *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int) {
-    *buf = append(*buf, l.prefix...)
-    if l.flag & (Ldate | Ltime | Lmicroseconds) != 0 {
+func (l *Logger) formatHeader(buf *[]byte, e *Entry) {
+    t, file, line, fn := e.Time, e.File, e.Line, e.Func
+    if l.flag & Lmsgprefix == 0 {
+        *buf = append(*buf, l.prefix...)
+    }
+    if l.flag & LUTC != 0 {
+        t = t.UTC()
+    } else if l.loc != nil {
+        t = t.In(l.loc)
+    }
+    if len(l.layout) > 0 {
+        *buf = t.AppendFormat(*buf, l.layout)
+        *buf = append(*buf, ' ')
+    } else if l.flag & (LEpochMillis | LEpochNanos) != 0 {
+        if l.flag & LEpochNanos != 0 {
+            *buf = strconv.AppendInt(*buf, t.UnixNano(), 10)
+        } else {
+            *buf = strconv.AppendInt(*buf, t.UnixNano() / 1e6, 10)
+        }
+        *buf = append(*buf, ' ')
+    } else if l.flag & LRFC3339 != 0 {
+        layout := time.RFC3339
+        if l.flag & Lmicroseconds != 0 {
+            layout = rfc3339Micro
+        }
+        *buf = t.AppendFormat(*buf, layout)
+        *buf = append(*buf, ' ')
+    } else if l.flag & (Ldate | Ltime | Lmicroseconds) != 0 {
         if l.flag & Ldate != 0 {
             year, month, day := t.Date()
             itoa(buf, year, 4)
@@ -135,22 +367,112 @@ func (l *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int) {
             *buf = append(*buf, ' ')
         }
     }
-    if l.flag & (Lshortfile | Llongfile) != 0 {
-        if l.flag & Lshortfile != 0 {
-            short := file
-            for i := len(file) - 1; i > 0; i-- {
-                if file[i] == '/' {
-                    short = file[i + 1:]
-                    break
-                }
-            }
-            file = short
+    if l.flag & Lelapsed != 0 {
+        appendElapsed(buf, e.Time.Sub(l.start))
+        *buf = append(*buf, ' ')
+    }
+    if l.flag & Ldelta != 0 {
+        d := e.Time.Sub(l.last)
+        if d < 0 {
+            d = 0
         }
-        *buf = append(*buf, file...)
-        *buf = append(*buf, ':')
-        itoa(buf, line, -1)
+        *buf = append(*buf, '+')
+        *buf = append(*buf, d.Round(time.Millisecond).String()...)
+        *buf = append(*buf, ' ')
+    }
+    if l.flag & Lseq != 0 {
+        *buf = append(*buf, '#')
+        *buf = strconv.AppendUint(*buf, e.Seq, 10)
+        *buf = append(*buf, ' ')
+    }
+    if l.flag & (Lhostname | Lpid) != 0 {
+        if l.flag & Lhostname != 0 {
+            *buf = append(*buf, hostname...)
+        }
+        if l.flag & Lpid != 0 {
+            *buf = append(*buf, '[')
+            itoa(buf, pid, -1)
+            *buf = append(*buf, ']')
+        }
+        *buf = append(*buf, ' ')
+    }
+    if l.flag & Lgoroutine != 0 {
+        *buf = append(*buf, 'g')
+        *buf = strconv.AppendInt(*buf, e.Goroutine, 10)
+        *buf = append(*buf, ' ')
+    }
+    if l.flag & (Lshortfile | Llongfile) != 0 && l.flag & Lcallerlast == 0 {
+        l.appendCaller(buf, file, line)
         *buf = append(*buf, ": "...)
     }
+    if l.flag & (Lfuncname | Llongfuncname) != 0 {
+        if l.flag & Llongfuncname == 0 {
+            fn = shortFuncName(fn)
+        }
+        *buf = append(*buf, fn...)
+        *buf = append(*buf, ": "...)
+    }
+    if l.hook != nil {
+        l.hook(buf, *e)
+    }
+    if l.flag & Lmsgprefix != 0 {
+        *buf = append(*buf, l.prefix...)
+    }
+}
+
+// appendElapsed appends d as +hh:mm:ss.mmm, hours grow beyond two digits if needed.
+func appendElapsed(buf *[]byte, d time.Duration) {
+    if d < 0 {
+        d = 0
+    }
+    ms := int(d / time.Millisecond)
+    *buf = append(*buf, '+')
+    itoa(buf, ms / 3600000, 2)
+    *buf = append(*buf, ':')
+    itoa(buf, ms / 60000 % 60, 2)
+    *buf = append(*buf, ':')
+    itoa(buf, ms / 1000 % 60, 2)
+    *buf = append(*buf, '.')
+    itoa(buf, ms % 1000, 3)
+}
+
+// appendCaller appends file:line, with the file shortened if Lshortfile is set.
+func (l *Logger) appendCaller(buf *[]byte, file string, line int) {
+    if l.flag & Lshortfile != 0 {
+        short := file
+        for i := len(file) - 1; i > 0; i-- {
+            if file[i] == '/' {
+                short = file[i + 1:]
+                break
+            }
+        }
+        file = short
+    }
+    *buf = append(*buf, file...)
+    *buf = append(*buf, ':')
+    itoa(buf, line, -1)
+}
+
+// shortFuncName strips the import path from a function name, keeping the
+// package name: github.com/a/b/db.(*Conn).Query becomes db.(*Conn).Query.
+func shortFuncName(fn string) string {
+    if i := strings.LastIndex(fn, "/"); i >= 0 {
+        return fn[i + 1:]
+    }
+    return fn
+}
+
+// goroutineID returns the id of the calling goroutine, parsed from the first
+// line of its stack trace: "goroutine 42 [running]:".
+func goroutineID() int64 {
+    var b [64]byte
+    s := b[:runtime.Stack(b[:], false)]
+    s = s[len("goroutine "):]
+    if i := bytes.IndexByte(s, ' '); i >= 0 {
+        s = s[:i]
+    }
+    id, _ := strconv.ParseInt(string(s), 10, 64)
+    return id
 }
 
 // Output writes the output for a logging event.  The string s contains
@@ -159,87 +481,185 @@ func (l *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int) {
 // already a newline.  Calldepth is used to recover the PC and is
 // provided for generality, although at the moment on all pre-defined
 // paths it will be 2.
-func (l *Logger) output(calldepth int, s string) error {
-    now := time.Now() // get this early.
-    var file string
-    var line int
+func (l *Logger) output(calldepth int, level Level, s string) error {
+    return l.outputFields(calldepth + 1, level, s, nil)
+}
+
+// outputFields is output with fields added to the bound fields of the
+// logger for this entry only, except those with the key of a bound field.
+func (l *Logger) outputFields(calldepth int, level Level, s string, extra []Field) error {
+    e := Entry{Time: time.Now(), Level: level, Message: s} // get time early.
     l.mu.Lock()
     defer l.mu.Unlock()
-    if l.flag & (Lshortfile | Llongfile) != 0 {
+    if l.clock != nil {
+        e.Time = l.clock()
+    }
+    if !l.audit && l.enabler != nil && !l.enabler.Enabled(level, l.name) {
+        return nil
+    }
+    if !l.audit && !l.filter.Allow(s) {
+        return nil
+    }
+    e.Logger = l.name
+    e.Fields = l.fields
+    if len(extra) > 0 {
+        e.Fields = mergeFields(l.fields, extra)
+    }
+    e.stats = l.stats
+    if !l.audit && l.rule != nil && !l.rule.eval(&e) {
+        return nil
+    }
+    if l.utf8Mode != UTF8Keep {
+        s = ValidUTF8(s, l.utf8Mode)
+        e.Message = s
+        e.Fields = validFields(e.Fields, l.utf8Mode)
+    }
+    if l.flag & Lsanitize != 0 {
+        s = sanitize(strings.TrimSuffix(s, "\n"))
+        e.Message = s
+    }
+    if len(l.redact) > 0 {
+        s = redact(s, l.redact)
+        e.Message = s
+    }
+    if !l.keepSecrets {
+        s = maskSecrets(s)
+        e.Message = s
+    }
+    if l.maxlen > 0 && len(s) > l.maxlen {
+        if t := Truncate(s, l.maxlen, true); len(t) < len(s) {
+            s = t + "…"
+            e.Message = s
+        }
+    }
+    callerFlags := l.flag & (Lshortfile | Llongfile | Lfuncname | Llongfuncname) != 0
+    if callerFlags || len(l.pkgLevels) > 0 {
         // release lock while getting caller info - it's expensive.
         l.mu.Unlock()
-        var ok bool
-        _, file, line, ok = runtime.Caller(calldepth)
-        if !ok {
-            file = "???"
-            line = 0
+        pc, file, line, ok := runtime.Caller(calldepth)
+        fn := "???"
+        if ok {
+            if f := runtime.FuncForPC(pc); f != nil {
+                fn = f.Name()
+            }
+        } else {
+            file, line = "???", 0
         }
         l.mu.Lock()
+
+        if len(l.pkgLevels) > 0 && l.enabler == nil && !l.audit {
+            min, ok := l.packageLevel(fn)
+            if !ok {
+                min = l.Level()
+            }
+            if level < min {
+                return nil
+            }
+        }
+        if callerFlags {
+            e.File, e.Line, e.Func = file, line, fn
+        }
+    }
+    if l.flag & Lgoroutine != 0 {
+        l.mu.Unlock()
+        e.Goroutine = goroutineID()
+        l.mu.Lock()
+    }
+    if len(l.fieldMasks) > 0 {
+        e.Fields = maskFields(e.Fields, l.fieldMasks)
+    }
+    if len(l.middleware) > 0 {
+        if !l.runMiddleware(&e) {
+            return nil
+        }
+        s = e.Message
+    }
+    l.seq++
+    e.Seq = l.seq
+    if len(l.hooks) > 0 {
+        l.fireHooks(&e)
+        s = e.Message
     }
     l.buf = l.buf[:0]
-    l.formatHeader(&l.buf, now, file, line)
-    l.buf = append(l.buf, s...)
-    if len(s) > 0 && s[len(s) - 1] != '\n' {
+    if l.enc != nil {
+        l.enc.Encode(&l.buf, e)
+        e.Formatted = l.buf
+        return l.write(e)
+    }
+    l.formatHeader(&l.buf, &e)
+    l.last = e.Time
+    callerLast := l.flag & Lcallerlast != 0 && l.flag & (Lshortfile | Llongfile) != 0
+    if len(e.Fields) > 0 || callerLast {
+        l.buf = append(l.buf, strings.TrimSuffix(s, "\n")...)
+        appendFields(&l.buf, e.Fields)
+        if callerLast {
+            l.buf = append(l.buf, " ("...)
+            l.appendCaller(&l.buf, e.File, e.Line)
+            l.buf = append(l.buf, ')')
+        }
         l.buf = append(l.buf, '\n')
+    } else {
+        l.buf = append(l.buf, s...)
+        if len(s) > 0 && s[len(s) - 1] != '\n' {
+            l.buf = append(l.buf, '\n')
+        }
     }
 
-    if len(l.path) > 0 {
-        err := l.rollFile(now)
-        if err != nil {
-            return err
-        }
+    e.Formatted = l.buf
+    return l.write(e)
+}
+
+// write hands e to the sink and counts it. l.mu must be held.
+func (l *Logger) write(e Entry) error {
+    start := time.Now()
+    err := l.sink.Write(e)
+    d := time.Since(start)
+    if r := metricsRecorder(); r != nil {
+        r.RecordEntry(e.Logger, e.Level, d, err)
+    }
+    l.stats.latency.observe(d)
+    l.stats.add(e.Level, len(e.Formatted), err)
+    totals.add(e.Level, len(e.Formatted), err)
+    if err != nil {
+        reportError(err)
     }
-    _, err := l.out.Write(l.buf)
     return err
 }
 
-func (l *Logger) rollFile(now time.Time) error {
-    l.size += uint(len(l.buf))
-
-    if l.size < l.maxsize {
+func (l *Logger) Debug(s string, v ...interface{}) error {
+    if !l.mayLog(LevelDebug) {
         return nil
     }
+    err := l.output(2, LevelDebug, fmt.Sprintf(s, v...))
+    return err
+}
 
-    // file rotation if size > maxsize
-    // close file before rename it
-    if currentOutFile != nil {
-        // ignore if Close() failed
-        err := currentOutFile.Close()
-        if err != nil {
-            l.buf = append(l.buf, ("[XXX] ARALOGGER ERROR: Close current output file failed, " + err.Error())...)
-            l.buf = append(l.buf, '\n')
-        }
+func (l *Logger) Info(s string, v ...interface{}) error {
+    if !l.mayLog(LevelInfo) {
+        return nil
     }
+    err := l.output(2, LevelInfo, fmt.Sprintf(s, v...))
+    return err
+}
 
-    newPath := l.path
-
-    // rename l.path to nameYYYYMMDDhhmmss
-    err := os.Rename(l.path,
-        l.path + string(now.Year()) + string(now.Month()) + string(now.Day()) +
-        string(now.Hour()) + string(now.Minute()) + string(now.Second()))
-    if err == nil {
-        // TODO zip it
-    } else {
-        l.buf = append(l.buf, ("[XXX] ARALOGGER ERROR: Rolling file failed, " + err.Error())...)
-        l.buf = append(l.buf, '\n')
-
-        // if rename failed, start a new log file with different name
-        newPath = l.path + string(now.Unix())
+func (l *Logger) Warn(s string, v ...interface{}) error {
+    if !l.mayLog(LevelWarn) {
+        return nil
     }
+    err := l.output(2, LevelWarn, fmt.Sprintf(s, v...))
+    return err
+}
 
-    newOut, err := os.OpenFile(newPath, os.O_APPEND | os.O_WRONLY, 0600)
-    if err != nil {
-        return err
+func (l *Logger) Error(s string, v ...interface{}) error {
+    if !l.mayLog(LevelError) {
+        return nil
     }
-
-    currentOutFile = newOut
-    l.out = newOut
-    l.size = uint(len(l.buf))
-
-    return nil
+    err := l.output(2, LevelError, fmt.Sprintf(s, v...))
+    return err
 }
 
-func (l *Logger) Debug(s string, v ...interface{}) error {
-    err := l.output(2, fmt.Sprintf(s, v...))
-    return err
+// Fatal logs at LevelFatal and then calls os.Exit(1).
+func (l *Logger) Fatal(s string, v ...interface{}) {
+    l.output(2, LevelFatal, fmt.Sprintf(s, v...))
+    os.Exit(1)
 }
@@ -1,7 +1,14 @@
 package aralog
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestAraLog(t *testing.T) {
@@ -12,3 +19,248 @@ func TestAraLog(t *testing.T) {
 
 	logger.Debug("log a test string")
 }
+
+func TestRollFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink, err := NewRollFileSink(dir+string(filepath.Separator), 1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	// force a rotation on the next write
+	sink.size = sink.maxsize
+	logger := NewSinkLogger(sink, "", 0)
+	if err = logger.Debug("after rotation"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 2 {
+		t.Fatalf("expected active and rolled file, got %d files", len(files))
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "aralog.log"))
+	if string(b) != "after rotation\n" {
+		t.Errorf("unexpected active file content: %q", b)
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink, err := NewRollFileSink(filepath.Join(dir, "app.log"), 1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	now := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	logger := NewSinkLogger(sink, "", LstdFlags|LUTC|Lelapsed)
+	logger.SetClock(func() time.Time { return now })
+
+	now = now.Add(90 * time.Second)
+	sink.size = sink.maxsize
+	logger.Info("after rotation")
+
+	if _, err := os.Stat(filepath.Join(dir, "app.log20090123012453")); err != nil {
+		t.Errorf("rolled file not named after the clock: %v", err)
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "app.log"))
+	if want := "2009/01/23 01:24:53 +00:01:30.000 after rotation\n"; string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+}
+
+func TestRollFileSinkTokens(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink, err := NewRollFileSink(filepath.Join(dir, "app-%{hostname}-%{pid}.log"), 1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	want := filepath.Join(dir, "app-"+pathSafe(hostname)+"-"+strconv.Itoa(os.Getpid())+".log")
+	if sink.Path() != want {
+		t.Errorf("path %s, want %s", sink.Path(), want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Error(err)
+	}
+	if files, _ := RotatedFiles(filepath.Join(dir, "app-%{hostname}-%{pid}.log")); len(files) != 1 || files[0] != want {
+		t.Errorf("rotated files %v", files)
+	}
+}
+
+func TestLUTC(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", Ltime|LUTC)
+	logger.SetClock(func() time.Time { return time.Date(2009, 1, 23, 9, 23, 23, 0, time.FixedZone("CST", 8*3600)) })
+	logger.Debug("utc")
+
+	if want := "01:23:23 "; !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("expected UTC time %s, got %q", want, buf.String())
+	}
+}
+
+func TestLRFC3339(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", LRFC3339|Lmicroseconds|LUTC)
+	logger.Debug("iso")
+
+	ts := strings.SplitN(buf.String(), " ", 2)[0]
+	if _, err := time.Parse(time.RFC3339Nano, ts); err != nil || len(ts) != len("2009-01-23T01:23:23.123456Z") {
+		t.Errorf("unexpected RFC3339 timestamp %q: %v", ts, err)
+	}
+}
+
+func TestLEpochMillis(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", LEpochMillis|Ldate)
+	before := time.Now().UnixNano() / 1e6
+	logger.Debug("epoch")
+	after := time.Now().UnixNano() / 1e6
+
+	ms, err := strconv.ParseInt(strings.SplitN(buf.String(), " ", 2)[0], 10, 64)
+	if err != nil || ms < before || ms > after {
+		t.Errorf("unexpected epoch header %q", buf.String())
+	}
+}
+
+func TestSetTimeFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", LstdFlags)
+	logger.SetTimeFormat("2006.01.02")
+	logger.Debug("layout")
+
+	want := time.Now().Format("2006.01.02") + " layout\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestSetLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+13", 13*3600)
+	var buf bytes.Buffer
+	logger := New(&buf, "", LRFC3339)
+	logger.SetLocation(loc)
+	logger.Debug("zone")
+
+	if !strings.Contains(buf.String(), "+13:00 ") {
+		t.Errorf("expected +13:00 offset, got %q", buf.String())
+	}
+}
+
+func TestLmsgprefix(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "[svc] ", Lshortfile|Lmsgprefix)
+	logger.Debug("hello")
+
+	if !strings.HasPrefix(buf.String(), "aralog_test.go:") || !strings.HasSuffix(buf.String(), ": [svc] hello\n") {
+		t.Errorf("expected prefix before message, got %q", buf.String())
+	}
+}
+
+func TestLfuncname(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", Lfuncname)
+	logger.Debug("hello")
+
+	if buf.String() != "aralog.TestLfuncname: hello\n" {
+		t.Errorf("unexpected function header %q", buf.String())
+	}
+}
+
+func TestLgoroutine(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", Lgoroutine)
+	logger.Debug("hello")
+
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.SplitN(buf.String(), " ", 2)[0], "g"))
+	if err != nil || id <= 0 {
+		t.Errorf("unexpected goroutine header %q", buf.String())
+	}
+}
+
+func TestLhostnameLpid(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", Lhostname|Lpid)
+	logger.Debug("hello")
+
+	host, _ := os.Hostname()
+	want := host + "[" + strconv.Itoa(os.Getpid()) + "] hello\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestLseq(t *testing.T) {
+	ring := NewRingSink(2)
+	logger := NewSinkLogger(ring, "", Lseq)
+	logger.Debug("a")
+	logger.Debug("b")
+
+	var buf bytes.Buffer
+	ring.Dump(&buf)
+	if buf.String() != "#1 a\n#2 b\n" || ring.Entries()[1].Seq != 2 {
+		t.Errorf("unexpected sequence numbers %q", buf.String())
+	}
+}
+
+func TestAppendElapsed(t *testing.T) {
+	var buf []byte
+	appendElapsed(&buf, 3*time.Minute+12*time.Second+450*time.Millisecond)
+	if string(buf) != "+00:03:12.450" {
+		t.Errorf("unexpected elapsed %q", buf)
+	}
+}
+
+func TestLdelta(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", Ldelta)
+	logger.Debug("a")
+	buf.Reset()
+	time.Sleep(20 * time.Millisecond)
+	logger.Debug("b")
+
+	d, err := time.ParseDuration(strings.TrimPrefix(strings.SplitN(buf.String(), " ", 2)[0], "+"))
+	if err != nil || d < 20*time.Millisecond {
+		t.Errorf("unexpected delta header %q", buf.String())
+	}
+}
+
+func TestSetHeaderHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	logger.SetHeaderHook(func(b *[]byte, e Entry) {
+		*b = append(*b, e.Level.String()...)
+		*b = append(*b, ' ')
+	})
+	logger.Warn("disk almost full")
+
+	if buf.String() != "WARN disk almost full\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestLcallerlast(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", Lshortfile|Lcallerlast)
+	logger.With(F("k", "v")).Debug("hello")
+
+	if !strings.HasPrefix(buf.String(), "hello k=v (aralog_test.go:") || !strings.HasSuffix(buf.String(), ")\n") {
+		t.Errorf("expected caller at the end, got %q", buf.String())
+	}
+}
@@ -0,0 +1,133 @@
+// Package aralogtest helps testing code which logs through aralog: a Sink
+// capturing the entries in memory and assertions over them, so tests need
+// not parse log files.
+package aralogtest
+
+import (
+    "strings"
+    "sync"
+    "testing"
+
+    "github.com/araframework/aralog"
+)
+
+// Sink is an aralog.Sink keeping every entry written to it.
+type Sink struct {
+    mu      sync.Mutex
+    entries []aralog.Entry
+}
+
+// NewSink creates an empty Sink.
+func NewSink() *Sink {
+    return &Sink{}
+}
+
+// NewLogger creates a Logger writing every level to a new Sink, without
+// header, and returns both.
+func NewLogger() (*aralog.Logger, *Sink) {
+    s := NewSink()
+    l := aralog.NewSinkLogger(s, "", 0)
+    l.SetLevel(aralog.LevelDebug)
+    return l, s
+}
+
+// Write keeps a copy of e.
+func (s *Sink) Write(e aralog.Entry) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    e.Formatted = append([]byte(nil), e.Formatted...)
+    e.Fields = append([]aralog.Field(nil), e.Fields...)
+    s.entries = append(s.entries, e)
+    return nil
+}
+
+// Flush does nothing.
+func (s *Sink) Flush() error {
+    return nil
+}
+
+// Close does nothing, the entries stay available.
+func (s *Sink) Close() error {
+    return nil
+}
+
+// Healthy reports true.
+func (s *Sink) Healthy() bool {
+    return true
+}
+
+// Entries returns the entries written so far, oldest first.
+func (s *Sink) Entries() []aralog.Entry {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]aralog.Entry(nil), s.entries...)
+}
+
+// Messages returns the messages of the entries written so far.
+func (s *Sink) Messages() []string {
+    var msgs []string
+    for _, e := range s.Entries() {
+        msgs = append(msgs, e.Message)
+    }
+    return msgs
+}
+
+// Reset forgets the entries written so far.
+func (s *Sink) Reset() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.entries = nil
+}
+
+// Logged reports whether an entry at level with a message containing substr
+// was written.
+func (s *Sink) Logged(level aralog.Level, substr string) bool {
+    for _, e := range s.Entries() {
+        if e.Level == level && strings.Contains(e.Message, substr) {
+            return true
+        }
+    }
+    return false
+}
+
+// AssertLogged fails the test unless an entry at level with a message
+// containing substr was written.
+func (s *Sink) AssertLogged(t testing.TB, level aralog.Level, substr string) {
+    t.Helper()
+    if !s.Logged(level, substr) {
+        t.Errorf("no %s entry containing %q, got:\n%s", level, substr, s.dump())
+    }
+}
+
+// AssertNotLogged fails the test if an entry at level with a message
+// containing substr was written.
+func (s *Sink) AssertNotLogged(t testing.TB, level aralog.Level, substr string) {
+    t.Helper()
+    if s.Logged(level, substr) {
+        t.Errorf("unexpected %s entry containing %q, got:\n%s", level, substr, s.dump())
+    }
+}
+
+// AssertCount fails the test unless n entries were written.
+func (s *Sink) AssertCount(t testing.TB, n int) {
+    t.Helper()
+    if got := len(s.Entries()); got != n {
+        t.Errorf("%d entries written, want %d, got:\n%s", got, n, s.dump())
+    }
+}
+
+// dump lists the entries for failure messages.
+func (s *Sink) dump() string {
+    var b strings.Builder
+    for _, e := range s.Entries() {
+        b.WriteString("\t")
+        b.WriteString(e.Level.String())
+        b.WriteString(" ")
+        b.WriteString(strings.TrimSuffix(e.Message, "\n"))
+        b.WriteString("\n")
+    }
+    if b.Len() == 0 {
+        return "\t(no entries)\n"
+    }
+    return b.String()
+}
@@ -0,0 +1,43 @@
+package aralogtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/araframework/aralog"
+)
+
+// recorder is a testing.TB recording failures instead of failing.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertLogged(t *testing.T) {
+	logger, sink := NewLogger()
+	logger.Debug("cache miss for %s", "user:1")
+	logger.Error("db timeout after %ds", 30)
+
+	sink.AssertLogged(t, aralog.LevelError, "db timeout")
+	sink.AssertNotLogged(t, aralog.LevelError, "cache miss")
+	sink.AssertCount(t, 2)
+
+	r := &recorder{TB: t}
+	sink.AssertLogged(r, aralog.LevelWarn, "db timeout")
+	sink.AssertNotLogged(r, aralog.LevelDebug, "cache")
+	sink.AssertCount(r, 3)
+	if len(r.failures) != 3 {
+		t.Errorf("expected 3 failures, got %q", r.failures)
+	}
+
+	sink.Reset()
+	if len(sink.Entries()) != 0 {
+		t.Error("entries kept after Reset")
+	}
+}
@@ -0,0 +1,98 @@
+package aralogtest
+
+import (
+    "bytes"
+    "errors"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/araframework/aralog"
+)
+
+// GoldenTime is the time of the first entry rendered by Render.
+var GoldenTime = time.Date(2009, 1, 23, 1, 23, 23, 123456000, time.UTC)
+
+// UpdateEnv is the environment variable which makes AssertGolden rewrite
+// the golden files instead of comparing against them, ex:
+//
+//	ARALOG_UPDATE_GOLDEN=1 go test ./...
+const UpdateEnv = "ARALOG_UPDATE_GOLDEN"
+
+// Clock returns a clock for Logger.SetClock which returns start on its first
+// call and step later on every following call.
+func Clock(start time.Time, step time.Duration) func() time.Time {
+    var mu sync.Mutex
+    next := start
+    return func() time.Time {
+        mu.Lock()
+        defer mu.Unlock()
+        t := next
+        next = next.Add(step)
+        return t
+    }
+}
+
+// Render writes a fixed set of entries, covering every level, fields of the
+// common types and messages with quotes, tabs and non-ASCII characters,
+// through a logger with the flags and encoder given, nil for the text
+// format. The clock starts at GoldenTime, when the logger is created, and
+// advances by 1.5s per entry, so the output only changes with the format.
+func Render(flag int, enc aralog.Encoder) []byte {
+    var buf bytes.Buffer
+    l := aralog.New(&buf, "", flag)
+    l.SetLevel(aralog.LevelDebug)
+    l.SetClock(Clock(GoldenTime, 1500 * time.Millisecond))
+    if enc != nil {
+        l.SetEncoder(enc)
+    }
+
+    l.Debug("cache miss")
+    l.With(aralog.F("user", "jane doe"), aralog.F("id", 42), aralog.F("admin", true)).Info("signed in")
+    l.With(aralog.F("ratio", 0.25), aralog.F("query", `name="x"`)).Warn("slow query\ttook 2s")
+    l.With(aralog.F("err", errors.New("connection reset"))).Error("payment failed for order №7")
+    return buf.Bytes()
+}
+
+// AssertGolden compares got with the golden file testdata/name.golden and
+// fails the test at the first differing line. With UpdateEnv set, the
+// golden file is written with got instead.
+func AssertGolden(t testing.TB, name string, got []byte) {
+    t.Helper()
+    path := filepath.Join("testdata", name + ".golden")
+    if os.Getenv(UpdateEnv) != "" {
+        if err := os.MkdirAll("testdata", 0755); err != nil {
+            t.Fatal(err)
+        }
+        if err := ioutil.WriteFile(path, got, 0644); err != nil {
+            t.Fatal(err)
+        }
+        return
+    }
+
+    want, err := ioutil.ReadFile(path)
+    if err != nil {
+        t.Fatalf("%v, run with %s=1 to create it", err, UpdateEnv)
+    }
+    if bytes.Equal(got, want) {
+        return
+    }
+    gotLines, wantLines := strings.Split(string(got), "\n"), strings.Split(string(want), "\n")
+    for i := 0; ; i++ {
+        if i >= len(gotLines) || i >= len(wantLines) || gotLines[i] != wantLines[i] {
+            var g, w string
+            if i < len(gotLines) {
+                g = gotLines[i]
+            }
+            if i < len(wantLines) {
+                w = wantLines[i]
+            }
+            t.Errorf("%s: line %d differs\n got: %q\nwant: %q", path, i + 1, g, w)
+            return
+        }
+    }
+}
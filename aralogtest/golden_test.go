@@ -0,0 +1,40 @@
+package aralogtest
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/araframework/aralog"
+)
+
+func TestClock(t *testing.T) {
+	clock := Clock(GoldenTime, time.Second)
+	if clock() != GoldenTime || clock() != GoldenTime.Add(time.Second) {
+		t.Error("clock does not step")
+	}
+}
+
+func TestGoldenText(t *testing.T) {
+	AssertGolden(t, "text", Render(aralog.LstdFlags|aralog.Lmicroseconds|aralog.LUTC|aralog.Lelapsed, nil))
+}
+
+func TestGoldenJSON(t *testing.T) {
+	AssertGolden(t, "json", Render(0, aralog.NewJSONEncoder()))
+}
+
+func TestGoldenConsole(t *testing.T) {
+	AssertGolden(t, "console", Render(0, aralog.NewConsoleEncoder()))
+}
+
+func TestAssertGoldenMismatch(t *testing.T) {
+	if os.Getenv(UpdateEnv) != "" {
+		t.Skip("updating golden files")
+	}
+	r := &recorder{TB: t}
+	AssertGolden(r, "text", []byte("drifted\n"))
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "line 1 differs") {
+		t.Errorf("unexpected failures %q", r.failures)
+	}
+}
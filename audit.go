@@ -0,0 +1,186 @@
+package aralog
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "os"
+    "sync"
+)
+
+// AuditSink is a Sink for compliance-grade event records. The file is only
+// ever appended to and never rolled, and every entry is synced to stable
+// storage before Write returns. Each line ends with a space and a hash
+// chaining it to the previous lines: the hex SHA-256 of the hash of the
+// previous line and the line itself, so any later change to the file is
+// detected by VerifyAuditFile.
+type AuditSink struct {
+    mu   sync.Mutex
+    file *os.File
+    path string
+    hash [sha256.Size]byte // hash of the last line, zero for an empty file
+    line []byte
+    err  error // last write error
+}
+
+// NewAuditSink opens the audit file at path for appending, creating it if
+// needed. The hash chain of an existing file is continued from its last line.
+func NewAuditSink(path string) (*AuditSink, error) {
+    file, err := os.OpenFile(path, os.O_APPEND | os.O_CREATE | os.O_RDWR, 0600)
+    if err != nil {
+        return nil, err
+    }
+    s := &AuditSink{file: file, path: path}
+    if s.hash, err = lastAuditHash(file); err != nil {
+        file.Close()
+        return nil, fmt.Errorf("aralog: audit file %s: %v", path, err)
+    }
+    return s, nil
+}
+
+// lastAuditHash returns the hash ending the last line of f.
+func lastAuditHash(f *os.File) ([sha256.Size]byte, error) {
+    var hash [sha256.Size]byte
+    fi, err := f.Stat()
+    if err != nil || fi.Size() == 0 {
+        return hash, err
+    }
+
+    // the last line ends with " " + hash + "\n"
+    tail := make([]byte, hex.EncodedLen(sha256.Size) + 2)
+    if fi.Size() < int64(len(tail)) {
+        return hash, fmt.Errorf("truncated last line")
+    }
+    if _, err = f.ReadAt(tail, fi.Size() - int64(len(tail))); err != nil {
+        return hash, err
+    }
+    if tail[0] != ' ' || tail[len(tail) - 1] != '\n' {
+        return hash, fmt.Errorf("last line has no hash")
+    }
+    if _, err = hex.Decode(hash[:], tail[1:len(tail) - 1]); err != nil {
+        return hash, fmt.Errorf("last line has no hash")
+    }
+    return hash, nil
+}
+
+// chainAuditHash returns the hash of line, without its newline, after prev.
+func chainAuditHash(prev [sha256.Size]byte, line []byte) [sha256.Size]byte {
+    h := sha256.New()
+    h.Write(prev[:])
+    h.Write(line)
+    var sum [sha256.Size]byte
+    h.Sum(sum[:0])
+    return sum
+}
+
+// Write appends the formatted entry with its hash and syncs the file.
+func (s *AuditSink) Write(e Entry) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    line := bytes.TrimRight(e.Formatted, "\n")
+    // a newline inside the entry would split it into lines with no hash
+    line = bytes.Replace(line, []byte("\n"), []byte(`\n`), -1)
+    hash := chainAuditHash(s.hash, line)
+
+    s.line = append(s.line[:0], line...)
+    s.line = append(s.line, ' ')
+    s.line = append(s.line, hex.EncodeToString(hash[:])...)
+    s.line = append(s.line, '\n')
+    if _, s.err = s.file.Write(s.line); s.err == nil {
+        s.err = s.file.Sync()
+    }
+    if s.err == nil {
+        s.hash = hash
+    }
+    return s.err
+}
+
+// Flush does nothing, every entry is synced by Write.
+func (s *AuditSink) Flush() error {
+    return nil
+}
+
+// Close closes the file.
+func (s *AuditSink) Close() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.file.Close()
+}
+
+// Healthy reports whether the last entry was written and synced.
+func (s *AuditSink) Healthy() bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err == nil
+}
+
+// LastError returns the error of the last write, nil if it succeeded.
+func (s *AuditSink) LastError() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err
+}
+
+// Describe describes the sink with its path.
+func (s *AuditSink) Describe() SinkDescription {
+    return SinkDescription{Type: "audit", Healthy: s.Healthy(), Settings: map[string]interface{}{"path": s.path}}
+}
+
+// VerifyAuditFile checks the hash chain of the audit file at path and
+// returns an error naming the first line which was altered, inserted or
+// removed.
+func VerifyAuditFile(path string) error {
+    f, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+    return VerifyAudit(f)
+}
+
+// VerifyAudit checks the hash chain of the audit lines read from r, see
+// VerifyAuditFile.
+func VerifyAudit(r io.Reader) error {
+    var prev [sha256.Size]byte
+    br := bufio.NewReader(r)
+    for num := 1; ; num++ {
+        line, err := br.ReadBytes('\n')
+        if err == io.EOF && len(line) == 0 {
+            return nil
+        }
+        if err != nil && err != io.EOF {
+            return err
+        }
+        line = bytes.TrimSuffix(line, []byte("\n"))
+
+        i := bytes.LastIndexByte(line, ' ')
+        var hash [sha256.Size]byte
+        if i < 0 || hex.DecodedLen(len(line) - i - 1) != sha256.Size {
+            return fmt.Errorf("aralog: audit line %d: no hash", num)
+        }
+        if _, err := hex.Decode(hash[:], line[i + 1:]); err != nil {
+            return fmt.Errorf("aralog: audit line %d: no hash", num)
+        }
+        if chainAuditHash(prev, line[:i]) != hash {
+            return fmt.Errorf("aralog: audit line %d: hash mismatch, the file was altered", num)
+        }
+        prev = hash
+    }
+}
+
+// NewAuditLogger creates a Logger in audit mode writing to an AuditSink at
+// path: every entry is written whatever the level, package levels, level
+// enabler, filter or rule of the logger, so no event can be filtered out.
+func NewAuditLogger(path string, flag int) (*Logger, error) {
+    sink, err := NewAuditSink(path)
+    if err != nil {
+        return nil, err
+    }
+    l := NewSinkLogger(sink, "", flag)
+    l.audit = true
+    return l, nil
+}
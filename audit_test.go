@@ -0,0 +1,50 @@
+package aralog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	logger, err := NewAuditLogger(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.SetLevel(LevelError)
+	logger.Debug("user alice logged in")
+	logger.Info("user alice\ndeleted bob")
+	logger.Close()
+
+	// the chain continues in a reopened file
+	logger, err = NewAuditLogger(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("user alice logged out")
+	logger.Close()
+
+	b, _ := ioutil.ReadFile(path)
+	if lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n"); len(lines) != 3 ||
+		!strings.HasPrefix(lines[1], `user alice\ndeleted bob `) {
+		t.Fatalf("unexpected audit file\n%s", b)
+	}
+	if err = VerifyAuditFile(path); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+
+	altered := bytes.Replace(b, []byte("bob"), []byte("eve"), 1)
+	if err = VerifyAudit(bytes.NewReader(altered)); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected altered line 2, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package aralog
+
+import (
+    "errors"
+    "sync"
+    "time"
+)
+
+// ErrBreakerOpen is returned by a BreakerSink whose circuit is open and
+// which has no fallback sink.
+var ErrBreakerOpen = errors.New("aralog: circuit breaker open")
+
+// BreakerSink is a circuit breaker around a failing sink. After Threshold
+// consecutive failures the circuit opens and entries go to the fallback
+// sink, without touching the primary, for Cooldown. Then the next entry is
+// used as a probe: if the primary accepts it the circuit closes again,
+// otherwise it stays open for another Cooldown.
+type BreakerSink struct {
+    mu        sync.Mutex
+    primary   Sink
+    fallback  Sink // may be nil
+    failures  int
+    openUntil time.Time
+    Threshold int
+    Cooldown  time.Duration
+}
+
+// NewBreakerSink creates a BreakerSink around primary, diverting to
+// fallback while the circuit is open. fallback may be nil to drop entries.
+func NewBreakerSink(primary, fallback Sink) *BreakerSink {
+    return &BreakerSink{primary: primary, fallback: fallback, Threshold: 5, Cooldown: 30 * time.Second}
+}
+
+// Write writes e to the primary, or to the fallback while the circuit is open
+// or if the primary fails. Without a fallback, the error of the primary is
+// returned, ErrBreakerOpen while the circuit is open.
+func (b *BreakerSink) Write(e Entry) error {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    now := time.Now()
+    if now.Before(b.openUntil) {
+        return b.divert(e)
+    }
+
+    err := b.primary.Write(e)
+    if err == nil {
+        b.failures = 0
+        return nil
+    }
+
+    b.failures++
+    if b.failures >= b.Threshold {
+        b.openUntil = now.Add(b.Cooldown)
+    }
+    if b.fallback == nil {
+        return err
+    }
+    return b.fallback.Write(e)
+}
+
+func (b *BreakerSink) divert(e Entry) error {
+    if b.fallback == nil {
+        return ErrBreakerOpen
+    }
+    return b.fallback.Write(e)
+}
+
+// Open reports whether the circuit is currently open.
+func (b *BreakerSink) Open() bool {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    return time.Now().Before(b.openUntil)
+}
+
+// Flush flushes the primary and the fallback.
+func (b *BreakerSink) Flush() error {
+    err := b.primary.Flush()
+    if b.fallback != nil {
+        if ferr := b.fallback.Flush(); err == nil {
+            err = ferr
+        }
+    }
+    return err
+}
+
+// Close closes the primary and the fallback.
+func (b *BreakerSink) Close() error {
+    err := b.primary.Close()
+    if b.fallback != nil {
+        if cerr := b.fallback.Close(); err == nil {
+            err = cerr
+        }
+    }
+    return err
+}
+
+// Healthy reports whether the circuit is closed.
+func (b *BreakerSink) Healthy() bool {
+    return !b.Open()
+}
+
+// Describe describes the breaker with the primary and the fallback sink.
+func (b *BreakerSink) Describe() SinkDescription {
+    d := SinkDescription{
+        Type:     "breaker",
+        Healthy:  b.Healthy(),
+        Settings: map[string]interface{}{"threshold": b.Threshold, "cooldown": b.Cooldown.String(), "open": b.Open()},
+        Sinks:    []SinkDescription{DescribeSink(b.primary)},
+    }
+    if b.fallback != nil {
+        d.Sinks = append(d.Sinks, DescribeSink(b.fallback))
+    }
+    return d
+}
+
+// LastError returns the last error of the primary.
+func (b *BreakerSink) LastError() error {
+    return SinkLastError(b.primary)
+}
@@ -0,0 +1,45 @@
+package aralog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerSink(t *testing.T) {
+	primary := &failingWriter{fail: true}
+	fallback := &failingWriter{}
+	b := NewBreakerSink(NewWriterSink(primary), NewWriterSink(fallback))
+	b.Threshold = 2
+	b.Cooldown = 10 * time.Millisecond
+	logger := NewSinkLogger(b, "", 0)
+
+	logger.Debug("a")
+	logger.Debug("b")
+	if !b.Open() {
+		t.Fatal("expected circuit to open after 2 failures")
+	}
+
+	primary.fail = false
+	logger.Debug("c")
+	if primary.buf.Len() != 0 || fallback.buf.String() != "a\nb\nc\n" {
+		t.Fatalf("expected entries diverted while open, got %q", fallback.buf.String())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	logger.Debug("d")
+	if b.Open() || primary.buf.String() != "d\n" {
+		t.Errorf("expected probe to close the circuit, got %q", primary.buf.String())
+	}
+}
+
+func TestBreakerSinkWithoutFallback(t *testing.T) {
+	b := NewBreakerSink(&failingSink{}, nil)
+	b.Threshold = 2
+	if err := b.Write(Entry{}); err == nil || err == ErrBreakerOpen {
+		t.Errorf("closed circuit returned %v, want the error of the primary", err)
+	}
+	b.Write(Entry{})
+	if err := b.Write(Entry{}); err != ErrBreakerOpen {
+		t.Errorf("open circuit returned %v", err)
+	}
+}
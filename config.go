@@ -0,0 +1,538 @@
+package aralog
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "net"
+    "net/url"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "time"
+)
+
+// Config declares a Logger with all of its options. It can be decoded from
+// JSON, YAML or TOML, see LoadConfig, and is checked by Validate.
+type Config struct {
+    Name             string            `json:"name"`             // register the logger under this name
+    Level            string            `json:"level"`            // debug, info, warn, error or fatal; debug by default
+    PackageLevels    map[string]string `json:"packageLevels"`    // levels by import path prefix, see Logger.SetPackageLevels
+    Encoding         string            `json:"encoding"`         // text, console, json or logfmt; text by default
+    Flags            []string          `json:"flags"`            // header flags without the L: date, time, shortfile...
+    Prefix           string            `json:"prefix"`           // prefix of every line
+    TimeFormat       string            `json:"timeFormat"`       // custom time layout, see Logger.SetTimeFormat
+    Location         string            `json:"location"`         // time zone of the header, ex: Asia/Tokyo
+    MaxMessageLength int               `json:"maxMessageLength"` // max characters of a message, 0 for no limit
+    Include          string            `json:"include"`          // regexp, keep only the matching messages
+    Exclude          string            `json:"exclude"`          // regexp, drop the matching messages
+    Filter           string            `json:"filter"`           // rule the entries must match, see CompileRule
+    Redact           []string          `json:"redact"`           // redactions of messages: email, card, ssn
+    RedactFields     []string          `json:"redactFields"`     // keys of the fields written as [REDACTED]
+    HashFields       []string          `json:"hashFields"`       // keys of the fields written as a hash of their value
+    KeepSecrets      bool              `json:"keepSecrets"`      // don't mask secrets in messages, see SetSecretMasking
+    InvalidUTF8      string            `json:"invalidUTF8"`      // keep, replace or escape; keep by default, see SetUTF8Mode
+    Sampling         *SamplingConfig   `json:"sampling"`         // sample repetitive entries, see SamplingSink
+    Outputs          []OutputConfig    `json:"outputs"`          // stderr if empty
+}
+
+// SamplingConfig declares the sampling of a Logger, see SamplingSink.
+type SamplingConfig struct {
+    Tick       string `json:"tick"` // duration, ex: 1s
+    First      uint64 `json:"first"`
+    Thereafter uint64 `json:"thereafter"`
+}
+
+// OutputConfig declares one output of a Logger.
+type OutputConfig struct {
+    Type          string `json:"type"`          // stdout, stderr, file, levels, tenant, route, gelf or webhook
+    Path          string `json:"path"`          // file: path of the log file; levels, tenant: directory of the files; route: path template, see NewTemplateSink
+    MaxSize       uint   `json:"maxSize"`       // file, levels, tenant, route: roll size in bytes
+    DatedArchive  bool   `json:"datedArchive"`  // file: move the rolled files to YYYY/MM/DD directories, see RollFileSink.SetDatedArchive
+    Key           string `json:"key"`           // tenant: key of the field holding the tenant
+    MaxOpen       int    `json:"maxOpen"`       // tenant, route: files kept open, 64 by default
+    Address       string `json:"address"`       // gelf: host:port of the Graylog UDP input
+    Compress      bool   `json:"compress"`      // gelf: zlib compress the messages
+    URL           string `json:"url"`           // webhook: URL the entries are posted to
+    Level         string `json:"level"`         // webhook: lowest level posted, fatal by default
+    Template      string `json:"template"`      // webhook: payload template, see WebhookSink
+    Delivery      string `json:"delivery"`      // gelf, webhook: best-effort, at-least-once or bounded-loss, see Delivery
+    Spool         string `json:"spool"`         // at-least-once: path of the spool file
+    QueueSize     int    `json:"queueSize"`     // bounded-loss: entries kept for retries
+    Include       string `json:"include"`       // regexp, keep only the matching messages
+    Exclude       string `json:"exclude"`       // regexp, drop the matching messages
+    Filter        string `json:"filter"`        // rule the entries must match, see CompileRule
+    LineEnding    string `json:"lineEnding"`    // lf or crlf, lf by default
+    MaxLineLength int    `json:"maxLineLength"` // bytes of a line without line ending, 0 for no limit, see LineLengthSink
+    SplitLines    bool   `json:"splitLines"`    // split the lines longer than maxLineLength instead of cutting them
+}
+
+// flagNames maps the names used in configurations to the flags.
+var flagNames = map[string]int{
+    "date":         Ldate,
+    "time":         Ltime,
+    "microseconds": Lmicroseconds,
+    "longfile":     Llongfile,
+    "shortfile":    Lshortfile,
+    "utc":          LUTC,
+    "rfc3339":      LRFC3339,
+    "epochmillis":  LEpochMillis,
+    "epochnanos":   LEpochNanos,
+    "msgprefix":    Lmsgprefix,
+    "funcname":     Lfuncname,
+    "longfuncname": Llongfuncname,
+    "goroutine":    Lgoroutine,
+    "hostname":     Lhostname,
+    "pid":          Lpid,
+    "seq":          Lseq,
+    "elapsed":      Lelapsed,
+    "delta":        Ldelta,
+    "callerlast":   Lcallerlast,
+    "sanitize":     Lsanitize,
+    "stdflags":     LstdFlags,
+}
+
+// LoadConfig reads a Config from a .json, .yaml, .yml or .toml file. Only
+// the block style subset of YAML is supported: mappings, sequences and
+// scalars. A TOML file may hold the config in a [logging] table, so it can
+// be shared with the rest of a service configuration.
+func LoadConfig(path string) (*Config, error) {
+    b, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+        v, err := parseYAML(string(b))
+        if err != nil {
+            return nil, fmt.Errorf("aralog: %s: %v", path, err)
+        }
+        if b, err = json.Marshal(v); err != nil {
+            return nil, err
+        }
+    case ".toml":
+        t, err := parseTOML(string(b))
+        if err != nil {
+            return nil, fmt.Errorf("aralog: %s: %v", path, err)
+        }
+        var v interface{} = t
+        if logging, ok := t["logging"].(map[string]interface{}); ok {
+            v = logging
+        }
+        if b, err = json.Marshal(v); err != nil {
+            return nil, err
+        }
+    }
+
+    var c Config
+    if err = json.Unmarshal(b, &c); err != nil {
+        return nil, fmt.Errorf("aralog: %s: %v", path, err)
+    }
+    return &c, nil
+}
+
+// NewFromConfigFile creates a Logger as declared by the config file at path.
+func NewFromConfigFile(path string) (*Logger, error) {
+    c, err := LoadConfig(path)
+    if err != nil {
+        return nil, err
+    }
+    return c.Build()
+}
+
+// ConfigError lists all problems Config.Validate found.
+type ConfigError []string
+
+func (e ConfigError) Error() string {
+    return "aralog: invalid config: " + strings.Join(e, "; ")
+}
+
+// Validate checks every setting of c and returns a ConfigError describing
+// all problems, or nil. Values are never coerced: a file output rolled below
+// 1MB is an error, not silently rolled at 10MB.
+func (c *Config) Validate() error {
+    var errs ConfigError
+    fail := func(format string, v ...interface{}) {
+        errs = append(errs, fmt.Sprintf(format, v...))
+    }
+
+    if len(c.Level) > 0 {
+        if _, err := ParseLevel(c.Level); err != nil {
+            fail("unknown level %q", c.Level)
+        }
+    }
+    for prefix, name := range c.PackageLevels {
+        if _, err := ParseLevel(name); err != nil {
+            fail("unknown level %q for package %s", name, prefix)
+        }
+    }
+
+    flag := 0
+    for _, name := range c.Flags {
+        f, ok := flagNames[strings.ToLower(name)]
+        if !ok {
+            fail("unknown flag %q", name)
+        }
+        flag |= f
+    }
+    if flag & LEpochMillis != 0 && flag & LEpochNanos != 0 {
+        fail("flags epochmillis and epochnanos conflict")
+    }
+    if flag & (LEpochMillis | LEpochNanos) != 0 && flag & LRFC3339 != 0 {
+        fail("epoch flags conflict with rfc3339")
+    }
+    if len(c.TimeFormat) > 0 && flag & (LEpochMillis | LEpochNanos | LRFC3339) != 0 {
+        fail("timeFormat conflicts with the rfc3339 and epoch flags")
+    }
+    if len(c.Location) > 0 {
+        if flag & LUTC != 0 {
+            fail("location %q conflicts with flag utc", c.Location)
+        }
+        if _, err := time.LoadLocation(c.Location); err != nil {
+            fail("unknown location %q", c.Location)
+        }
+    }
+    if c.MaxMessageLength < 0 {
+        fail("negative maxMessageLength %d", c.MaxMessageLength)
+    }
+    for _, problem := range validateFilter(c.Include, c.Exclude, c.Filter) {
+        fail("%s", problem)
+    }
+    for _, name := range c.Redact {
+        if _, ok := redactionNames[strings.ToLower(name)]; !ok {
+            fail("unknown redaction %q", name)
+        }
+    }
+
+    if len(c.InvalidUTF8) > 0 {
+        if _, err := ParseUTF8Mode(c.InvalidUTF8); err != nil {
+            fail("unknown invalidUTF8 mode %q", c.InvalidUTF8)
+        }
+    }
+
+    switch strings.ToLower(c.Encoding) {
+    case "", "text", "console", "json", "logfmt":
+    default:
+        fail("unknown encoding %q", c.Encoding)
+    }
+
+    if c.Sampling != nil {
+        if d, err := time.ParseDuration(c.Sampling.Tick); err != nil || d <= 0 {
+            fail("invalid sampling tick %q", c.Sampling.Tick)
+        }
+        if c.Sampling.First == 0 && c.Sampling.Thereafter == 0 {
+            fail("sampling drops every entry, set first or thereafter")
+        }
+    }
+
+    for i, o := range c.Outputs {
+        for _, problem := range o.validate() {
+            fail("outputs[%d]: %s", i, problem)
+        }
+    }
+
+    if len(errs) > 0 {
+        return errs
+    }
+    return nil
+}
+
+func (o OutputConfig) validate() []string {
+    var problems []string
+    switch strings.ToLower(o.Type) {
+    case "stdout", "stderr":
+    case "file":
+        if len(o.Path) == 0 {
+            problems = append(problems, "file output without path")
+        } else if fi, err := os.Stat(o.Path); err == nil && fi.IsDir() {
+            problems = append(problems, fmt.Sprintf("path %q is a directory", o.Path))
+        } else if fi, err := os.Stat(filepath.Dir(o.Path)); err == nil && !fi.IsDir() {
+            problems = append(problems, fmt.Sprintf("parent of path %q is not a directory", o.Path))
+        }
+        if o.MaxSize != 0 && o.MaxSize < 1024 * 1024 {
+            problems = append(problems, fmt.Sprintf("maxSize %d is below the minimum of 1MB", o.MaxSize))
+        }
+    case "levels", "tenant":
+        if len(o.Path) == 0 {
+            problems = append(problems, fmt.Sprintf("%s output without path", strings.ToLower(o.Type)))
+        } else if fi, err := os.Stat(o.Path); err == nil && !fi.IsDir() {
+            problems = append(problems, fmt.Sprintf("path %q is not a directory", o.Path))
+        }
+        if len(o.Key) == 0 && strings.EqualFold(o.Type, "tenant") {
+            problems = append(problems, "tenant output without key")
+        }
+        if o.MaxSize != 0 && o.MaxSize < 1024 * 1024 {
+            problems = append(problems, fmt.Sprintf("maxSize %d is below the minimum of 1MB", o.MaxSize))
+        }
+        if o.MaxOpen < 0 {
+            problems = append(problems, fmt.Sprintf("negative maxOpen %d", o.MaxOpen))
+        }
+    case "route":
+        if len(o.Path) == 0 {
+            problems = append(problems, "route output without path")
+        } else if _, err := parsePathTemplate(o.Path); err != nil {
+            problems = append(problems, strings.TrimPrefix(err.Error(), "aralog: "))
+        }
+        if o.MaxSize != 0 && o.MaxSize < 1024 * 1024 {
+            problems = append(problems, fmt.Sprintf("maxSize %d is below the minimum of 1MB", o.MaxSize))
+        }
+        if o.MaxOpen < 0 {
+            problems = append(problems, fmt.Sprintf("negative maxOpen %d", o.MaxOpen))
+        }
+    case "gelf":
+        if _, _, err := net.SplitHostPort(o.Address); err != nil {
+            problems = append(problems, fmt.Sprintf("invalid gelf address %q", o.Address))
+        }
+    case "webhook":
+        if u, err := url.Parse(o.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+            problems = append(problems, fmt.Sprintf("invalid webhook url %q", o.URL))
+        }
+        if len(o.Level) > 0 {
+            if _, err := ParseLevel(o.Level); err != nil {
+                problems = append(problems, fmt.Sprintf("invalid webhook level %q", o.Level))
+            }
+        }
+        if _, err := NewWebhookSink(o.URL, o.Template); err != nil {
+            problems = append(problems, strings.TrimPrefix(err.Error(), "aralog: "))
+        }
+    default:
+        problems = append(problems, fmt.Sprintf("unknown output type %q", o.Type))
+    }
+    if len(o.Delivery) > 0 {
+        d, err := ParseDelivery(o.Delivery)
+        network := strings.EqualFold(o.Type, "gelf") || strings.EqualFold(o.Type, "webhook")
+        switch {
+        case err != nil:
+            problems = append(problems, fmt.Sprintf("unknown delivery %q", o.Delivery))
+        case !network:
+            problems = append(problems, fmt.Sprintf("delivery of %s output, only network outputs have one", o.Type))
+        case d == AtLeastOnce && !strings.EqualFold(o.Type, "webhook"):
+            problems = append(problems, fmt.Sprintf("at-least-once delivery of %s output, which has no acknowledgements", o.Type))
+        case d == AtLeastOnce && len(o.Spool) == 0:
+            problems = append(problems, "at-least-once delivery without spool")
+        }
+    }
+    if o.MaxLineLength < 0 {
+        problems = append(problems, fmt.Sprintf("negative maxLineLength %d", o.MaxLineLength))
+    }
+    if len(o.LineEnding) > 0 {
+        if _, err := ParseLineEnding(o.LineEnding); err != nil {
+            problems = append(problems, fmt.Sprintf("unknown line ending %q", o.LineEnding))
+        }
+    }
+    return append(problems, validateFilter(o.Include, o.Exclude, o.Filter)...)
+}
+
+func validateFilter(include, exclude, filter string) []string {
+    var problems []string
+    if _, err := regexp.Compile(include); err != nil {
+        problems = append(problems, fmt.Sprintf("invalid include %q: %v", include, err))
+    }
+    if _, err := regexp.Compile(exclude); err != nil {
+        problems = append(problems, fmt.Sprintf("invalid exclude %q: %v", exclude, err))
+    }
+    if len(filter) > 0 {
+        if _, err := CompileRule(filter); err != nil {
+            problems = append(problems, strings.TrimPrefix(err.Error(), "aralog: "))
+        }
+    }
+    return problems
+}
+
+// compileFilter compiles validated include and exclude patterns, empty ones to nil.
+func compileFilter(include, exclude string) (inc, exc *regexp.Regexp) {
+    if len(include) > 0 {
+        inc = regexp.MustCompile(include)
+    }
+    if len(exclude) > 0 {
+        exc = regexp.MustCompile(exclude)
+    }
+    return inc, exc
+}
+
+// Build validates c and creates the Logger it declares. If c has a Name,
+// the logger is registered under it.
+func (c *Config) Build() (*Logger, error) {
+    l, err := c.build()
+    if err != nil {
+        return nil, err
+    }
+    if len(c.Name) > 0 {
+        Register(c.Name, l)
+    }
+    return l, nil
+}
+
+// build is Build without registering the logger.
+func (c *Config) build() (*Logger, error) {
+    if err := c.Validate(); err != nil {
+        return nil, err
+    }
+
+    level := LevelDebug
+    if len(c.Level) > 0 {
+        level, _ = ParseLevel(c.Level)
+    }
+
+    flag := LstdFlags
+    if c.Flags != nil {
+        flag = 0
+        for _, name := range c.Flags {
+            flag |= flagNames[strings.ToLower(name)]
+        }
+    }
+
+    outputs := c.Outputs
+    if len(outputs) == 0 {
+        outputs = []OutputConfig{{Type: "stderr"}}
+    }
+    var sinks MultiSink
+    for _, o := range outputs {
+        s, err := o.build()
+        if err != nil {
+            sinks.Close()
+            return nil, err
+        }
+        sinks = append(sinks, s)
+    }
+
+    var sink Sink = sinks
+    if len(sinks) == 1 {
+        sink = sinks[0]
+    }
+    if c.Sampling != nil {
+        tick, _ := time.ParseDuration(c.Sampling.Tick)
+        sink = NewSamplingSink(sink, tick, c.Sampling.First, c.Sampling.Thereafter)
+    }
+
+    l := NewSinkLogger(sink, c.Prefix, flag)
+    l.SetLevel(level)
+    if len(c.PackageLevels) > 0 {
+        levels := map[string]Level{}
+        for prefix, name := range c.PackageLevels {
+            levels[prefix], _ = ParseLevel(name)
+        }
+        l.SetPackageLevels(levels)
+    }
+    l.SetTimeFormat(c.TimeFormat)
+    l.SetMaxMessageLength(c.MaxMessageLength)
+    l.SetMessageFilter(compileFilter(c.Include, c.Exclude))
+    if len(c.Filter) > 0 {
+        l.SetFilterRule(MustCompileRule(c.Filter))
+    }
+    var redactions []Redaction
+    for _, name := range c.Redact {
+        redactions = append(redactions, redactionNames[strings.ToLower(name)])
+    }
+    l.SetRedactions(redactions...)
+    l.SetRedactedFields(MaskRedact, c.RedactFields...)
+    l.SetRedactedFields(MaskHash, c.HashFields...)
+    l.SetSecretMasking(!c.KeepSecrets)
+    if len(c.InvalidUTF8) > 0 {
+        mode, _ := ParseUTF8Mode(c.InvalidUTF8)
+        l.SetUTF8Mode(mode)
+    }
+    if len(c.Location) > 0 {
+        loc, _ := time.LoadLocation(c.Location)
+        l.SetLocation(loc)
+    }
+    switch strings.ToLower(c.Encoding) {
+    case "console":
+        // color only if the first output is a terminal
+        var w io.Writer
+        if f := outputs[0].writer(); f != nil {
+            w = f
+        }
+        l.SetEncoder(NewConsoleEncoderFor(w))
+    case "json":
+        l.SetEncoder(NewJSONEncoder())
+    case "logfmt":
+        l.SetEncoder(NewLogfmtEncoder())
+    }
+    return l, nil
+}
+
+// writer returns the standard stream of stdout and stderr outputs, nil otherwise.
+func (o OutputConfig) writer() *os.File {
+    switch strings.ToLower(o.Type) {
+    case "stdout":
+        return os.Stdout
+    case "stderr":
+        return os.Stderr
+    }
+    return nil
+}
+
+func (o OutputConfig) build() (Sink, error) {
+    s, err := o.buildSink()
+    if err == nil && o.MaxLineLength > 0 {
+        s = NewLineLengthSink(s, o.MaxLineLength, o.SplitLines)
+    }
+    if err == nil && len(o.LineEnding) > 0 {
+        if ending, _ := ParseLineEnding(o.LineEnding); ending != LF {
+            s = NewLineEndingSink(s, ending)
+        }
+    }
+    if err == nil && len(o.Delivery) > 0 {
+        d, _ := ParseDelivery(o.Delivery)
+        if s, err = NewDeliverySink(s, d, DeliveryOptions{SpoolPath: o.Spool, QueueSize: o.QueueSize}); err != nil {
+            return nil, err
+        }
+    }
+    if err != nil || len(o.Include) + len(o.Exclude) + len(o.Filter) == 0 {
+        return s, err
+    }
+    include, exclude := compileFilter(o.Include, o.Exclude)
+    f := NewFilterSink(s, include, exclude)
+    if len(o.Filter) > 0 {
+        f.Rule = MustCompileRule(o.Filter)
+    }
+    return f, nil
+}
+
+func (o OutputConfig) buildSink() (Sink, error) {
+    switch strings.ToLower(o.Type) {
+    case "file":
+        maxsize := o.MaxSize
+        if maxsize == 0 {
+            maxsize = 1024 * 1024 * 10
+        }
+        s, err := NewRollFileSink(o.Path, maxsize)
+        if err != nil {
+            return nil, err
+        }
+        s.SetDatedArchive(o.DatedArchive)
+        return s, nil
+    case "levels":
+        return NewLevelSink(o.Path, o.MaxSize), nil
+    case "tenant", "route":
+        maxOpen := o.MaxOpen
+        if maxOpen == 0 {
+            maxOpen = 64
+        }
+        if strings.EqualFold(o.Type, "route") {
+            return NewTemplateSink(o.Path, o.MaxSize, maxOpen)
+        }
+        return NewTenantSink(o.Path, o.Key, o.MaxSize, maxOpen), nil
+    case "gelf":
+        s, err := NewGELFSink(o.Address)
+        if err != nil {
+            return nil, err
+        }
+        s.Compress = o.Compress
+        return s, nil
+    case "webhook":
+        s, err := NewWebhookSink(o.URL, o.Template)
+        if err != nil {
+            return nil, err
+        }
+        if len(o.Level) > 0 {
+            s.MinLevel, _ = ParseLevel(o.Level)
+        }
+        return s, nil
+    }
+    return NewWriterSink(o.writer()), nil
+}
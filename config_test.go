@@ -0,0 +1,152 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testYAMLConfig = `
+# service logging
+level: info
+encoding: json
+flags: [date, time, shortfile]
+outputs:
+  - type: file
+    path: "%s"   # rolled at 1MB
+    maxSize: 1048576
+  - type: stderr
+`
+
+func TestLoadConfigYAML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "app.log")
+	path := filepath.Join(dir, "aralog.yaml")
+	ioutil.WriteFile(path, []byte(strings.Replace(testYAMLConfig, "%s", logPath, 1)), 0600)
+
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Config{
+		Level:    "info",
+		Encoding: "json",
+		Flags:    []string{"date", "time", "shortfile"},
+		Outputs:  []OutputConfig{{Type: "file", Path: logPath, MaxSize: 1048576}, {Type: "stderr"}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("expected %+v, got %+v", want, c)
+	}
+
+	c.Outputs = c.Outputs[:1]
+	logger, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("dropped")
+	logger.Info("kept")
+	logger.Close()
+
+	b, _ := ioutil.ReadFile(logPath)
+	if !strings.Contains(string(b), `"level":"INFO","caller":"config_test.go:`) || strings.Contains(string(b), "dropped") {
+		t.Errorf("unexpected log file content %q", b)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	v, err := parseYAML("a:\n  b: 010\n  c:\n  - x\n  - 'y z'\nd: ~\ne: [\"x,y\", 'z']\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"a": map[string]interface{}{"b": int64(10), "c": []interface{}{"x", "y z"}},
+		"d": nil,
+		"e": []interface{}{"x,y", "z"},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("expected %v, got %v", want, v)
+	}
+
+	if _, err = parseYAML("a: 1\n   b: 2\n"); err == nil {
+		t.Error("expected indentation error")
+	}
+}
+
+const testTOMLConfig = `
+[server]
+port = 8080
+
+[logging]
+level = "warn"   # quiet
+flags = ["date", "time"]
+
+[[logging.outputs]]
+type = "stdout"
+
+[[logging.outputs]]
+type = 'gelf'
+address = "127.0.0.1:12201"
+compress = true
+`
+
+func TestLoadConfigTOML(t *testing.T) {
+	f, err := ioutil.TempFile("", "aralog*.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(testTOMLConfig)
+	f.Close()
+
+	c, err := LoadConfig(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Config{
+		Level:   "warn",
+		Flags:   []string{"date", "time"},
+		Outputs: []OutputConfig{{Type: "stdout"}, {Type: "gelf", Address: "127.0.0.1:12201", Compress: true}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("expected %+v, got %+v", want, c)
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	v, err := parseTOML("a = 010\nb = 0x1f\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v["a"] != int64(10) || v["b"] != int64(31) {
+		t.Errorf("unexpected integers %v", v)
+	}
+	for _, doc := range []string{"a = []\n[a]\n", "a = []\na.b = 1\n", "= 1\n", "[a.]\n"} {
+		if _, err := parseTOML(doc); err == nil {
+			t.Errorf("%q accepted", doc)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	c := &Config{
+		Level:   "verbose",
+		Flags:   []string{"epochmillis", "rfc3339"},
+		Outputs: []OutputConfig{{Type: "file", Path: os.TempDir(), MaxSize: 1024}, {Type: "gelf"}, {Type: "webhook", URL: "hooks.example.com", Level: "loud"}, {Type: "gelf", Address: "127.0.0.1:12201", Delivery: "at-least-once"}},
+	}
+	err := c.Validate()
+	errs, ok := err.(ConfigError)
+	if !ok || len(errs) != 8 {
+		t.Fatalf("expected 8 problems, got %v", err)
+	}
+	if _, err = c.Build(); err == nil {
+		t.Error("expected Build to fail")
+	}
+}
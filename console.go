@@ -0,0 +1,231 @@
+package aralog
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "sync/atomic"
+    "unicode/utf8"
+)
+
+// ANSI SGR sequences used by the default color scheme.
+const (
+    ansiReset   = "\x1b[0m"
+    ansiBold    = "\x1b[1m"
+    ansiDim     = "\x1b[2m"
+    ansiRed     = "\x1b[31m"
+    ansiGreen   = "\x1b[32m"
+    ansiYellow  = "\x1b[33m"
+    ansiMagenta = "\x1b[35m"
+    ansiCyan    = "\x1b[36m"
+    ansiBoldRed = "\x1b[1;31m"
+)
+
+// ColorScheme holds the ANSI SGR sequences the ConsoleEncoder wraps each
+// part of an entry in. An empty sequence leaves that part uncolored.
+type ColorScheme struct {
+    Levels [LevelFatal + 1]string
+    Time   string
+    Caller string
+    Key    string // field keys
+}
+
+// DefaultColorScheme is the color scheme of a new ConsoleEncoder.
+var DefaultColorScheme = ColorScheme{
+    Levels: [LevelFatal + 1]string{ansiMagenta, ansiGreen, ansiYellow, ansiRed, ansiBoldRed},
+    Time:   ansiDim,
+    Caller: ansiBold,
+    Key:    ansiCyan,
+}
+
+// Compact level badges for ConsoleEncoder.Badges, indexed by Level.
+var (
+    ShortBadges  = []string{"DBG", "INF", "WRN", "ERR", "FTL"}
+    SymbolBadges = []string{"·", "ℹ", "⚠", "✖", "☠"}
+)
+
+// ConsoleEncoder is an Encoder for humans reading a terminal: a dim
+// timestamp, the level colored by severity, the highlighted caller, the
+// message and the fields with colored keys.
+type ConsoleEncoder struct {
+    Colors       ColorScheme
+    TimeLayout   string   // time.Format layout of the timestamp, empty to omit it
+    ForceColor   bool     // color even if the output does not support it
+    DisableColor bool     // never color, overrides ForceColor
+    Badges       []string // labels indexed by Level, nil for the level names
+    Columns      bool     // pad caller and message into aligned columns
+    CallerWidth  int      // caller column width in Columns layout, longer callers are cut on the left
+    MessageWidth int      // message column width in Columns layout, the fields start after it
+    CallerAtEnd  bool     // put the caller at the end of the line: message (d.go:23)
+    detected     bool     // whether the output supports colors
+    out          *os.File // the output of NewConsoleEncoderFor, if a file
+    vtEnabled    int32    // 1 once escape sequences were enabled on out, accessed atomically
+}
+
+// NewConsoleEncoder creates a ConsoleEncoder using the DefaultColorScheme,
+// assuming the output supports colors.
+func NewConsoleEncoder() *ConsoleEncoder {
+    return &ConsoleEncoder{
+        Colors:       DefaultColorScheme,
+        TimeLayout:   "15:04:05.000",
+        CallerWidth:  24,
+        MessageWidth: 48,
+        detected:     true,
+    }
+}
+
+// NewConsoleEncoderFor creates a ConsoleEncoder like NewConsoleEncoder, with
+// colors only enabled if w supports them, see ColorSupported. If colors are
+// forced on a Windows console w, it is made to process escape sequences.
+func NewConsoleEncoderFor(w io.Writer) *ConsoleEncoder {
+    c := NewConsoleEncoder()
+    c.detected = ColorSupported(w)
+    c.out, _ = w.(*os.File)
+    return c
+}
+
+// NewConsoleLogger creates a Logger writing to w with a ConsoleEncoder
+// created by NewConsoleEncoderFor.
+func NewConsoleLogger(w io.Writer, flag int) *Logger {
+    l := New(w, "", flag)
+    l.SetEncoder(NewConsoleEncoderFor(w))
+    return l
+}
+
+// ColorSupported reports whether w is a terminal which may be colored:
+// NO_COLOR must not be set and TERM must not be "dumb". On Windows it also
+// enables ANSI escape sequence processing of the console.
+func ColorSupported(w io.Writer) bool {
+    if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+        return false
+    }
+    f, ok := w.(*os.File)
+    if !ok {
+        return false
+    }
+    fi, err := f.Stat()
+    if err != nil || fi.Mode() & os.ModeCharDevice == 0 {
+        return false
+    }
+    return enableVirtualTerminal(f)
+}
+
+func (c *ConsoleEncoder) useColor() bool {
+    if c.DisableColor {
+        return false
+    }
+    if c.ForceColor && c.out != nil && atomic.CompareAndSwapInt32(&c.vtEnabled, 0, 1) {
+        // on Windows, the console prints forced colors as raw sequences
+        // unless told to process them
+        enableVirtualTerminal(c.out)
+    }
+    return c.ForceColor || c.detected
+}
+
+func (c *ConsoleEncoder) Encode(buf *[]byte, e Entry) {
+    if len(c.TimeLayout) > 0 {
+        c.colored(buf, c.Colors.Time, e.Time.Format(c.TimeLayout))
+        *buf = append(*buf, ' ')
+    }
+
+    c.colored(buf, c.levelColor(e.Level), c.levelLabel(e.Level))
+    *buf = append(*buf, ' ')
+
+    if len(e.File) > 0 && !c.CallerAtEnd {
+        caller := callerString(e)
+        if c.Columns {
+            caller = padColumn(caller, c.CallerWidth, true)
+        }
+        c.colored(buf, c.Colors.Caller, caller)
+        *buf = append(*buf, ' ')
+    }
+
+    msg := strings.TrimSuffix(e.Message, "\n")
+    if c.Columns && len(e.Fields) > 0 {
+        msg = padColumn(msg, c.MessageWidth, false)
+    }
+    *buf = append(*buf, msg...)
+    for _, f := range e.Fields {
+        *buf = append(*buf, ' ')
+        c.colored(buf, c.Colors.Key, f.Key)
+        *buf = append(*buf, '=')
+        appendFieldValue(buf, f.Value)
+    }
+    if len(e.File) > 0 && c.CallerAtEnd {
+        *buf = append(*buf, ' ')
+        c.colored(buf, c.Colors.Caller, "(" + callerString(e) + ")")
+    }
+    *buf = append(*buf, '\n')
+}
+
+// levelLabel returns the badge or name of level, padded to the width of the
+// widest one so the messages line up.
+func (c *ConsoleEncoder) levelLabel(level Level) string {
+    labels := c.Badges
+    if labels == nil {
+        labels = levelNames
+    }
+
+    label := level.String()
+    if level >= 0 && int(level) < len(labels) {
+        label = labels[level]
+    }
+
+    width := 0
+    for _, l := range labels {
+        if n := utf8.RuneCountInString(l); n > width {
+            width = n
+        }
+    }
+    if n := utf8.RuneCountInString(label); n < width {
+        label += strings.Repeat(" ", width - n)
+    }
+    return label
+}
+
+func (c *ConsoleEncoder) levelColor(level Level) string {
+    if level < LevelDebug || level > LevelFatal {
+        return ""
+    }
+    return c.Colors.Levels[level]
+}
+
+// colored appends s wrapped in the color sequence, if any and enabled.
+func (c *ConsoleEncoder) colored(buf *[]byte, color, s string) {
+    if len(color) == 0 || !c.useColor() {
+        *buf = append(*buf, s...)
+        return
+    }
+    *buf = append(*buf, color...)
+    *buf = append(*buf, s...)
+    *buf = append(*buf, ansiReset...)
+}
+
+// padColumn pads s with spaces to width characters. If cut is set, a
+// longer s is cut to width keeping its end, which is the interesting part
+// of a caller. Characters are grapheme clusters, see Truncate.
+func padColumn(s string, width int, cut bool) string {
+    n := CharCount(s, true)
+    if n < width {
+        return s + strings.Repeat(" ", width - n)
+    }
+    if cut && width > 0 && n > width {
+        // skip the first n - width characters
+        i := 0
+        for ; n > width; n-- {
+            i += charLen(s[i:], true)
+        }
+        return s[i:]
+    }
+    return s
+}
+
+// callerString returns the final file name element and line of the caller: d.go:23.
+func callerString(e Entry) string {
+    file := e.File
+    if i := strings.LastIndex(file, "/"); i >= 0 {
+        file = file[i + 1:]
+    }
+    return fmt.Sprintf("%s:%d", file, e.Line)
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package aralog
+
+import (
+    "os"
+)
+
+// enableVirtualTerminal reports true, terminals other than the Windows
+// console process ANSI escape sequences natively.
+func enableVirtualTerminal(f *os.File) bool {
+    return true
+}
@@ -0,0 +1,93 @@
+package aralog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestConsoleEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", Lshortfile)
+	enc := NewConsoleEncoder()
+	enc.TimeLayout = ""
+	logger.SetEncoder(enc)
+	logger.With(F("id", 7)).Error("failed")
+
+	want := "\x1b[31mERROR\x1b[0m \x1b[1mconsole_test.go:16\x1b[0m failed \x1b[36mid\x1b[0m=7\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestConsoleColorDetection(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewConsoleLogger(&buf, 0)
+	logger.Encoder().(*ConsoleEncoder).TimeLayout = ""
+	logger.Info("plain")
+	if buf.String() != "INFO  plain\n" {
+		t.Errorf("expected no colors for a buffer, got %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.Encoder().(*ConsoleEncoder).ForceColor = true
+	logger.Info("forced")
+	if buf.String() != "\x1b[32mINFO \x1b[0m forced\n" {
+		t.Errorf("expected forced colors, got %q", buf.String())
+	}
+}
+
+func TestConsoleForceColorFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	enc := NewConsoleEncoderFor(f)
+	var buf []byte
+	enc.Encode(&buf, Entry{Level: LevelInfo, Message: "plain"})
+	if enc.vtEnabled != 0 {
+		t.Error("escape sequences enabled without forced colors")
+	}
+	enc.ForceColor = true
+	enc.Encode(&buf, Entry{Level: LevelInfo, Message: "forced"})
+	if enc.vtEnabled != 1 {
+		t.Error("escape sequences not enabled for forced colors")
+	}
+}
+
+func TestConsoleBadges(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewConsoleLogger(&buf, 0)
+	enc := logger.Encoder().(*ConsoleEncoder)
+	enc.TimeLayout = ""
+	enc.Badges = ShortBadges
+	logger.Warn("w")
+	enc.Badges = SymbolBadges
+	logger.Error("e")
+
+	if buf.String() != "WRN w\n✖ e\n" {
+		t.Errorf("unexpected badges %q", buf.String())
+	}
+}
+
+func TestConsoleColumns(t *testing.T) {
+	enc := NewConsoleEncoder()
+	enc.DisableColor = true
+	enc.TimeLayout = ""
+	enc.Columns = true
+	enc.CallerWidth = 8
+	enc.MessageWidth = 6
+
+	var b []byte
+	enc.Encode(&b, Entry{Level: LevelInfo, File: "/src/server.go", Line: 42, Message: "up", Fields: []Field{F("port", 80)}})
+	enc.Encode(&b, Entry{Level: LevelError, File: "/src/a.go", Line: 7, Message: "down", Fields: []Field{F("port", 80)}})
+
+	want := "INFO  er.go:42 up     port=80\nERROR a.go:7   down   port=80\n"
+	if string(b) != want {
+		t.Errorf("expected %q, got %q", want, b)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build windows
+// +build windows
+
+package aralog
+
+import (
+    "os"
+    "syscall"
+    "unsafe"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var (
+    kernel32           = syscall.NewLazyDLL("kernel32.dll")
+    procGetConsoleMode = kernel32.NewProc("GetConsoleMode")
+    procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
+)
+
+// enableVirtualTerminal turns on ANSI escape sequence processing for the
+// console f refers to, so colors don't print as garbage in cmd and
+// PowerShell. It reports false if the console can't process them, which is
+// the case before Windows 10.
+func enableVirtualTerminal(f *os.File) bool {
+    var mode uint32
+    h := f.Fd()
+    if r, _, _ := procGetConsoleMode.Call(h, uintptr(unsafe.Pointer(&mode))); r == 0 {
+        return false
+    }
+    if mode & enableVirtualTerminalProcessing != 0 {
+        return true
+    }
+    r, _, _ := procSetConsoleMode.Call(h, uintptr(mode | enableVirtualTerminalProcessing))
+    return r != 0
+}
@@ -0,0 +1,73 @@
+package aralog
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "net/http"
+)
+
+type contextKey int
+
+const (
+    loggerKey contextKey = iota
+    correlationKey
+    traceKey
+)
+
+// NewContext returns a copy of ctx carrying l, ex: a logger with fields of
+// the request being served.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+    return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger carried by ctx, nil if there is none.
+func FromContext(ctx context.Context) *Logger {
+    l, _ := ctx.Value(loggerKey).(*Logger)
+    return l
+}
+
+// CorrelationHeader is the HTTP header carrying correlation IDs.
+var CorrelationHeader = "X-Request-ID"
+
+// NewCorrelationID returns a random ID of 32 hexadecimal digits.
+func NewCorrelationID() string {
+    var b [16]byte
+    rand.Read(b[:])
+    return hex.EncodeToString(b[:])
+}
+
+// WithCorrelationID returns a copy of ctx carrying the correlation ID id.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+    return context.WithValue(ctx, correlationKey, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, empty if there
+// is none.
+func CorrelationID(ctx context.Context) string {
+    id, _ := ctx.Value(correlationKey).(string)
+    return id
+}
+
+// CorrelationMiddleware returns a middleware giving every request a
+// correlation ID: the one in the CorrelationHeader of the request if any,
+// a new one otherwise. The ID is echoed in the same header of the response
+// and stored in the context of the request, with a logger derived from l
+// with the field correlation_id, see FromContext:
+//
+//	aralog.FromContext(r.Context()).Info("user %s created", name)
+func CorrelationMiddleware(l *Logger) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            id := r.Header.Get(CorrelationHeader)
+            if len(id) == 0 || len(id) > 128 {
+                id = NewCorrelationID()
+            }
+            w.Header().Set(CorrelationHeader, id)
+
+            ctx := WithCorrelationID(r.Context(), id)
+            ctx = NewContext(ctx, l.With(F("correlation_id", id)))
+            next.ServeHTTP(w, r.WithContext(ctx))
+        })
+    }
+}
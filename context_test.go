@@ -0,0 +1,35 @@
+package aralog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCorrelationMiddleware(t *testing.T) {
+	ring := NewRingSink(4)
+	handler := CorrelationMiddleware(NewSinkLogger(ring, "", 0))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handled %s", CorrelationID(r.Context()))
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "abc123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Header().Get("X-Request-ID") != "abc123" {
+		t.Errorf("correlation ID not echoed: %v", rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	id := rec.Header().Get("X-Request-ID")
+	if len(id) != 32 {
+		t.Errorf("unexpected generated ID %q", id)
+	}
+
+	entries := ring.Entries()
+	if len(entries) != 2 || string(entries[0].Formatted) != "handled abc123 correlation_id=abc123\n" ||
+		string(entries[1].Formatted) != "handled "+id+" correlation_id="+id+"\n" {
+		t.Errorf("unexpected entries %v", entries)
+	}
+}
@@ -0,0 +1,44 @@
+package aralog
+
+import (
+    "io"
+)
+
+// Convert reads the entries of r and writes them to w rendered by enc, to
+// migrate logs from one format to another, for instance text to JSON:
+//
+//	p := aralog.NewParser("", aralog.LstdFlags)
+//	p.Fields = true
+//	n, err := aralog.Convert(out, aralog.NewTextReader(in, p), aralog.NewJSONEncoder())
+//
+// Times, levels, callers and fields are kept as far as the source format
+// has them. Lines which do not parse are skipped; Convert goes on and
+// returns the first *ParseError once r is exhausted. It returns the number
+// of entries written.
+func Convert(w io.Writer, r EntryReader, enc Encoder) (int, error) {
+    var n int
+    var skipped error
+    var buf []byte
+    for {
+        e, err := r.Read()
+        if err == io.EOF {
+            return n, skipped
+        }
+        if _, ok := err.(*ParseError); ok {
+            if skipped == nil {
+                skipped = err
+            }
+            continue
+        }
+        if err != nil {
+            return n, err
+        }
+
+        buf = buf[:0]
+        enc.Encode(&buf, e)
+        if _, err := w.Write(buf); err != nil {
+            return n, err
+        }
+        n++
+    }
+}
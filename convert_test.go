@@ -0,0 +1,74 @@
+package aralog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtEncoder(t *testing.T) {
+	var buf []byte
+	e := Entry{
+		Time:    time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC),
+		Level:   LevelWarn,
+		File:    "/a/b/d.go",
+		Line:    23,
+		Message: "disk almost full\n",
+		Fields:  []Field{F("free", 0.05), F("mount", "/var"), F("err", errors.New("quota = exceeded")), F("empty", "")},
+	}
+	NewLogfmtEncoder().Encode(&buf, e)
+	want := `time=2009-01-23T01:23:23.000000Z level=warn caller=d.go:23 msg="disk almost full" free=0.05 mount=/var err="quota = exceeded" empty=""` + "\n"
+	if string(buf) != want {
+		t.Errorf("got %q, want %q", buf, want)
+	}
+
+	back, err := ParseLogfmtLine(string(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !back.Time.Equal(e.Time) || back.Level != e.Level || back.Line != 23 || back.Message != "disk almost full" || len(back.Fields) != 4 {
+		t.Errorf("parsed back %+v", back)
+	}
+}
+
+func TestConvertTextToJSON(t *testing.T) {
+	in := "2009/01/23 01:23:23 started port=8080\n" +
+		"2009/01/23 01:23:24 panic: boom\n" +
+		"goroutine 1 [running]\n"
+	p := NewParser("", LstdFlags)
+	p.Location = time.UTC
+	p.Fields = true
+
+	var out bytes.Buffer
+	n, err := Convert(&out, NewTextReader(strings.NewReader(in), p), NewJSONEncoder())
+	if err != nil || n != 2 {
+		t.Fatalf("converted %d entries, %v", n, err)
+	}
+	want := `{"time":"2009-01-23T01:23:23.000000Z","level":"INFO","msg":"started","port":"8080"}` + "\n" +
+		`{"time":"2009-01-23T01:23:24.000000Z","level":"INFO","msg":"panic: boom\ngoroutine 1 [running]"}` + "\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestConvertJSONToLogfmt(t *testing.T) {
+	in := `{"time":"2009-01-23T01:23:23.5Z","level":"ERROR","caller":"d.go:23","msg":"failed","attempt":3}` + "\n" +
+		"not json\n" +
+		`{"time":"2009-01-23T01:23:24Z","level":"DEBUG","msg":"retrying"}` + "\n"
+
+	var out bytes.Buffer
+	n, err := Convert(&out, NewJSONReader(strings.NewReader(in)), NewLogfmtEncoder())
+	if n != 2 {
+		t.Errorf("converted %d entries, want 2", n)
+	}
+	if perr, ok := err.(*ParseError); !ok || perr.Line != "not json\n" {
+		t.Errorf("got error %v, want the parse error of the skipped line", err)
+	}
+	want := "time=2009-01-23T01:23:23.500000Z level=error caller=d.go:23 msg=failed attempt=3\n" +
+		"time=2009-01-23T01:23:24.000000Z level=debug msg=retrying\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
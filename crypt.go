@@ -0,0 +1,214 @@
+package aralog
+
+import (
+    "bufio"
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "os"
+    "sync"
+)
+
+// maxChunk bounds the size of an encrypted chunk accepted by DecryptReader.
+const maxChunk = 16 << 20
+
+// ErrCorruptChunk is returned by DecryptReader for a chunk which fails
+// authentication: the data was altered or the key is wrong.
+var ErrCorruptChunk = errors.New("aralog: corrupt or tampered encrypted chunk")
+
+// EncryptWriter encrypts everything written to it with AES-GCM before it
+// reaches the underlying writer, for logs which must never be on disk in
+// clear. Every Write is sealed as one chunk: the 4-byte big-endian length
+// of the rest of the chunk, a random 12-byte nonce and the ciphertext with
+// its tag. Used under a Logger, a chunk is one entry, so a file cut by a
+// crash loses at most its last entry; OpenEncryptFile drops that torn
+// chunk before appending, ex:
+//
+//	w, err := aralog.OpenEncryptFile("secret.log", key)
+//	logger := aralog.New(w, "", aralog.LstdFlags)
+//
+// The chunks are read back by DecryptReader.
+type EncryptWriter struct {
+    mu   sync.Mutex
+    w    io.Writer
+    aead cipher.AEAD
+    buf  []byte
+}
+
+// NewEncryptWriter creates an EncryptWriter writing to w. The key must be 16,
+// 24 or 32 bytes long, selecting AES-128, AES-192 or AES-256.
+func NewEncryptWriter(w io.Writer, key []byte) (*EncryptWriter, error) {
+    aead, err := newGCM(key)
+    if err != nil {
+        return nil, err
+    }
+    return &EncryptWriter{w: w, aead: aead}, nil
+}
+
+// OpenEncryptFile opens the file at path for appending chunks encrypted with
+// key, creating it if needed. A chunk cut by a crash at the end of the file
+// is truncated first, so the chunks appended stay readable. The file is
+// closed by Close.
+func OpenEncryptFile(path string, key []byte) (*EncryptWriter, error) {
+    aead, err := newGCM(key)
+    if err != nil {
+        return nil, err
+    }
+    f, err := os.OpenFile(path, os.O_CREATE | os.O_RDWR, 0600)
+    if err != nil {
+        return nil, err
+    }
+    end, err := lastChunkEnd(f, aead)
+    if err == nil {
+        err = f.Truncate(end)
+    }
+    if err == nil {
+        _, err = f.Seek(end, io.SeekStart)
+    }
+    if err != nil {
+        f.Close()
+        return nil, fmt.Errorf("aralog: %s: %v", path, err)
+    }
+    return &EncryptWriter{w: f, aead: aead}, nil
+}
+
+// lastChunkEnd returns the offset after the last complete chunk of r. The
+// chunks are not opened, only their lengths are checked.
+func lastChunkEnd(r io.Reader, aead cipher.AEAD) (int64, error) {
+    br := bufio.NewReader(r)
+    var end int64
+    for {
+        var head [4]byte
+        if _, err := io.ReadFull(br, head[:]); err == io.EOF || err == io.ErrUnexpectedEOF {
+            return end, nil
+        } else if err != nil {
+            return 0, err
+        }
+        size := int64(binary.BigEndian.Uint32(head[:]))
+        if size < int64(aead.NonceSize() + aead.Overhead()) || size > maxChunk {
+            return 0, ErrCorruptChunk
+        }
+        n, err := io.CopyN(ioutil.Discard, br, size)
+        if n < size {
+            return end, nil
+        }
+        if err != nil {
+            return 0, err
+        }
+        end += 4 + size
+    }
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, fmt.Errorf("aralog: encryption key: %v", err)
+    }
+    return cipher.NewGCM(block)
+}
+
+// Write seals p as one chunk and writes it to the underlying writer.
+func (w *EncryptWriter) Write(p []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    size := w.aead.NonceSize() + len(p) + w.aead.Overhead()
+    w.buf = append(w.buf[:0], 0, 0, 0, 0)
+    binary.BigEndian.PutUint32(w.buf, uint32(size))
+    w.buf = append(w.buf, make([]byte, w.aead.NonceSize())...)
+    nonce := w.buf[4:]
+    if _, err := rand.Read(nonce); err != nil {
+        return 0, err
+    }
+    w.buf = w.aead.Seal(w.buf, nonce, p, nil)
+    if _, err := w.w.Write(w.buf); err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
+
+// Sync calls Flush or Sync of the underlying writer if it has one.
+func (w *EncryptWriter) Sync() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    switch u := w.w.(type) {
+    case interface{ Flush() error }:
+        return u.Flush()
+    case interface{ Sync() error }:
+        return u.Sync()
+    }
+    return nil
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (w *EncryptWriter) Close() error {
+    if c, ok := w.w.(io.Closer); ok {
+        return c.Close()
+    }
+    return nil
+}
+
+// DecryptReader reads the plaintext of the chunks written by EncryptWriter.
+type DecryptReader struct {
+    r     io.Reader
+    aead  cipher.AEAD
+    chunk []byte
+    plain []byte // unread plaintext of the current chunk
+}
+
+// NewDecryptReader creates a DecryptReader reading the chunks from r with
+// the key they were encrypted with.
+func NewDecryptReader(r io.Reader, key []byte) (*DecryptReader, error) {
+    aead, err := newGCM(key)
+    if err != nil {
+        return nil, err
+    }
+    return &DecryptReader{r: r, aead: aead}, nil
+}
+
+// Read reads decrypted data. It returns ErrCorruptChunk for a chunk which
+// fails authentication and io.ErrUnexpectedEOF for a truncated last chunk.
+func (r *DecryptReader) Read(p []byte) (int, error) {
+    for len(r.plain) == 0 {
+        if err := r.next(); err != nil {
+            return 0, err
+        }
+    }
+    n := copy(p, r.plain)
+    r.plain = r.plain[n:]
+    return n, nil
+}
+
+// next reads and opens the next chunk.
+func (r *DecryptReader) next() error {
+    var head [4]byte
+    if _, err := io.ReadFull(r.r, head[:]); err != nil {
+        return err
+    }
+    size := int(binary.BigEndian.Uint32(head[:]))
+    ns := r.aead.NonceSize()
+    if size < ns + r.aead.Overhead() || size > maxChunk {
+        return ErrCorruptChunk
+    }
+    if cap(r.chunk) < size {
+        r.chunk = make([]byte, size)
+    }
+    r.chunk = r.chunk[:size]
+    if _, err := io.ReadFull(r.r, r.chunk); err != nil {
+        if err == io.EOF {
+            err = io.ErrUnexpectedEOF
+        }
+        return err
+    }
+    plain, err := r.aead.Open(r.chunk[ns:ns], r.chunk[:ns], r.chunk[ns:], nil)
+    if err != nil {
+        return ErrCorruptChunk
+    }
+    r.plain = plain
+    return nil
+}
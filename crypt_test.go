@@ -0,0 +1,86 @@
+package aralog
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEncryptWriter(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(w, "", 0)
+	logger.Info("first")
+	logger.Info("second")
+	if strings.Contains(buf.String(), "first") {
+		t.Fatal("plaintext written")
+	}
+
+	r, err := NewDecryptReader(bytes.NewReader(buf.Bytes()), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "first\nsecond\n" {
+		t.Errorf("got %q", got)
+	}
+
+	tampered := append([]byte(nil), buf.Bytes()...)
+	tampered[len(tampered)-1] ^= 1
+	r, _ = NewDecryptReader(bytes.NewReader(tampered), key)
+	if _, err := ioutil.ReadAll(r); err != ErrCorruptChunk {
+		t.Errorf("tampered: got %v", err)
+	}
+
+	r, _ = NewDecryptReader(bytes.NewReader(buf.Bytes()[:buf.Len()-3]), key)
+	if _, err := ioutil.ReadAll(r); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated: got %v", err)
+	}
+
+	if _, err := NewEncryptWriter(&buf, key[:10]); err == nil {
+		t.Error("short key accepted")
+	}
+}
+
+func TestOpenEncryptFileTornChunk(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	f, err := ioutil.TempFile("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	w, err := OpenEncryptFile(f.Name(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("one\n"))
+	w.Write([]byte("two\n"))
+	w.Close()
+	fi, _ := os.Stat(f.Name())
+	os.Truncate(f.Name(), fi.Size()-5)
+
+	if w, err = OpenEncryptFile(f.Name(), key); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("three\n"))
+	w.Close()
+
+	b, _ := ioutil.ReadFile(f.Name())
+	r, _ := NewDecryptReader(bytes.NewReader(b), key)
+	got, err := ioutil.ReadAll(r)
+	if err != nil || string(got) != "one\nthree\n" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,115 @@
+package aralog
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "sync"
+    "sync/atomic"
+)
+
+// SpanFromContext returns the span active on a context, for the
+// context-aware logging methods, ex: DebugContext. By default it returns
+// the TraceContext stored by WithTrace; building with the aralog_otel tag
+// replaces it by the span of OpenTelemetry.
+var SpanFromContext = TraceFromContext
+
+// A ContextExtractor returns fields from values of a context, ex: the
+// tenant or the user of a request, nil if the context has none.
+type ContextExtractor func(ctx context.Context) []Field
+
+var (
+    extractorsMu sync.Mutex   // serializes RegisterContextExtractor
+    extractors   atomic.Value // []ContextExtractor, copied on write
+)
+
+// RegisterContextExtractor adds fn to the extractors called by the
+// context-aware logging methods of all loggers, ex: DebugContext, in order
+// of registration. It is meant to be called at initialization:
+//
+//	aralog.RegisterContextExtractor(func(ctx context.Context) []aralog.Field {
+//	    if t, ok := ctx.Value(tenantKey).(string); ok {
+//	        return []aralog.Field{aralog.F("tenant", t)}
+//	    }
+//	    return nil
+//	})
+func RegisterContextExtractor(fn ContextExtractor) {
+    extractorsMu.Lock()
+    defer extractorsMu.Unlock()
+    old, _ := extractors.Load().([]ContextExtractor)
+    extractors.Store(append(old[:len(old):len(old)], fn))
+}
+
+// contextFields returns the fields logged for ctx: trace_id and span_id if
+// a span is active, then the fields of the extractors.
+func contextFields(ctx context.Context) []Field {
+    if ctx == nil {
+        return nil
+    }
+    var fields []Field
+    if t, ok := SpanFromContext(ctx); ok {
+        fields = t.Fields()
+    }
+    fns, _ := extractors.Load().([]ContextExtractor)
+    for _, fn := range fns {
+        fields = append(fields, fn(ctx)...)
+    }
+    return fields
+}
+
+// mergeFields returns fields followed by the extra fields whose key is not
+// in fields.
+func mergeFields(fields, extra []Field) []Field {
+    merged := append([]Field(nil), fields...)
+next:
+    for _, x := range extra {
+        for _, f := range fields {
+            if f.Key == x.Key {
+                continue next
+            }
+        }
+        merged = append(merged, x)
+    }
+    return merged
+}
+
+// DebugContext logs at LevelDebug like Debug, with the fields of ctx: the
+// trace_id and span_id of the active span, see SpanFromContext, and those
+// of the extractors, see RegisterContextExtractor.
+func (l *Logger) DebugContext(ctx context.Context, s string, v ...interface{}) error {
+    if !l.mayLog(LevelDebug) {
+        return nil
+    }
+    return l.outputFields(2, LevelDebug, fmt.Sprintf(s, v...), contextFields(ctx))
+}
+
+// InfoContext logs at LevelInfo with the fields of ctx, see DebugContext.
+func (l *Logger) InfoContext(ctx context.Context, s string, v ...interface{}) error {
+    if !l.mayLog(LevelInfo) {
+        return nil
+    }
+    return l.outputFields(2, LevelInfo, fmt.Sprintf(s, v...), contextFields(ctx))
+}
+
+// WarnContext logs at LevelWarn with the fields of ctx, see DebugContext.
+func (l *Logger) WarnContext(ctx context.Context, s string, v ...interface{}) error {
+    if !l.mayLog(LevelWarn) {
+        return nil
+    }
+    return l.outputFields(2, LevelWarn, fmt.Sprintf(s, v...), contextFields(ctx))
+}
+
+// ErrorContext logs at LevelError with the fields of ctx, see DebugContext.
+func (l *Logger) ErrorContext(ctx context.Context, s string, v ...interface{}) error {
+    if !l.mayLog(LevelError) {
+        return nil
+    }
+    return l.outputFields(2, LevelError, fmt.Sprintf(s, v...), contextFields(ctx))
+}
+
+// FatalContext logs at LevelFatal with the fields of ctx, see DebugContext,
+// and then calls os.Exit(1).
+func (l *Logger) FatalContext(ctx context.Context, s string, v ...interface{}) {
+    l.outputFields(2, LevelFatal, fmt.Sprintf(s, v...), contextFields(ctx))
+    os.Exit(1)
+}
@@ -0,0 +1,46 @@
+package aralog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestInfoContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", Lshortfile)
+	span := TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"}
+	ctx := WithTrace(context.Background(), span)
+
+	logger.InfoContext(ctx, "traced")
+	logger.With(span.Fields()...).InfoContext(ctx, "bound")
+	logger.InfoContext(context.Background(), "untraced")
+
+	want := "ctxlog_test.go:15: traced trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7\n" +
+		"ctxlog_test.go:16: bound trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7\n" +
+		"ctxlog_test.go:17: untraced\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s", buf.String())
+	}
+}
+
+type tenantKey struct{}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	old, _ := extractors.Load().([]ContextExtractor)
+	defer extractors.Store(old)
+	RegisterContextExtractor(func(ctx context.Context) []Field {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []Field{F("tenant", tenant)}
+		}
+		return nil
+	})
+
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	logger.WarnContext(context.WithValue(context.Background(), tenantKey{}, "acme"), "quota exceeded")
+	logger.WarnContext(context.Background(), "no tenant")
+	if buf.String() != "quota exceeded tenant=acme\nno tenant\n" {
+		t.Errorf("got %q", buf.String())
+	}
+}
@@ -0,0 +1,230 @@
+package aralog
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "sync"
+    "time"
+)
+
+// DeadLetterSink keeps the entries a network sink fails to deliver: those
+// for which it returns an error, after its own retries, are appended to a
+// local dead-letter file, one JSON record per line, instead of being lost.
+// The file is delivered again later by ReplayDeadLetters.
+type DeadLetterSink struct {
+    mu   sync.Mutex
+    sink Sink
+    path string
+    file *os.File // opened on the first failure, reopened if replay removed it
+    err  error    // last error of the dead-letter file
+}
+
+// NewDeadLetterSink creates a DeadLetterSink in front of sink, keeping the
+// failed entries in the file at path.
+func NewDeadLetterSink(sink Sink, path string) *DeadLetterSink {
+    return &DeadLetterSink{sink: sink, path: path}
+}
+
+// entryRecord is the replayable form of an Entry.
+type entryRecord struct {
+    Time      time.Time `json:"time"`
+    Level     string    `json:"level"`
+    Logger    string    `json:"logger,omitempty"`
+    Message   string    `json:"msg"`
+    File      string    `json:"file,omitempty"`
+    Line      int       `json:"line,omitempty"`
+    Func      string    `json:"func,omitempty"`
+    Seq       uint64    `json:"seq,omitempty"`
+    Fields    []Field   `json:"fields,omitempty"`
+    Formatted string    `json:"formatted"`
+}
+
+// marshalEntry encodes e as one JSON line.
+func marshalEntry(e Entry) ([]byte, error) {
+    r := entryRecord{
+        Time:      e.Time,
+        Level:     e.Level.String(),
+        Logger:    e.Logger,
+        Message:   e.Message,
+        File:      e.File,
+        Line:      e.Line,
+        Func:      e.Func,
+        Seq:       e.Seq,
+        Formatted: string(e.Formatted),
+    }
+    for _, f := range e.Fields {
+        if err, ok := f.Value.(error); ok {
+            f.Value = err.Error()
+        }
+        r.Fields = append(r.Fields, f)
+    }
+    b, err := json.Marshal(r)
+    if err != nil {
+        return nil, err
+    }
+    return append(b, '\n'), nil
+}
+
+// unmarshalEntry decodes a line written by marshalEntry.
+func unmarshalEntry(line []byte) (Entry, error) {
+    var r entryRecord
+    if err := json.Unmarshal(line, &r); err != nil {
+        return Entry{}, err
+    }
+    level, err := ParseLevel(r.Level)
+    if err != nil {
+        return Entry{}, err
+    }
+    return Entry{
+        Time:      r.Time,
+        Level:     level,
+        Logger:    r.Logger,
+        Message:   r.Message,
+        File:      r.File,
+        Line:      r.Line,
+        Func:      r.Func,
+        Seq:       r.Seq,
+        Fields:    r.Fields,
+        Formatted: []byte(r.Formatted),
+    }, nil
+}
+
+// Write writes e to the sink, or to the dead-letter file if it fails. The
+// failure is passed to the error handler; the error is only returned if
+// the entry could not be kept either.
+func (s *DeadLetterSink) Write(e Entry) error {
+    err := s.sink.Write(e)
+    if err == nil {
+        return nil
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.err = s.keep(e); s.err != nil {
+        return err
+    }
+    reportError(fmt.Errorf("aralog: %v, entry kept in %s", err, s.path))
+    return nil
+}
+
+func (s *DeadLetterSink) keep(e Entry) error {
+    if s.file != nil && !s.fileCurrent() {
+        s.file.Close()
+        s.file = nil
+    }
+    if s.file == nil {
+        f, err := os.OpenFile(s.path, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0600)
+        if err != nil {
+            return err
+        }
+        s.file = f
+    }
+    b, err := marshalEntry(e)
+    if err != nil {
+        return err
+    }
+    _, err = s.file.Write(b)
+    return err
+}
+
+// fileCurrent reports whether s.file is still the file at s.path, which
+// ReplayDeadLetters deletes once it delivered all its entries.
+func (s *DeadLetterSink) fileCurrent() bool {
+    fi, err := s.file.Stat()
+    if err != nil {
+        return false
+    }
+    pi, err := os.Stat(s.path)
+    return err == nil && os.SameFile(fi, pi)
+}
+
+// Flush flushes the sink.
+func (s *DeadLetterSink) Flush() error {
+    return s.sink.Flush()
+}
+
+// Close closes the sink and the dead-letter file.
+func (s *DeadLetterSink) Close() error {
+    err := s.sink.Close()
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.file != nil {
+        if cerr := s.file.Close(); err == nil {
+            err = cerr
+        }
+        s.file = nil
+    }
+    return err
+}
+
+// Healthy reports whether the sink is healthy.
+func (s *DeadLetterSink) Healthy() bool {
+    return s.sink.Healthy()
+}
+
+// LastError returns the last error of the sink, or of the dead-letter file
+// if the sink has none.
+func (s *DeadLetterSink) LastError() error {
+    if err := SinkLastError(s.sink); err != nil {
+        return err
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err
+}
+
+// Describe describes the dead-letter file and the sink.
+func (s *DeadLetterSink) Describe() SinkDescription {
+    return SinkDescription{
+        Type:     "deadletter",
+        Healthy:  s.Healthy(),
+        Settings: map[string]interface{}{"path": s.path},
+        Sinks:    []SinkDescription{DescribeSink(s.sink)},
+    }
+}
+
+// ReplayDeadLetters writes the entries of the dead-letter file at path to
+// sink, in order, and returns how many were delivered. The delivered entries
+// are removed from the file, which is deleted once empty; replay stops at
+// the first failure, leaving the rest in the file. Lines which do not decode,
+// ex: cut by a crash, are skipped and reported to the error handler. The
+// file must not be written to meanwhile, a DeadLetterSink creates it again
+// for the next failure.
+func ReplayDeadLetters(path string, sink Sink) (int, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return 0, err
+    }
+    defer f.Close()
+
+    n, skipped := 0, 0
+    defer func() {
+        if skipped > 0 {
+            internalError("%s: skipped %d corrupt dead letters", path, skipped)
+        }
+    }()
+    r := bufio.NewReader(f)
+    for {
+        line, rerr := r.ReadBytes('\n')
+        if len(line) == 0 && rerr != nil {
+            break
+        }
+        e, err := unmarshalEntry(line)
+        if err != nil {
+            skipped++
+            continue
+        }
+        if err = sink.Write(e); err != nil {
+            rest, _ := ioutil.ReadAll(r)
+            if werr := ioutil.WriteFile(path, append(line, rest...), 0600); werr != nil {
+                return n, werr
+            }
+            return n, err
+        }
+        n++
+    }
+    return n, os.Remove(path)
+}
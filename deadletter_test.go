@@ -0,0 +1,98 @@
+package aralog
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeadLetterSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "dead.jsonl")
+
+	remote := &failingSink{}
+	sink := NewDeadLetterSink(remote, path)
+	logger := NewSinkLogger(sink, "", 0)
+	if err := logger.With(F("order", 7), F("cause", errors.New("timeout"))).Error("first"); err != nil {
+		t.Fatalf("kept entry reported as failed: %v", err)
+	}
+	logger.Info("second")
+	sink.Close()
+
+	ring := NewRingSink(10)
+	n, err := ReplayDeadLetters(path, ring)
+	if err != nil || n != 2 {
+		t.Fatalf("replayed %d entries: %v", n, err)
+	}
+	entries := ring.Entries()
+	e := entries[0]
+	if e.Message != "first" || e.Level != LevelError || string(e.Formatted) != "first order=7 cause=timeout\n" ||
+		len(e.Fields) != 2 || e.Fields[1].Value != "timeout" || time.Since(e.Time) > time.Minute {
+		t.Errorf("unexpected replayed entry %+v", e)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("dead-letter file not removed after replay")
+	}
+}
+
+func TestReplayDeadLettersFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "dead.jsonl")
+
+	sink := NewDeadLetterSink(&failingSink{}, path)
+	logger := NewSinkLogger(sink, "", 0)
+	logger.Info("one")
+	logger.Info("two")
+	sink.Close()
+
+	n, err := ReplayDeadLetters(path, &failingSink{})
+	if err == nil || n != 0 {
+		t.Fatalf("replay to a failing sink: %d, %v", n, err)
+	}
+	ring := NewRingSink(10)
+	if n, err = ReplayDeadLetters(path, ring); err != nil || n != 2 {
+		t.Fatalf("entries lost by a failed replay: %d, %v", n, err)
+	}
+}
+
+func TestDeadLetterSinkAfterReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "dead.jsonl")
+
+	sink := NewDeadLetterSink(&failingSink{}, path)
+	defer sink.Close()
+	logger := NewSinkLogger(sink, "", 0)
+	logger.Info("before")
+	if n, err := ReplayDeadLetters(path, NewRingSink(10)); err != nil || n != 1 {
+		t.Fatalf("replayed %d entries: %v", n, err)
+	}
+
+	logger.Info("after")
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString("{\"msg\": \"torn\n")
+	f.Close()
+	logger.Info("last")
+
+	ring := NewRingSink(10)
+	if n, err := ReplayDeadLetters(path, ring); err != nil || n != 2 {
+		t.Fatalf("replayed %d entries: %v", n, err)
+	}
+	if entries := ring.Entries(); entries[0].Message != "after" || entries[1].Message != "last" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,314 @@
+package aralog
+
+import (
+    "fmt"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// Delivery is the guarantee a network sink gives for the entries written
+// to it, see NewDeliverySink.
+type Delivery int
+
+const (
+    // BestEffort tries every entry once, in the background from a memory
+    // queue of limited size. Entries the sink fails to deliver, or which
+    // find the queue full, are counted as dropped and reported to the error
+    // handler; logging never waits for a down collector, except for fatal
+    // entries which are written at once, before the process exits. For
+    // metrics-like, high volume logs.
+    BestEffort Delivery = iota
+
+    // AtLeastOnce keeps every entry until the sink acknowledges it: failed
+    // entries are retried by the retry policy, then spooled to disk and
+    // replayed in order, see SpoolSink. An entry is only lost with the
+    // disk. It may be delivered twice if its acknowledgement is lost, so
+    // the collector should tolerate duplicates. Only sinks which
+    // acknowledge entries qualify, see Acknowledger.
+    AtLeastOnce
+
+    // BoundedLoss retries failed entries from a memory queue of limited
+    // size, on the following writes and on Flush. When the queue is full the
+    // oldest entry is dropped and counted, so memory stays bounded and the
+    // most recent entries win. Queued entries are lost if the process dies.
+    BoundedLoss
+)
+
+var deliveryNames = []string{"best-effort", "at-least-once", "bounded-loss"}
+
+func (d Delivery) String() string {
+    if d >= 0 && int(d) < len(deliveryNames) {
+        return deliveryNames[d]
+    }
+    return fmt.Sprintf("Delivery(%d)", int(d))
+}
+
+// ParseDelivery parses a delivery name: best-effort, at-least-once or
+// bounded-loss.
+func ParseDelivery(s string) (Delivery, error) {
+    for i, n := range deliveryNames {
+        if strings.EqualFold(s, n) {
+            return Delivery(i), nil
+        }
+    }
+    return BestEffort, fmt.Errorf("aralog: unknown delivery %q", s)
+}
+
+// An Acknowledger is a Sink which reports whether a nil error from Write
+// means the entry was received, ex: a 2xx response to an HTTP post, as
+// opposed to a UDP datagram sent into the void.
+type Acknowledger interface {
+    Acknowledges() bool
+}
+
+// Acknowledges reports false, GELF over UDP has no acknowledgements.
+func (w *GELFSink) Acknowledges() bool { return false }
+
+// Acknowledges reports true, entries are acknowledged by the response.
+func (s *WebhookSink) Acknowledges() bool { return true }
+
+// DeliveryOptions are the settings of NewDeliverySink.
+type DeliveryOptions struct {
+    Retry      *RetryPolicy  // at-least-once: retries before spooling, DefaultRetryPolicy if nil
+    SpoolPath  string        // at-least-once: spool file
+    SpoolRetry time.Duration // at-least-once: interval of spool replays, 10s if 0
+    QueueSize  int           // best-effort: entries queued for writing, bounded-loss: entries kept for retries, 1000 if 0
+}
+
+// NewDeliverySink wraps sink to give it the delivery semantics d.
+func NewDeliverySink(sink Sink, d Delivery, opts DeliveryOptions) (Sink, error) {
+    size := opts.QueueSize
+    if size <= 0 {
+        size = 1000
+    }
+    switch d {
+    case BestEffort:
+        s := &bestEffortSink{Sink: sink, queue: make(chan bestEffortItem, size), done: make(chan struct{})}
+        go s.run()
+        return s, nil
+    case AtLeastOnce:
+        if a, ok := sink.(Acknowledger); !ok || !a.Acknowledges() {
+            return nil, fmt.Errorf("aralog: at-least-once delivery needs a sink acknowledging entries, %T does not", sink)
+        }
+        if opts.SpoolPath == "" {
+            return nil, fmt.Errorf("aralog: at-least-once delivery needs a spool path")
+        }
+        retry := opts.Retry
+        if retry == nil {
+            retry = &DefaultRetryPolicy
+        }
+        return NewSpoolSink(&retrySink{sink, retry}, opts.SpoolPath, opts.SpoolRetry)
+    case BoundedLoss:
+        return &boundedSink{sink: sink, size: size}, nil
+    }
+    return nil, fmt.Errorf("aralog: unknown delivery %v", d)
+}
+
+// bestEffortSink writes the entries to its sink in the background and
+// drops those it fails to deliver or can't queue.
+type bestEffortSink struct {
+    Sink
+    mu      sync.RWMutex // held for writing to close queue
+    closed  bool
+    queue   chan bestEffortItem
+    done    chan struct{} // closed once run returns
+    lastErr atomic.Value  // errorBox of the last write
+}
+
+// bestEffortItem is an entry to write, or a request to signal flushed once
+// the entries queued before it are written.
+type bestEffortItem struct {
+    e       Entry
+    flushed chan struct{}
+}
+
+func (s *bestEffortSink) Write(e Entry) error {
+    if e.Level >= LevelFatal {
+        s.write(e)
+        return nil
+    }
+    e.Formatted = append([]byte(nil), e.Formatted...)
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    if s.closed {
+        countDropped(e)
+        return nil
+    }
+    select {
+    case s.queue <- bestEffortItem{e: e}:
+    default:
+        countDropped(e)
+        reportError(fmt.Errorf("aralog: entry dropped: best-effort queue full"))
+    }
+    return nil
+}
+
+func (s *bestEffortSink) write(e Entry) {
+    err := s.Sink.Write(e)
+    if err != nil {
+        countDropped(e)
+        reportError(fmt.Errorf("aralog: entry dropped: %v", err))
+    }
+    s.lastErr.Store(errorBox{err})
+}
+
+func (s *bestEffortSink) run() {
+    defer close(s.done)
+    for item := range s.queue {
+        if item.flushed != nil {
+            close(item.flushed)
+            continue
+        }
+        s.write(item.e)
+    }
+}
+
+// Flush waits for the queued entries to be written and flushes the sink.
+func (s *bestEffortSink) Flush() error {
+    s.mu.RLock()
+    if !s.closed {
+        flushed := make(chan struct{})
+        s.queue <- bestEffortItem{flushed: flushed}
+        s.mu.RUnlock()
+        <-flushed
+    } else {
+        s.mu.RUnlock()
+    }
+    return s.Sink.Flush()
+}
+
+// Close writes the queued entries and closes the sink.
+func (s *bestEffortSink) Close() error {
+    s.mu.Lock()
+    if !s.closed {
+        s.closed = true
+        close(s.queue)
+    }
+    s.mu.Unlock()
+    <-s.done
+    return s.Sink.Close()
+}
+
+// QueueDepth returns the number of entries waiting to be written.
+func (s *bestEffortSink) QueueDepth() int {
+    return len(s.queue)
+}
+
+// LastError returns the error of the last write, or else the last error of
+// the sink.
+func (s *bestEffortSink) LastError() error {
+    if b, _ := s.lastErr.Load().(errorBox); b.err != nil {
+        return b.err
+    }
+    return SinkLastError(s.Sink)
+}
+
+// Describe describes the delivery and the sink.
+func (s *bestEffortSink) Describe() SinkDescription {
+    return SinkDescription{
+        Type:     "delivery",
+        Healthy:  s.Healthy(),
+        Settings: map[string]interface{}{"delivery": BestEffort.String(), "queueSize": cap(s.queue), "queued": s.QueueDepth()},
+        Sinks:    []SinkDescription{DescribeSink(s.Sink)},
+    }
+}
+
+// retrySink retries the failed writes of its sink.
+type retrySink struct {
+    Sink
+    retry *RetryPolicy
+}
+
+func (s *retrySink) Write(e Entry) error {
+    return s.retry.Do(func() error {
+        return s.Sink.Write(e)
+    })
+}
+
+// boundedSink queues the entries its sink fails to deliver, up to size.
+type boundedSink struct {
+    mu    sync.Mutex
+    sink  Sink
+    queue []Entry // oldest first, with Formatted copied
+    size  int
+}
+
+func (s *boundedSink) Write(e Entry) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if s.drain() == nil && s.sink.Write(e) == nil {
+        return nil
+    }
+    e.Formatted = append([]byte(nil), e.Formatted...)
+    if len(s.queue) == s.size {
+        countDropped(s.queue[0])
+        s.queue = s.queue[1:]
+    }
+    s.queue = append(s.queue, e)
+    return nil
+}
+
+// drain writes the queued entries until the sink fails. s.mu must be held.
+func (s *boundedSink) drain() error {
+    for len(s.queue) > 0 {
+        if err := s.sink.Write(s.queue[0]); err != nil {
+            return err
+        }
+        s.queue[0] = Entry{}
+        s.queue = s.queue[1:]
+    }
+    s.queue = nil
+    return nil
+}
+
+// Flush retries the queued entries and flushes the sink.
+func (s *boundedSink) Flush() error {
+    s.mu.Lock()
+    err := s.drain()
+    s.mu.Unlock()
+    if ferr := s.sink.Flush(); err == nil {
+        err = ferr
+    }
+    return err
+}
+
+// Close retries the queued entries and closes the sink. The entries still
+// queued are dropped.
+func (s *boundedSink) Close() error {
+    s.mu.Lock()
+    s.drain()
+    for _, e := range s.queue {
+        countDropped(e)
+    }
+    s.queue = nil
+    s.mu.Unlock()
+    return s.sink.Close()
+}
+
+func (s *boundedSink) Healthy() bool {
+    return s.QueueDepth() == 0 && s.sink.Healthy()
+}
+
+func (s *boundedSink) QueueDepth() int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return len(s.queue)
+}
+
+// LastError returns the error of the sink.
+func (s *boundedSink) LastError() error {
+    return SinkLastError(s.sink)
+}
+
+// Describe describes the delivery and the sink.
+func (s *boundedSink) Describe() SinkDescription {
+    return SinkDescription{
+        Type:     "delivery",
+        Healthy:  s.Healthy(),
+        Settings: map[string]interface{}{"delivery": BoundedLoss.String(), "queueSize": s.size, "queued": s.QueueDepth()},
+        Sinks:    []SinkDescription{DescribeSink(s.sink)},
+    }
+}
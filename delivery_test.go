@@ -0,0 +1,121 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBestEffortDelivery(t *testing.T) {
+	sink, err := NewDeliverySink(&failingSink{}, BestEffort, DeliveryOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewSinkLogger(sink, "", 0)
+	if err := logger.Info("lost"); err != nil {
+		t.Errorf("best effort returned %v", err)
+	}
+	sink.Flush()
+	if s := logger.Stats(); s.Dropped != 1 {
+		t.Errorf("dropped %d entries, want 1", s.Dropped)
+	}
+	if err := SinkLastError(sink); err == nil {
+		t.Error("write error not reported")
+	}
+	if d := DescribeSink(sink); d.Settings["delivery"] != "best-effort" || len(d.Sinks) != 1 {
+		t.Errorf("unexpected description %+v", d)
+	}
+	sink.Close()
+}
+
+func TestBestEffortDeliveryDoesNotWait(t *testing.T) {
+	remote := &blockingSink{release: make(chan struct{})}
+	sink, err := NewDeliverySink(remote, BestEffort, DeliveryOptions{QueueSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewSinkLogger(sink, "", 0)
+	for i := 0; i < 5; i++ {
+		logger.Info("hot loop")
+	}
+	close(remote.release)
+	sink.Close()
+	if s := logger.Stats(); s.Dropped < 3 {
+		t.Errorf("dropped %d entries, want at least 3", s.Dropped)
+	}
+}
+
+// blockingSink blocks its writes until release is closed.
+type blockingSink struct {
+	DiscardSink
+	release chan struct{}
+}
+
+func (s *blockingSink) Write(Entry) error {
+	<-s.release
+	return nil
+}
+
+func TestBoundedLossDelivery(t *testing.T) {
+	remote := &flakySink{RingSink: NewRingSink(10), down: true}
+	sink, err := NewDeliverySink(remote, BoundedLoss, DeliveryOptions{QueueSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewSinkLogger(sink, "", 0)
+	logger.Info("1")
+	logger.Info("2")
+	logger.Info("3")
+	if d := sink.(QueueSink).QueueDepth(); d != 2 {
+		t.Errorf("queue depth %d, want 2", d)
+	}
+	remote.setDown(false)
+	logger.Info("4")
+
+	var got []string
+	for _, e := range remote.Entries() {
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, ",") != "2,3,4" {
+		t.Errorf("delivered %v", got)
+	}
+	if s := logger.Stats(); s.Dropped != 1 {
+		t.Errorf("dropped %d entries, want 1", s.Dropped)
+	}
+}
+
+func TestAtLeastOnceDelivery(t *testing.T) {
+	if _, err := NewDeliverySink(&GELFSink{}, AtLeastOnce, DeliveryOptions{SpoolPath: "spool"}); err == nil {
+		t.Error("at-least-once accepted for a sink without acknowledgements")
+	}
+
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	webhook, _ := NewWebhookSink("http://127.0.0.1:1/", "")
+	webhook.MinLevel = LevelDebug
+	sink, err := NewDeliverySink(webhook, AtLeastOnce, DeliveryOptions{Retry: &RetryPolicy{MaxAttempts: 1}, SpoolPath: filepath.Join(dir, "spool")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	NewSinkLogger(sink, "", 0).Info("kept")
+	if !sink.(*SpoolSink).Spooled() {
+		t.Error("undelivered entry not spooled")
+	}
+}
+
+func TestParseDelivery(t *testing.T) {
+	for _, d := range []Delivery{BestEffort, AtLeastOnce, BoundedLoss} {
+		if got, err := ParseDelivery(d.String()); err != nil || got != d {
+			t.Errorf("ParseDelivery(%q) = %v, %v", d.String(), got, err)
+		}
+	}
+	if _, err := ParseDelivery("exactly-once"); err == nil {
+		t.Error("unknown delivery accepted")
+	}
+}
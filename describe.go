@@ -0,0 +1,128 @@
+package aralog
+
+import (
+    "fmt"
+    "sort"
+)
+
+// Description is the effective configuration of a Logger, as reported by
+// Logger.Describe. It marshals to JSON for diagnostics endpoints.
+type Description struct {
+    Name             string            `json:"name,omitempty"`
+    Level            string            `json:"level"`
+    PackageLevels    map[string]string `json:"packageLevels,omitempty"`
+    Flags            []string          `json:"flags"`
+    Prefix           string            `json:"prefix,omitempty"`
+    TimeFormat       string            `json:"timeFormat,omitempty"`
+    Location         string            `json:"location,omitempty"`
+    MaxMessageLength int               `json:"maxMessageLength,omitempty"`
+    Encoding         string            `json:"encoding"`
+    Include          string            `json:"include,omitempty"`
+    Exclude          string            `json:"exclude,omitempty"`
+    Filter           string            `json:"filter,omitempty"`
+    Fields           map[string]string `json:"fields,omitempty"`
+    Sink             SinkDescription   `json:"sink"`
+}
+
+// SinkDescription describes a Sink and the sinks it wraps.
+type SinkDescription struct {
+    Type     string                 `json:"type"` // ex: file, gelf, multi
+    Healthy  bool                   `json:"healthy"`
+    Settings map[string]interface{} `json:"settings,omitempty"`
+    Sinks    []SinkDescription      `json:"sinks,omitempty"` // wrapped sinks
+}
+
+// A SinkDescriber is a Sink which describes itself in Logger.Describe.
+// Other sinks are described by their Go type only.
+type SinkDescriber interface {
+    Describe() SinkDescription
+}
+
+// Describe returns the effective configuration of the logger.
+func (l *Logger) Describe() Description {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    d := Description{
+        Name:             l.name,
+        Level:            l.Level().String(),
+        Flags:            flagList(l.flag),
+        Prefix:           l.prefix,
+        TimeFormat:       l.layout,
+        MaxMessageLength: l.maxlen,
+        Encoding:         encodingName(l.enc),
+        Sink:             DescribeSink(l.sink),
+    }
+    if l.loc != nil {
+        d.Location = l.loc.String()
+    }
+    if len(l.pkgLevels) > 0 {
+        d.PackageLevels = map[string]string{}
+        for _, pl := range l.pkgLevels {
+            d.PackageLevels[pl.prefix] = pl.level.String()
+        }
+    }
+    if l.filter.Include != nil {
+        d.Include = l.filter.Include.String()
+    }
+    if l.filter.Exclude != nil {
+        d.Exclude = l.filter.Exclude.String()
+    }
+    if l.rule != nil {
+        d.Filter = l.rule.String()
+    }
+    if len(l.fields) > 0 {
+        d.Fields = map[string]string{}
+        for _, f := range maskFields(l.fields, l.fieldMasks) {
+            d.Fields[f.Key] = fmt.Sprint(f.Value)
+        }
+    }
+    return d
+}
+
+// DescribeSink describes s, by its Describe method if it is a SinkDescriber.
+func DescribeSink(s Sink) SinkDescription {
+    if s == nil {
+        return SinkDescription{Type: "none"}
+    }
+    if d, ok := s.(SinkDescriber); ok {
+        return d.Describe()
+    }
+    return SinkDescription{Type: fmt.Sprintf("%T", s), Healthy: s.Healthy()}
+}
+
+func describeSinks(sinks []Sink) []SinkDescription {
+    d := make([]SinkDescription, len(sinks))
+    for i, s := range sinks {
+        d[i] = DescribeSink(s)
+    }
+    return d
+}
+
+// flagList returns the configuration names of the flags set in flag.
+func flagList(flag int) []string {
+    names := []string{}
+    for name, f := range flagNames {
+        if f != LstdFlags && flag & f != 0 {
+            names = append(names, name)
+        }
+    }
+    sort.Slice(names, func(i, j int) bool {
+        return flagNames[names[i]] < flagNames[names[j]]
+    })
+    return names
+}
+
+func encodingName(enc Encoder) string {
+    switch enc.(type) {
+    case nil:
+        return "text"
+    case *ConsoleEncoder:
+        return "console"
+    case *JSONEncoder:
+        return "json"
+    case *LogfmtEncoder:
+        return "logfmt"
+    }
+    return fmt.Sprintf("%T", enc)
+}
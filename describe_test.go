@@ -0,0 +1,60 @@
+package aralog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file, err := NewRollFileSink(filepath.Join(dir, "app.log"), 2*1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := NewSamplingSink(NewMultiSink(file, NewFilterSink(NewRingSink(8), nil, regexp.MustCompile(`noisy`))), time.Second, 10, 5)
+	logger := NewSinkLogger(sink, "app: ", LstdFlags|Lshortfile).With(F("service", "api"), F("token", "s3cr3t"))
+	logger.SetRedactedFields(MaskRedact, "token")
+	logger.SetLevel(LevelWarn)
+	logger.SetEncoder(NewJSONEncoder())
+	defer logger.Close()
+
+	d := logger.Describe()
+	if d.Level != "WARN" || d.Encoding != "json" || strings.Join(d.Flags, ",") != "date,time,shortfile" {
+		t.Errorf("unexpected description %+v", d)
+	}
+	if d.Fields["service"] != "api" || d.Fields["token"] != "[REDACTED]" {
+		t.Errorf("unexpected fields %v", d.Fields)
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"type":"sampling"`, `"type":"multi"`, `"maxSize":2097152`, `"exclude":"noisy"`, `"type":"ring"`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%s missing from %s", want, b)
+		}
+	}
+}
+
+func TestDescribeEncodingNames(t *testing.T) {
+	for _, encoding := range []string{"text", "console", "json", "logfmt"} {
+		l, err := (&Config{Encoding: encoding, Outputs: []OutputConfig{{Type: "stdout"}}}).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := l.Describe().Encoding; got != encoding {
+			t.Errorf("encoding %s described as %s", encoding, got)
+		}
+	}
+}
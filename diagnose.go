@@ -0,0 +1,141 @@
+package aralog
+
+import (
+    "fmt"
+    "io/ioutil"
+    "net"
+    "os"
+    "path/filepath"
+    "time"
+)
+
+// DiagnosticReport is the result of Logger.Diagnose.
+type DiagnosticReport struct {
+    Time   time.Time         `json:"time"`
+    OK     bool              `json:"ok"` // all checks passed
+    Checks []DiagnosticCheck `json:"checks"`
+}
+
+// DiagnosticCheck is the result of one check of a sink.
+type DiagnosticCheck struct {
+    Sink  string `json:"sink"`            // ex: file /var/log/app.log
+    Check string `json:"check"`           // ex: write, rotate, permissions, probe
+    Error string `json:"error,omitempty"` // empty if the check passed
+}
+
+// A SinkDiagnoser is a Sink which checks itself for Logger.Diagnose. Other
+// sinks are only checked to be Healthy.
+type SinkDiagnoser interface {
+    Diagnose() []DiagnosticCheck
+}
+
+// Diagnose checks that every sink of the logger is able to deliver
+// entries, without writing to them: file sinks are checked by a test write
+// and a test rotation of a temporary file next to the log file, and remote
+// sinks by a probe of their server. It is meant for startup validation.
+func (l *Logger) Diagnose() DiagnosticReport {
+    r := DiagnosticReport{Time: time.Now(), OK: true}
+    var walk func(Sink)
+    walk = func(sink Sink) {
+        var checks []DiagnosticCheck
+        if d, ok := sink.(SinkDiagnoser); ok {
+            checks = d.Diagnose()
+        } else if _, ok := sink.(sinkWrapper); !ok {
+            c := DiagnosticCheck{Sink: fmt.Sprintf("%T", sink), Check: "healthy"}
+            if !sink.Healthy() {
+                c.Error = "sink is unhealthy"
+                if err := SinkLastError(sink); err != nil {
+                    c.Error = err.Error()
+                }
+            }
+            checks = []DiagnosticCheck{c}
+        }
+        for _, c := range checks {
+            r.OK = r.OK && len(c.Error) == 0
+        }
+        r.Checks = append(r.Checks, checks...)
+
+        if w, ok := sink.(sinkWrapper); ok {
+            for _, inner := range w.inner() {
+                walk(inner)
+            }
+        }
+    }
+    walk(l.Sink())
+    return r
+}
+
+// newCheck returns the result of a check failed with err, or passed if err is nil.
+func newCheck(sink, check string, err error) DiagnosticCheck {
+    c := DiagnosticCheck{Sink: sink, Check: check}
+    if err != nil {
+        c.Error = err.Error()
+    }
+    return c
+}
+
+// Diagnose checks that the active file is still at its path and writable,
+// and that files can be created and renamed in its directory for rotation.
+func (s *RollFileSink) Diagnose() []DiagnosticCheck {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    name := "file " + s.path
+    checks := []DiagnosticCheck{newCheck(name, "permissions", s.checkFile())}
+
+    var write, rotate error
+    tmp, err := ioutil.TempFile(filepath.Dir(s.path), ".aralog-diagnose-")
+    if err == nil {
+        _, write = tmp.Write([]byte("aralog diagnose\n"))
+        if cerr := tmp.Close(); write == nil {
+            write = cerr
+        }
+        rolled := tmp.Name() + time.Now().Format("20060102150405")
+        if rotate = os.Rename(tmp.Name(), rolled); rotate == nil {
+            os.Remove(rolled)
+        } else {
+            os.Remove(tmp.Name())
+        }
+    } else {
+        write, rotate = err, err
+    }
+    return append(checks, newCheck(name, "write", write), newCheck(name, "rotate", rotate))
+}
+
+// checkFile checks that the active file was not removed or replaced and
+// that it is writable. s.mu must be held.
+func (s *RollFileSink) checkFile() error {
+    if s.err != nil {
+        return s.err
+    }
+    open, err := s.file.Stat()
+    if err != nil {
+        return err
+    }
+    fi, err := os.Stat(s.path)
+    if err != nil {
+        return err
+    }
+    if !os.SameFile(open, fi) {
+        return fmt.Errorf("%s was replaced, entries go to a removed file", s.path)
+    }
+    if fi.Mode().Perm() & 0200 == 0 {
+        return fmt.Errorf("%s is not writable by its owner", s.path)
+    }
+    return nil
+}
+
+// Diagnose probes the Graylog server by dialing it again, which resolves
+// its address and checks that it can be routed to.
+func (w *GELFSink) Diagnose() []DiagnosticCheck {
+    addr := w.conn.RemoteAddr().String()
+    conn, err := net.DialTimeout("udp", addr, 5 * time.Second)
+    if err == nil {
+        conn.Close()
+    }
+    checks := []DiagnosticCheck{newCheck("gelf " + addr, "probe", err)}
+    if err = w.LastError(); err != nil {
+        checks = append(checks, newCheck("gelf " + addr, "send", err))
+    }
+    return checks
+}
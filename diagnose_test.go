@@ -0,0 +1,40 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	file, err := NewRollFileSink(path, 1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewSinkLogger(NewMultiSink(file, NewRingSink(4)), "", 0)
+	defer logger.Close()
+
+	r := logger.Diagnose()
+	if !r.OK || len(r.Checks) != 4 {
+		t.Fatalf("unexpected report %+v", r)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("diagnose left %d files", len(files))
+	}
+
+	// the file is removed by an external log rotation
+	os.Remove(path)
+	r = logger.Diagnose()
+	if r.OK || r.Checks[0].Check != "permissions" || !strings.Contains(r.Checks[0].Error, "no such file") {
+		t.Errorf("unexpected report %+v", r)
+	}
+}
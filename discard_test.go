@@ -0,0 +1,23 @@
+package aralog
+
+import (
+	"testing"
+)
+
+func TestDiscardSink(t *testing.T) {
+	d := NewDiscardSink()
+	logger := NewSinkLogger(d, "", 0)
+	logger.Debug("one")
+	logger.Error("two")
+	logger.Error("three")
+
+	if n, b := d.Count(LevelDebug); n != 1 || b != 4 {
+		t.Errorf("debug: got %d entries, %d bytes", n, b)
+	}
+	if n, b := d.Count(LevelError); n != 2 || b != 10 {
+		t.Errorf("error: got %d entries, %d bytes", n, b)
+	}
+	if n, _ := d.Count(LevelInfo); n != 0 {
+		t.Errorf("info: got %d entries", n)
+	}
+}
@@ -0,0 +1,35 @@
+package aralog
+
+import (
+    "sync/atomic"
+)
+
+// A LevelEnabler decides which entries are written, in place of the level
+// and the package levels of a logger. It lets an external system, ex: a
+// feature flag service, gate the verbosity of each logger by its name,
+// see Register, without polling. Enabled is called for every entry, with
+// the logger locked, so it must be fast and must not log to the logger.
+type LevelEnabler interface {
+    Enabled(level Level, logger string) bool
+}
+
+// LevelEnablerFunc adapts a function to a LevelEnabler.
+type LevelEnablerFunc func(level Level, logger string) bool
+
+// Enabled returns f(level, logger).
+func (f LevelEnablerFunc) Enabled(level Level, logger string) bool {
+    return f(level, logger)
+}
+
+// SetLevelEnabler sets the enabler deciding which entries the logger
+// writes, nil restores the decision by level.
+func (l *Logger) SetLevelEnabler(e LevelEnabler) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.enabler = e
+    var has int32
+    if e != nil {
+        has = 1
+    }
+    atomic.StoreInt32(&l.hasEnabler, has)
+}
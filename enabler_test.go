@@ -0,0 +1,35 @@
+package aralog
+
+import (
+	"testing"
+)
+
+func TestSetLevelEnabler(t *testing.T) {
+	ring := NewRingSink(10)
+	logger := NewSinkLogger(ring, "", 0)
+	logger.SetLevel(LevelError)
+	Register("test.flags", logger)
+	defer Unregister("test.flags")
+
+	debug := map[string]bool{"test.flags": true}
+	logger.SetLevelEnabler(LevelEnablerFunc(func(level Level, name string) bool {
+		return level >= LevelWarn || debug[name]
+	}))
+	logger.Debug("kept by the enabler")
+	if !logger.Enabled(LevelDebug) || len(ring.Entries()) != 1 {
+		t.Fatalf("debug entry not written")
+	}
+
+	debug["test.flags"] = false
+	logger.Info("dropped by the enabler")
+	logger.Warn("kept by the enabler")
+	if n := len(ring.Entries()); n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+
+	logger.SetLevelEnabler(nil)
+	logger.Warn("dropped by the level")
+	if n := len(ring.Entries()); n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+}
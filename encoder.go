@@ -0,0 +1,23 @@
+package aralog
+
+// An Encoder renders an entry, including the trailing newline, in place of
+// the Logger's flag controlled text format. The caller information of the
+// entry is only filled in if a caller flag is set on the Logger.
+type Encoder interface {
+    Encode(buf *[]byte, e Entry)
+}
+
+// Encoder returns the encoder of the logger, nil for the flag controlled text format.
+func (l *Logger) Encoder() Encoder {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.enc
+}
+
+// SetEncoder sets the encoder rendering the entries of the logger. nil
+// restores the flag controlled text format.
+func (l *Logger) SetEncoder(enc Encoder) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.enc = enc
+}
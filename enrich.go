@@ -0,0 +1,127 @@
+package aralog
+
+import (
+    "io/ioutil"
+    "os"
+    "regexp"
+    "runtime"
+    "runtime/debug"
+    "strings"
+)
+
+// An Enricher returns fields describing the environment of the process,
+// which are bound to a logger once at creation by WithEnrichers.
+type Enricher func() []Field
+
+// WithEnrichers creates a child Logger with the fields of all enrichers bound.
+func (l *Logger) WithEnrichers(enrichers ...Enricher) *Logger {
+    var fields []Field
+    for _, enrich := range enrichers {
+        fields = append(fields, enrich()...)
+    }
+    return l.With(fields...)
+}
+
+// BuildInfo is an Enricher attaching the main module version, the VCS
+// revision and time the binary was built from, and the Go version.
+func BuildInfo() []Field {
+    fields := []Field{F("go_version", runtime.Version())}
+
+    info, ok := debug.ReadBuildInfo()
+    if !ok {
+        return fields
+    }
+    if info.Main.Version != "" {
+        fields = append(fields, F("version", info.Main.Version))
+    }
+    for _, s := range info.Settings {
+        switch s.Key {
+        case "vcs.revision":
+            fields = append(fields, F("revision", s.Value))
+        case "vcs.time":
+            fields = append(fields, F("revision_time", s.Value))
+        case "vcs.modified":
+            fields = append(fields, F("dirty", s.Value))
+        }
+    }
+    return fields
+}
+
+// Env returns an Enricher attaching the named environment variables, ex:
+// Env("REGION", "DEPLOY_ENV"). Keys are the lower-cased variable names;
+// unset variables are skipped.
+func Env(names ...string) Enricher {
+    return func() []Field {
+        var fields []Field
+        for _, name := range names {
+            if v, ok := os.LookupEnv(name); ok {
+                fields = append(fields, F(strings.ToLower(name), v))
+            }
+        }
+        return fields
+    }
+}
+
+// namespaceFile is where Kubernetes mounts the namespace of the service account.
+var namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Kubernetes is an Enricher attaching the pod name, namespace and node name.
+// They are read from POD_NAME, POD_NAMESPACE and NODE_NAME, which should be
+// exposed through the Downward API; the pod name falls back to HOSTNAME and
+// the namespace to the service account mount. Nothing is attached outside of
+// Kubernetes.
+func Kubernetes() []Field {
+    if os.Getenv("KUBERNETES_SERVICE_HOST") == "" && os.Getenv("POD_NAME") == "" {
+        return nil
+    }
+
+    pod := os.Getenv("POD_NAME")
+    if pod == "" {
+        pod = os.Getenv("HOSTNAME")
+    }
+    namespace := os.Getenv("POD_NAMESPACE")
+    if namespace == "" {
+        if b, err := ioutil.ReadFile(namespaceFile); err == nil {
+            namespace = strings.TrimSpace(string(b))
+        }
+    }
+
+    var fields []Field
+    for _, f := range []Field{F("k8s.pod", pod), F("k8s.namespace", namespace), F("k8s.node", os.Getenv("NODE_NAME"))} {
+        if f.Value != "" {
+            fields = append(fields, f)
+        }
+    }
+    return fields
+}
+
+// files searched for the container id: cgroup v1 paths contain it, with
+// cgroup v2 it usually only shows up in the mount sources.
+var containerIDFiles = []string{"/proc/self/cgroup", "/proc/self/mountinfo"}
+
+// containerIDPattern matches the container id in the cgroup paths of Docker,
+// containerd and Kubernetes, or in the mount of the /etc files Docker
+// provides a container. Other 64 hex digit ids, ex: of overlay layers or of
+// the mounts of other containers seen from the host, are not matched.
+var containerIDPattern = regexp.MustCompile(`(?m)(?:/docker/|docker-|cri-containerd-|/kubepods\S*/)([0-9a-f]{64})(?:\.scope)?$` +
+    `|/containers/([0-9a-f]{64})/(?:hostname|hosts|resolv\.conf) /etc/(?:hostname|hosts|resolv\.conf) `)
+
+// ContainerID is an Enricher attaching the id of the container the process
+// runs in, as found in /proc/self/cgroup or /proc/self/mountinfo. Nothing is
+// attached outside of a container.
+func ContainerID() []Field {
+    for _, name := range containerIDFiles {
+        b, err := ioutil.ReadFile(name)
+        if err != nil {
+            continue
+        }
+        if m := containerIDPattern.FindSubmatch(b); m != nil {
+            id := m[1]
+            if id == nil {
+                id = m[2]
+            }
+            return []Field{F("container_id", string(id))}
+        }
+    }
+    return nil
+}
@@ -0,0 +1,85 @@
+package aralog
+
+import (
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// Environment variables honored by Config.ApplyEnv.
+const (
+    EnvLevel   = "ARALOG_LEVEL"   // level, ex: debug
+    EnvFormat  = "ARALOG_FORMAT"  // encoding: text, console or json
+    EnvFile    = "ARALOG_FILE"    // path of a rolling log file output
+    EnvMaxSize = "ARALOG_MAXSIZE" // roll size of the file, in bytes or with a K, M or G suffix
+)
+
+// ApplyEnv fills the settings of c from the ARALOG_* environment variables.
+// Settings already made in c take precedence, so code can pin what must not
+// be tuned from the environment: ARALOG_LEVEL and ARALOG_FORMAT are only used
+// if Level and Encoding are empty, ARALOG_FILE only if there are no Outputs.
+func (c *Config) ApplyEnv() error {
+    if v := os.Getenv(EnvLevel); v != "" && c.Level == "" {
+        c.Level = v
+    }
+    if v := os.Getenv(EnvFormat); v != "" && c.Encoding == "" {
+        c.Encoding = v
+    }
+    if v := os.Getenv(EnvFile); v != "" && len(c.Outputs) == 0 {
+        c.Outputs = []OutputConfig{{Type: "file", Path: v}}
+    }
+    if v := os.Getenv(EnvMaxSize); v != "" {
+        size, err := parseSize(v)
+        if err != nil {
+            return fmt.Errorf("aralog: %s: %v", EnvMaxSize, err)
+        }
+        for i := range c.Outputs {
+            if c.Outputs[i].Type == "file" && c.Outputs[i].MaxSize == 0 {
+                c.Outputs[i].MaxSize = size
+            }
+        }
+    }
+    return nil
+}
+
+// NewFromEnv creates a Logger from c, nil for an empty Config, with the
+// settings c leaves open taken from the environment, see Config.ApplyEnv.
+func NewFromEnv(c *Config) (*Logger, error) {
+    if c == nil {
+        c = &Config{}
+    } else {
+        copied := *c
+        copied.Outputs = append([]OutputConfig(nil), c.Outputs...)
+        c = &copied
+    }
+    if err := c.ApplyEnv(); err != nil {
+        return nil, err
+    }
+    return c.Build()
+}
+
+// parseSize parses a byte size, optionally with a K, M or G (or KB, MB, GB)
+// suffix in powers of 1024.
+func parseSize(s string) (uint, error) {
+    s = strings.ToUpper(strings.TrimSpace(s))
+    s = strings.TrimSuffix(s, "B")
+    mult := uint64(1)
+    switch {
+    case strings.HasSuffix(s, "K"):
+        mult = 1 << 10
+    case strings.HasSuffix(s, "M"):
+        mult = 1 << 20
+    case strings.HasSuffix(s, "G"):
+        mult = 1 << 30
+    }
+    if mult > 1 {
+        s = s[:len(s) - 1]
+    }
+
+    n, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+    if err != nil {
+        return 0, fmt.Errorf("invalid size %q", s)
+    }
+    return uint(n * mult), nil
+}
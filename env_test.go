@@ -0,0 +1,28 @@
+package aralog
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{EnvLevel: "error", EnvFormat: "json", EnvFile: "/var/log/app.log", EnvMaxSize: "20MB"}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	c := &Config{Level: "info"}
+	if err := c.ApplyEnv(); err != nil {
+		t.Fatal(err)
+	}
+	want := &Config{
+		Level:    "info",
+		Encoding: "json",
+		Outputs:  []OutputConfig{{Type: "file", Path: "/var/log/app.log", MaxSize: 20 << 20}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("expected %+v, got %+v", want, c)
+	}
+}
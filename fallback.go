@@ -0,0 +1,116 @@
+package aralog
+
+import (
+    "errors"
+    "sync"
+    "time"
+)
+
+// FallbackSink delivers each entry to the first healthy sink of an ordered
+// chain, ex: remote -> local file -> stderr. A sink which fails is skipped
+// until RetryInterval has passed, after which it is tried again, so the
+// chain fails back to the primary once it recovers.
+type FallbackSink struct {
+    mu            sync.Mutex
+    sinks         []Sink
+    downUntil     []time.Time
+    err           error // last error of a sink
+    RetryInterval time.Duration
+}
+
+// NewFallbackSink creates a FallbackSink over sinks, in order of preference.
+func NewFallbackSink(sinks ...Sink) *FallbackSink {
+    return &FallbackSink{
+        sinks:         sinks,
+        downUntil:     make([]time.Time, len(sinks)),
+        RetryInterval: 30 * time.Second,
+    }
+}
+
+// Write writes e to the first healthy sink.
+func (f *FallbackSink) Write(e Entry) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    now := time.Now()
+    lastErr := errors.New("aralog: no healthy sink in fallback chain")
+    for i, s := range f.sinks {
+        if now.Before(f.downUntil[i]) {
+            continue
+        }
+
+        err := s.Write(e)
+        if err == nil {
+            return nil
+        }
+        f.downUntil[i] = now.Add(f.RetryInterval)
+        f.err = err
+        lastErr = err
+    }
+    return lastErr
+}
+
+// Active returns the index of the sink entries currently go to, or -1 if
+// every sink in the chain is down.
+func (f *FallbackSink) Active() int {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    now := time.Now()
+    for i := range f.sinks {
+        if !now.Before(f.downUntil[i]) {
+            return i
+        }
+    }
+    return -1
+}
+
+// Flush flushes every sink of the chain and returns the first error.
+func (f *FallbackSink) Flush() error {
+    var err error
+    for _, s := range f.sinks {
+        if ferr := s.Flush(); err == nil {
+            err = ferr
+        }
+    }
+    return err
+}
+
+// Close closes every sink of the chain and returns the first error.
+func (f *FallbackSink) Close() error {
+    var err error
+    for _, s := range f.sinks {
+        if cerr := s.Close(); err == nil {
+            err = cerr
+        }
+    }
+    return err
+}
+
+// Healthy reports whether any sink of the chain is healthy.
+func (f *FallbackSink) Healthy() bool {
+    for _, s := range f.sinks {
+        if s.Healthy() {
+            return true
+        }
+    }
+    return false
+}
+
+// Describe describes the sinks in order of preference.
+func (f *FallbackSink) Describe() SinkDescription {
+    return SinkDescription{
+        Type:     "fallback",
+        Healthy:  f.Healthy(),
+        Settings: map[string]interface{}{"retryInterval": f.RetryInterval.String(), "active": f.Active()},
+        Sinks:    describeSinks(f.sinks),
+    }
+}
+
+// LastError returns the last error a sink returned, even if another sink
+// took over the entry.
+func (f *FallbackSink) LastError() error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.err
+}
@@ -0,0 +1,40 @@
+package aralog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+type failingWriter struct {
+	fail bool
+	buf  bytes.Buffer
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, errors.New("write failed")
+	}
+	return w.buf.Write(p)
+}
+
+func TestFallbackSink(t *testing.T) {
+	primary := &failingWriter{fail: true}
+	secondary := &failingWriter{}
+	f := NewFallbackSink(NewWriterSink(primary), NewWriterSink(secondary))
+	f.RetryInterval = 10 * time.Millisecond
+	logger := NewSinkLogger(f, "", 0)
+
+	logger.Debug("first")
+	if secondary.buf.String() != "first\n" || f.Active() != 1 {
+		t.Fatalf("expected fallback to secondary, got %q", secondary.buf.String())
+	}
+
+	primary.fail = false
+	time.Sleep(20 * time.Millisecond)
+	logger.Debug("second")
+	if primary.buf.String() != "second\n" || f.Active() != 0 {
+		t.Errorf("expected fail-back to primary, got %q", primary.buf.String())
+	}
+}
@@ -0,0 +1,57 @@
+package aralog
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// Field is a key/value pair attached to log entries.
+type Field struct {
+    Key   string
+    Value interface{}
+}
+
+// F creates a Field.
+func F(key string, value interface{}) Field {
+    return Field{Key: key, Value: value}
+}
+
+// With creates a child Logger which attaches fields to every entry, after the
+// fields already bound to l. The child shares the sink of l.
+func (l *Logger) With(fields ...Field) *Logger {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    c := l.clone()
+    c.fields = append(append([]Field(nil), l.fields...), fields...)
+    return c
+}
+
+// Fields returns the fields bound to the logger.
+func (l *Logger) Fields() []Field {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return append([]Field(nil), l.fields...)
+}
+
+// appendFields appends fields as " key=value" pairs, quoting values which
+// contain spaces, quotes, '=' or control characters. Quoting escapes the
+// control characters, so values can't forge extra entries.
+func appendFields(buf *[]byte, fields []Field) {
+    for _, f := range fields {
+        *buf = append(*buf, ' ')
+        *buf = append(*buf, f.Key...)
+        *buf = append(*buf, '=')
+        appendFieldValue(buf, f.Value)
+    }
+}
+
+func appendFieldValue(buf *[]byte, value interface{}) {
+    v := fmt.Sprint(value)
+    if v == "" || strings.ContainsAny(v, " \t\"=") || strings.IndexFunc(v, isUnsafeControl) >= 0 {
+        *buf = strconv.AppendQuote(*buf, v)
+    } else {
+        *buf = append(*buf, v...)
+    }
+}
@@ -0,0 +1,91 @@
+package aralog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	child := logger.With(F("user", "bob"), F("msg", "hello world"))
+	child.Debug("login\n")
+	logger.Debug("plain")
+
+	if buf.String() != "login user=bob msg=\"hello world\"\nplain\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, "", 0).WithEnrichers(BuildInfo).Debug("started")
+
+	if !strings.Contains(buf.String(), " go_version=go") {
+		t.Errorf("expected go_version field, got %q", buf.String())
+	}
+}
+
+func TestEnv(t *testing.T) {
+	os.Setenv("ARALOG_TEST_REGION", "eu-west-1")
+	defer os.Unsetenv("ARALOG_TEST_REGION")
+
+	var buf bytes.Buffer
+	New(&buf, "", 0).WithEnrichers(Env("ARALOG_TEST_REGION", "ARALOG_TEST_UNSET")).Debug("started")
+	if buf.String() != "started aralog_test_region=eu-west-1\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestKubernetes(t *testing.T) {
+	for k, v := range map[string]string{"POD_NAME": "api-7d9f", "POD_NAMESPACE": "prod", "NODE_NAME": ""} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	var buf bytes.Buffer
+	New(&buf, "", 0).WithEnrichers(Kubernetes).Debug("started")
+	if buf.String() != "started k8s.pod=api-7d9f k8s.namespace=prod\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestContainerID(t *testing.T) {
+	f, err := ioutil.TempFile("", "cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	id := strings.Repeat("0123456789abcdef", 4)
+	f.WriteString("0::/system.slice/docker-" + id + ".scope\n")
+	f.Close()
+
+	saved := containerIDFiles
+	containerIDFiles = []string{f.Name()}
+	defer func() { containerIDFiles = saved }()
+
+	fields := ContainerID()
+	if len(fields) != 1 || fields[0].Value != id {
+		t.Errorf("unexpected fields %v", fields)
+	}
+
+	// cgroup v2 mountinfo: the overlay layer comes first
+	layer := strings.Repeat("fedcba9876543210", 4)
+	mountinfo := "600 500 0:52 / / rw - overlay overlay rw,upperdir=/var/lib/docker/overlay2/" + layer + "/diff\n" +
+		"610 600 259:1 /var/lib/docker/containers/" + id + "/hostname /etc/hostname rw - ext4 /dev/root rw\n"
+	ioutil.WriteFile(f.Name(), []byte(mountinfo), 0600)
+	if fields = ContainerID(); len(fields) != 1 || fields[0].Value != id {
+		t.Errorf("unexpected fields from mountinfo %v", fields)
+	}
+
+	// the host sees the mounts of the containers
+	host := "700 25 0:60 / /var/lib/docker/containers/" + id + "/mounts/shm rw - tmpfs shm rw\n" +
+		"710 25 0:52 / /var/lib/docker/overlay2/" + layer + "/merged rw - overlay overlay rw\n"
+	ioutil.WriteFile(f.Name(), []byte(host), 0600)
+	if fields = ContainerID(); fields != nil {
+		t.Errorf("container id found on the host: %v", fields)
+	}
+}
@@ -0,0 +1,94 @@
+package aralog
+
+import (
+    "regexp"
+)
+
+// MessageFilter keeps or drops entries by their message. An entry passes
+// if it matches Include, or Include is nil, and does not match Exclude.
+type MessageFilter struct {
+    Include *regexp.Regexp // keep only the matching messages, nil keeps all
+    Exclude *regexp.Regexp // drop the matching messages, nil drops none
+}
+
+// Allow reports whether an entry with the message msg passes the filter.
+func (f MessageFilter) Allow(msg string) bool {
+    if f.Include != nil && !f.Include.MatchString(msg) {
+        return false
+    }
+    return f.Exclude == nil || !f.Exclude.MatchString(msg)
+}
+
+// SetMessageFilter filters the entries of the logger by message, before
+// any other work is done for them. nil patterns remove the filter, ex:
+//
+//  l.SetMessageFilter(nil, regexp.MustCompile(`^kafka: (heartbeat|fetch)`))
+func (l *Logger) SetMessageFilter(include, exclude *regexp.Regexp) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.filter = MessageFilter{Include: include, Exclude: exclude}
+}
+
+// FilterSink is a Sink writing only the entries which pass its filter and
+// match its Rule, so outputs of the same logger can receive different
+// entries.
+type FilterSink struct {
+    MessageFilter
+    Rule *Rule // nil matches all entries
+    sink Sink
+}
+
+// NewFilterSink creates a FilterSink in front of sink.
+func NewFilterSink(sink Sink, include, exclude *regexp.Regexp) *FilterSink {
+    return &FilterSink{MessageFilter: MessageFilter{Include: include, Exclude: exclude}, sink: sink}
+}
+
+// NewRuleSink creates a FilterSink in front of sink, writing the entries
+// which match rule.
+func NewRuleSink(sink Sink, rule *Rule) *FilterSink {
+    return &FilterSink{Rule: rule, sink: sink}
+}
+
+// Write writes e to the sink if it passes the filter and matches the rule.
+func (s *FilterSink) Write(e Entry) error {
+    if !s.Allow(e.Message) || s.Rule != nil && !s.Rule.eval(&e) {
+        return nil
+    }
+    return s.sink.Write(e)
+}
+
+// Flush flushes the underlying sink.
+func (s *FilterSink) Flush() error {
+    return s.sink.Flush()
+}
+
+// Close closes the underlying sink.
+func (s *FilterSink) Close() error {
+    return s.sink.Close()
+}
+
+// Healthy reports whether the underlying sink is healthy.
+func (s *FilterSink) Healthy() bool {
+    return s.sink.Healthy()
+}
+
+// Describe describes the filter and the underlying sink.
+func (s *FilterSink) Describe() SinkDescription {
+    d := SinkDescription{Type: "filter", Healthy: s.Healthy(), Settings: map[string]interface{}{}}
+    if s.Include != nil {
+        d.Settings["include"] = s.Include.String()
+    }
+    if s.Exclude != nil {
+        d.Settings["exclude"] = s.Exclude.String()
+    }
+    if s.Rule != nil {
+        d.Settings["filter"] = s.Rule.String()
+    }
+    d.Sinks = []SinkDescription{DescribeSink(s.sink)}
+    return d
+}
+
+// LastError returns the last error of the underlying sink.
+func (s *FilterSink) LastError() error {
+    return SinkLastError(s.sink)
+}
@@ -0,0 +1,32 @@
+package aralog
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestSetMessageFilter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	logger.SetMessageFilter(regexp.MustCompile(`^kafka`), regexp.MustCompile(`heartbeat`))
+
+	logger.Info("kafka: heartbeat")
+	logger.Info("kafka: rebalance")
+	logger.Info("http: request")
+	if buf.String() != "kafka: rebalance\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestFilterSink(t *testing.T) {
+	ring := NewRingSink(10)
+	logger := NewSinkLogger(NewFilterSink(ring, nil, regexp.MustCompile(`noisy`)), "", 0)
+	logger.Info("noisy library message")
+	logger.Info("useful message")
+
+	entries := ring.Entries()
+	if len(entries) != 1 || entries[0].Message != "useful message" {
+		t.Errorf("unexpected entries %v", entries)
+	}
+}
@@ -0,0 +1,194 @@
+package aralog
+
+import (
+    "bytes"
+    "compress/zlib"
+    "crypto/rand"
+    "encoding/json"
+    "errors"
+    "net"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    gelfChunkMagic0  = 0x1e
+    gelfChunkMagic1  = 0x0f
+    gelfChunkHeader  = 12  // magic(2) + message id(8) + seq number(1) + seq count(1)
+    gelfMaxChunks    = 128 // limited by the GELF specification
+    GELFChunkSizeWAN = 1420
+    GELFChunkSizeLAN = 8154
+)
+
+// GELFSink is a Sink which sends each entry as one GELF message to a
+// Graylog server over UDP. Messages larger than one datagram are chunked as
+// required by the GELF specification.
+type GELFSink struct {
+    mu        sync.Mutex
+    conn      net.Conn
+    host      string
+    err       error // last send error
+    Compress  bool         // zlib compress the payload before sending
+    ChunkSize int          // max datagram size, including the chunk header
+    Retry     *RetryPolicy // retry failed sends, nil means no retry
+}
+
+// NewGELFSink creates a GELFSink which sends to the Graylog UDP input at addr,
+// ex: "graylog.example.com:12201"
+func NewGELFSink(addr string) (*GELFSink, error) {
+    conn, err := net.Dial("udp", addr)
+    if err != nil {
+        return nil, err
+    }
+
+    return &GELFSink{conn: conn, host: hostname, ChunkSize: GELFChunkSizeWAN}, nil
+}
+
+type gelfMessage struct {
+    Version      string  `json:"version"`
+    Host         string  `json:"host"`
+    ShortMessage string  `json:"short_message"`
+    Timestamp    float64 `json:"timestamp"`
+    Level        int     `json:"level"`
+}
+
+// Write sends the message of e as the short_message of a GELF message.
+func (w *GELFSink) Write(e Entry) error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    w.err = w.send(e.Time, gelfLevel(e.Level), strings.TrimRight(e.Message, "\n"))
+    return w.err
+}
+
+// gelfLevel maps a Level to its syslog severity.
+func gelfLevel(level Level) int {
+    switch level {
+    case LevelDebug:
+        return 7
+    case LevelInfo:
+        return 6
+    case LevelWarn:
+        return 4
+    case LevelError:
+        return 3
+    case LevelFatal:
+        return 2
+    }
+    return 6
+}
+
+func (w *GELFSink) send(t time.Time, level int, msg string) error {
+    payload, err := json.Marshal(gelfMessage{
+        Version:      "1.1",
+        Host:         w.host,
+        ShortMessage: msg,
+        Timestamp:    float64(t.UnixNano()) / 1e9,
+        Level:        level,
+    })
+    if err != nil {
+        return err
+    }
+
+    if w.Compress {
+        var b bytes.Buffer
+        zw := zlib.NewWriter(&b)
+        zw.Write(payload)
+        if err = zw.Close(); err != nil {
+            return err
+        }
+        payload = b.Bytes()
+    }
+
+    if w.Retry == nil {
+        return w.sendPayload(payload)
+    }
+    return w.Retry.Do(func() error {
+        return w.sendPayload(payload)
+    })
+}
+
+func (w *GELFSink) sendPayload(payload []byte) error {
+    if len(payload) <= w.ChunkSize {
+        _, err := w.conn.Write(payload)
+        return err
+    }
+
+    return w.sendChunked(payload)
+}
+
+func (w *GELFSink) sendChunked(payload []byte) error {
+    size := w.ChunkSize - gelfChunkHeader
+    if size <= 0 {
+        return errors.New("aralog: GELF chunk size too small")
+    }
+
+    count := (len(payload) + size - 1) / size
+    if count > gelfMaxChunks {
+        return errors.New("aralog: GELF message too large, exceeds 128 chunks")
+    }
+
+    var id [8]byte
+    if _, err := rand.Read(id[:]); err != nil {
+        return err
+    }
+
+    chunk := make([]byte, 0, w.ChunkSize)
+    for i := 0; i < count; i++ {
+        end := (i + 1) * size
+        if end > len(payload) {
+            end = len(payload)
+        }
+
+        chunk = append(chunk[:0], gelfChunkMagic0, gelfChunkMagic1)
+        chunk = append(chunk, id[:]...)
+        chunk = append(chunk, byte(i), byte(count))
+        chunk = append(chunk, payload[i * size:end]...)
+        if _, err := w.conn.Write(chunk); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Flush does nothing, every message is sent immediately.
+func (w *GELFSink) Flush() error {
+    return nil
+}
+
+// Close closes the underlying UDP connection.
+func (w *GELFSink) Close() error {
+    return w.conn.Close()
+}
+
+// Healthy reports whether the last message was sent successfully.
+func (w *GELFSink) Healthy() bool {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    return w.err == nil
+}
+
+// Describe describes the sink with its address and sending options.
+func (w *GELFSink) Describe() SinkDescription {
+    d := SinkDescription{
+        Type:    "gelf",
+        Healthy: w.Healthy(),
+        Settings: map[string]interface{}{
+            "address":   w.conn.RemoteAddr().String(),
+            "compress":  w.Compress,
+            "chunkSize": w.ChunkSize,
+        },
+    }
+    if w.Retry != nil {
+        d.Settings["retryMaxAttempts"] = w.Retry.MaxAttempts
+    }
+    return d
+}
+
+// LastError returns the error of the last send, nil if it succeeded.
+func (w *GELFSink) LastError() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    return w.err
+}
@@ -0,0 +1,64 @@
+package aralog
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGELFSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := NewGELFSink(pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.ChunkSize = 64
+	w.Compress = true
+	long := strings.Repeat("gelf chunking ", 100)
+	if err = w.Write(Entry{Time: time.Now(), Level: LevelDebug, Message: long + "\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var payload []byte
+	buf := make([]byte, 128)
+	for {
+		pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf[0] != gelfChunkMagic0 || buf[1] != gelfChunkMagic1 {
+			t.Fatal("expected chunked message")
+		}
+		payload = append(payload, buf[gelfChunkHeader:n]...)
+		if buf[10] == buf[11]-1 {
+			break
+		}
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := ioutil.ReadAll(zr)
+
+	var msg gelfMessage
+	if err = json.Unmarshal(raw, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Version != "1.1" || msg.ShortMessage != long || msg.Level != 7 {
+		t.Errorf("unexpected GELF message: %+v", msg)
+	}
+}
@@ -0,0 +1,74 @@
+//go:build aralog_grpc
+// +build aralog_grpc
+
+// The gRPC interceptors are built with the aralog_grpc tag, so aralog does
+// not depend on google.golang.org/grpc unless they are used:
+//
+//	go build -tags aralog_grpc
+
+package aralog
+
+import (
+    "context"
+    "time"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/peer"
+    "google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns an interceptor logging every unary call
+// served, with its request and response if payloads is set.
+func UnaryServerInterceptor(l *Logger, payloads bool) grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+        start := time.Now()
+        resp, err := handler(ctx, req)
+        logRPC(l, "server", info.FullMethod, status.Code(err).String(), time.Since(start), peerAddr(ctx), err, payloads, req, resp)
+        return resp, err
+    }
+}
+
+// StreamServerInterceptor returns an interceptor logging every stream
+// served once it ends. Stream messages are never logged.
+func StreamServerInterceptor(l *Logger) grpc.StreamServerInterceptor {
+    return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        start := time.Now()
+        err := handler(srv, ss)
+        logRPC(l, "server", info.FullMethod, status.Code(err).String(), time.Since(start), peerAddr(ss.Context()), err, false, nil, nil)
+        return err
+    }
+}
+
+// UnaryClientInterceptor returns an interceptor logging every unary call
+// made, with its request and reply if payloads is set.
+func UnaryClientInterceptor(l *Logger, payloads bool) grpc.UnaryClientInterceptor {
+    return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+        start := time.Now()
+        err := invoker(ctx, method, req, reply, cc, opts...)
+        var resp interface{}
+        if err == nil {
+            resp = reply
+        }
+        logRPC(l, "client", method, status.Code(err).String(), time.Since(start), cc.Target(), err, payloads, req, resp)
+        return err
+    }
+}
+
+// StreamClientInterceptor returns an interceptor logging the opening of
+// every stream. Stream messages are never logged.
+func StreamClientInterceptor(l *Logger) grpc.StreamClientInterceptor {
+    return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+        start := time.Now()
+        cs, err := streamer(ctx, desc, cc, method, opts...)
+        logRPC(l, "client", method, status.Code(err).String(), time.Since(start), cc.Target(), err, false, nil, nil)
+        return cs, err
+    }
+}
+
+// peerAddr returns the address of the client of a served call.
+func peerAddr(ctx context.Context) string {
+    if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+        return p.Addr.String()
+    }
+    return ""
+}
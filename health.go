@@ -0,0 +1,33 @@
+package aralog
+
+// An ErrorReporter is a Sink which reports the error that made it unhealthy.
+type ErrorReporter interface {
+    LastError() error
+}
+
+// SinkLastError returns the last error of s if it is an ErrorReporter, nil
+// otherwise.
+func SinkLastError(s Sink) error {
+    if r, ok := s.(ErrorReporter); ok {
+        return r.LastError()
+    }
+    return nil
+}
+
+type errorBox struct {
+    err error
+}
+
+// Healthy reports whether the sink of the logger is able to deliver entries,
+// ex: for a readiness probe.
+func (l *Logger) Healthy() bool {
+    return l.Sink().Healthy()
+}
+
+// LastError returns the last error the sink of the logger, or of a logger
+// derived from it by With, returned for an entry; nil if there was none.
+// It is kept after the sink recovers, see Healthy for the current state.
+func (l *Logger) LastError() error {
+    b, _ := l.stats.lastErr.Load().(errorBox)
+    return b.err
+}
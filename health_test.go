@@ -0,0 +1,45 @@
+package aralog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthy(t *testing.T) {
+	var w errWriter
+	logger := New(&w, "", 0)
+	Register("test.health", logger)
+	defer Unregister("test.health")
+
+	w.err = errors.New("disk full")
+	logger.Info("lost")
+	if logger.Healthy() || logger.LastError() != w.err || SinkLastError(logger.Sink()) != w.err {
+		t.Fatalf("expected unhealthy logger with the write error")
+	}
+
+	rec := httptest.NewRecorder()
+	HealthHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), `"test.health":{"healthy":false,"error":"disk full"}`) {
+		t.Errorf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	w.err = nil
+	logger.Info("written")
+	if !logger.Healthy() || logger.LastError() == nil {
+		t.Errorf("expected healthy logger keeping its last error")
+	}
+}
+
+type errWriter struct {
+	err error
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,83 @@
+package aralog
+
+import (
+    "fmt"
+)
+
+// A Hook is called with every entry of a logger before it is encoded and
+// written. It may change the message and level, and add fields by replacing
+// e.Fields with a new slice; the slice given must not be modified. Errors
+// are passed to the error handler, see SetErrorHandler, the entry is still
+// written. Hooks run with the logger locked and must not log to it.
+type Hook interface {
+    Fire(e *Entry) error
+}
+
+// HookFunc adapts a function to a Hook.
+type HookFunc func(e *Entry) error
+
+// Fire calls f(e).
+func (f HookFunc) Fire(e *Entry) error {
+    return f(e)
+}
+
+// LevelHook is a Hook which is only called with entries at its levels,
+// so expensive hooks don't run on every debug entry.
+type LevelHook interface {
+    Hook
+    Levels() []Level
+}
+
+// OnLevels restricts h to the entries at levels, ex:
+//
+//	l.AddHook(aralog.OnLevels(notify, aralog.LevelError, aralog.LevelFatal))
+func OnLevels(h Hook, levels ...Level) LevelHook {
+    return levelHook{h, append([]Level(nil), levels...)}
+}
+
+type levelHook struct {
+    Hook
+    levels []Level
+}
+
+func (h levelHook) Levels() []Level {
+    return h.levels
+}
+
+// boundHook is a Hook with the bit set of the levels it is called for.
+type boundHook struct {
+    hook   Hook
+    levels uint
+}
+
+// AddHook adds h to the hooks of the logger, which are called in the order
+// they were added, for all levels unless h is a LevelHook. Loggers created
+// from l by With afterwards inherit them.
+func (l *Logger) AddHook(h Hook) {
+    b := boundHook{h, ^uint(0)}
+    if lh, ok := h.(LevelHook); ok {
+        b.levels = 0
+        for _, lv := range lh.Levels() {
+            if lv >= 0 && lv <= LevelFatal {
+                b.levels |= 1 << uint(lv)
+            }
+        }
+    }
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.hooks = append(l.hooks[:len(l.hooks):len(l.hooks)], b)
+}
+
+// fireHooks calls the hooks of the logger for the level of e with e. l.mu
+// must be held.
+func (l *Logger) fireHooks(e *Entry) {
+    for _, b := range l.hooks {
+        if b.levels & (1 << uint(e.Level)) == 0 {
+            continue
+        }
+        if err := b.hook.Fire(e); err != nil {
+            reportError(fmt.Errorf("aralog: hook %T: %v", b.hook, err))
+        }
+    }
+}
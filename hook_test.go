@@ -0,0 +1,71 @@
+package aralog
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+)
+
+func TestAddHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	var levels []Level
+	logger.AddHook(HookFunc(func(e *Entry) error {
+		levels = append(levels, e.Level)
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], F("hooked", true))
+		return nil
+	}))
+	var reported error
+	SetErrorHandler(func(err error) { reported = err })
+	defer SetErrorHandler(nil)
+	logger.AddHook(HookFunc(func(e *Entry) error {
+		e.Message = "<" + e.Message + ">"
+		return errors.New("unavailable")
+	}))
+
+	logger.With(F("a", 1)).Warn("first")
+	logger.Debug("second")
+
+	if want := "<first> a=1 hooked=true\n<second> hooked=true\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if len(levels) != 2 || levels[0] != LevelWarn {
+		t.Errorf("hook called with %v", levels)
+	}
+	if reported == nil {
+		t.Error("hook error not reported")
+	}
+}
+
+func TestOnLevels(t *testing.T) {
+	logger := New(ioutil.Discard, "", 0)
+	var fired []string
+	logger.AddHook(OnLevels(HookFunc(func(e *Entry) error {
+		fired = append(fired, e.Message)
+		return nil
+	}), LevelError, LevelFatal))
+
+	logger.Debug("debug")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	if len(fired) != 1 || fired[0] != "error" {
+		t.Errorf("hook fired for %v", fired)
+	}
+}
+
+func TestHookSeesRedactedFields(t *testing.T) {
+	logger := New(ioutil.Discard, "", 0)
+	logger.SetRedactedFields(MaskRedact, "password")
+	var fields []Field
+	logger.AddHook(HookFunc(func(e *Entry) error {
+		fields = append([]Field(nil), e.Fields...)
+		return nil
+	}))
+	logger.With(F("password", "hunter2")).Info("login")
+
+	if len(fields) != 1 || fields[0].Value != "[REDACTED]" {
+		t.Errorf("hook got fields %v", fields)
+	}
+}
@@ -0,0 +1,119 @@
+package aralog
+
+import (
+    "bufio"
+    "fmt"
+    "net"
+    "net/http"
+    "runtime/debug"
+    "time"
+)
+
+// HTTPMiddleware returns a middleware logging every request handled by
+// the wrapped handler to l, as "http request" with the fields method, path,
+// status, bytes, duration and remote. Server errors are logged at
+// LevelError, client errors at LevelWarn, other requests at LevelInfo.
+//
+// A panic of the handler is logged with its stack at LevelError and answered
+// with 500 if nothing was written yet, instead of crashing the connection.
+//
+//	http.ListenAndServe(":8080", aralog.HTTPMiddleware(logger)(mux))
+func HTTPMiddleware(l *Logger) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            start := time.Now()
+            rw := &responseRecorder{ResponseWriter: w}
+            defer func() {
+                fields := []Field{
+                    F("method", r.Method),
+                    F("path", r.URL.Path),
+                    F("status", 0),
+                    F("bytes", 0),
+                    F("duration", time.Since(start)),
+                    F("remote", r.RemoteAddr),
+                }
+                if p := recover(); p != nil {
+                    if p == http.ErrAbortHandler {
+                        panic(p)
+                    }
+                    if rw.status == 0 {
+                        http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+                    }
+                    fields = append(fields, F("panic", fmt.Sprint(p)), F("stack", string(debug.Stack())))
+                }
+                if rw.status == 0 {
+                    rw.status = http.StatusOK
+                }
+                fields[2].Value, fields[3].Value = rw.status, rw.bytes
+
+                log := l.With(fields...)
+                switch {
+                case rw.status >= 500:
+                    log.Error("http request")
+                case rw.status >= 400:
+                    log.Warn("http request")
+                default:
+                    log.Info("http request")
+                }
+            }()
+            next.ServeHTTP(rw, r)
+        })
+    }
+}
+
+// responseRecorder records the status and the size of a response.
+type responseRecorder struct {
+    http.ResponseWriter
+    status int
+    bytes  int64
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+    if w.status == 0 {
+        w.status = status
+    }
+    w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+    if w.status == 0 {
+        w.status = http.StatusOK
+    }
+    n, err := w.ResponseWriter.Write(b)
+    w.bytes += int64(n)
+    return n, err
+}
+
+// Flush flushes the response if the underlying writer supports it.
+func (w *responseRecorder) Flush() {
+    if f, ok := w.ResponseWriter.(http.Flusher); ok {
+        f.Flush()
+    }
+}
+
+// Hijack takes over the connection if the underlying writer supports it,
+// ex: for websockets. The response is logged as switching protocols.
+func (w *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    h, ok := w.ResponseWriter.(http.Hijacker)
+    if !ok {
+        return nil, nil, fmt.Errorf("aralog: %T does not support hijacking", w.ResponseWriter)
+    }
+    conn, rw, err := h.Hijack()
+    if err == nil && w.status == 0 {
+        w.status = http.StatusSwitchingProtocols
+    }
+    return conn, rw, err
+}
+
+// Push pushes target if the underlying writer supports HTTP/2 server push.
+func (w *responseRecorder) Push(target string, opts *http.PushOptions) error {
+    if p, ok := w.ResponseWriter.(http.Pusher); ok {
+        return p.Push(target, opts)
+    }
+    return http.ErrNotSupported
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+    return w.ResponseWriter
+}
@@ -0,0 +1,76 @@
+package aralog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	ring := NewRingSink(4)
+	logger := NewSinkLogger(ring, "", 0)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	handler := HTTPMiddleware(logger)(mux)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/hello", nil))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/panic", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 after panic, got %d", rec.Code)
+	}
+
+	entries := ring.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if line := string(entries[0].Formatted); entries[0].Level != LevelInfo ||
+		!strings.HasPrefix(line, "http request method=GET path=/hello status=200 bytes=5 duration=") {
+		t.Errorf("unexpected entry %q", line)
+	}
+	if line := string(entries[1].Formatted); entries[1].Level != LevelError ||
+		!strings.Contains(line, "status=500") || !strings.Contains(line, "panic=boom stack=") {
+		t.Errorf("unexpected entry %q", line)
+	}
+}
+
+func TestHTTPMiddlewareHijack(t *testing.T) {
+	ring := NewRingSink(4)
+	logger := NewSinkLogger(ring, "", 0)
+	handler := HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	}))
+	logged := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+		close(logged)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("got status %d", resp.StatusCode)
+	}
+	<-logged
+	if entries := ring.Entries(); len(entries) != 1 || !strings.Contains(string(entries[0].Formatted), "status=101") {
+		t.Errorf("unexpected entries %v", entries)
+	}
+}
@@ -0,0 +1,64 @@
+package aralog
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "strings"
+    "time"
+)
+
+// JSONEncoder is an Encoder writing each entry as one JSON object:
+// {"time":"2009-01-23T01:23:23.123456+08:00","level":"INFO","caller":"d.go:23","msg":"message"}
+// followed by the fields as additional keys.
+type JSONEncoder struct {
+    TimeLayout string // time.Format layout of "time", RFC3339 with microseconds by default
+}
+
+// NewJSONEncoder creates a JSONEncoder.
+func NewJSONEncoder() *JSONEncoder {
+    return &JSONEncoder{TimeLayout: rfc3339Micro}
+}
+
+func (j *JSONEncoder) Encode(buf *[]byte, e Entry) {
+    layout := j.TimeLayout
+    if len(layout) == 0 {
+        layout = time.RFC3339Nano
+    }
+
+    *buf = append(*buf, `{"time":`...)
+    appendJSON(buf, e.Time.Format(layout))
+    *buf = append(*buf, `,"level":`...)
+    appendJSON(buf, e.Level.String())
+    if len(e.File) > 0 {
+        *buf = append(*buf, `,"caller":`...)
+        appendJSON(buf, callerString(e))
+    }
+    *buf = append(*buf, `,"msg":`...)
+    appendJSON(buf, strings.TrimSuffix(e.Message, "\n"))
+    for _, f := range e.Fields {
+        *buf = append(*buf, ',')
+        appendJSON(buf, f.Key)
+        *buf = append(*buf, ':')
+        appendJSON(buf, f.Value)
+    }
+    *buf = append(*buf, "}\n"...)
+}
+
+// appendJSON appends v encoded as JSON. Errors are encoded as their message
+// and values which can't be encoded as their fmt.Sprint form.
+func appendJSON(buf *[]byte, v interface{}) {
+    if err, ok := v.(error); ok {
+        v = err.Error()
+    }
+
+    var b bytes.Buffer
+    enc := json.NewEncoder(&b)
+    enc.SetEscapeHTML(false)
+    if err := enc.Encode(v); err != nil {
+        reportError(fmt.Errorf("aralog: encoding %T as JSON: %v", v, err))
+        b.Reset()
+        enc.Encode(fmt.Sprint(v))
+    }
+    *buf = append(*buf, bytes.TrimSuffix(b.Bytes(), []byte("\n"))...)
+}
@@ -0,0 +1,89 @@
+package aralog
+
+import (
+    "math/bits"
+    "sync/atomic"
+    "time"
+)
+
+// latencyBuckets is the number of buckets of a latencyHistogram, enough
+// for writes up to 2^36µs, about 19 hours.
+const latencyBuckets = 4 + 35 * 4
+
+// latencyHistogram counts durations in microseconds in log-linear buckets:
+// each power of two is split in 4, so a bucket bound is at most 25% above
+// the durations it holds. It is updated atomically.
+type latencyHistogram struct {
+    counts [latencyBuckets]uint64
+    sum    uint64 // of the durations in nanoseconds
+}
+
+func latencyBucket(d time.Duration) int {
+    v := uint64(d / time.Microsecond)
+    if d < 0 {
+        v = 0
+    }
+    if v < 4 {
+        return int(v)
+    }
+    e := bits.Len64(v) - 1
+    b := 4 + (e - 2) * 4 + int(v >> uint(e - 2) & 3)
+    if b >= latencyBuckets {
+        b = latencyBuckets - 1
+    }
+    return b
+}
+
+// latencyBound returns the exclusive upper bound of bucket b.
+func latencyBound(b int) time.Duration {
+    if b < 4 {
+        return time.Duration(b + 1) * time.Microsecond
+    }
+    e, sub := uint((b - 4) / 4 + 2), uint64((b - 4) % 4)
+    return time.Duration((5 + sub) << (e - 2)) * time.Microsecond
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+    atomic.AddUint64(&h.counts[latencyBucket(d)], 1)
+    atomic.AddUint64(&h.sum, uint64(d))
+}
+
+// Latency summarizes the durations of the sink writes of a Logger. The
+// percentiles are upper bounds, at most 25% above the exact value.
+type Latency struct {
+    Count uint64
+    Sum   time.Duration // of all the durations
+    P50   time.Duration
+    P95   time.Duration
+    P99   time.Duration
+}
+
+// summary computes the percentiles of the durations counted so far.
+func (h *latencyHistogram) summary() Latency {
+    var counts [latencyBuckets]uint64
+    var l Latency
+    for i := range counts {
+        counts[i] = atomic.LoadUint64(&h.counts[i])
+        l.Count += counts[i]
+    }
+    l.Sum = time.Duration(atomic.LoadUint64(&h.sum))
+    if l.Count == 0 {
+        return l
+    }
+
+    quantile := func(q float64) time.Duration {
+        rank := uint64(q * float64(l.Count) + 0.5)
+        if rank < 1 {
+            rank = 1
+        }
+        var n uint64
+        for b, c := range counts {
+            if n += c; n >= rank {
+                return latencyBound(b)
+            }
+        }
+        return latencyBound(latencyBuckets - 1)
+    }
+    l.P50, l.P95, l.P99 = quantile(0.50), quantile(0.95), quantile(0.99)
+    return l
+}
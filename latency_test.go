@@ -0,0 +1,33 @@
+package aralog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyBuckets(t *testing.T) {
+	for us := int64(0); us < 1<<20; us += 7 {
+		d := time.Duration(us) * time.Microsecond
+		b := latencyBound(latencyBucket(d))
+		if b <= d || b > d+d/4+time.Microsecond {
+			t.Fatalf("%v: bound %v", d, b)
+		}
+	}
+}
+
+func TestLatencySummary(t *testing.T) {
+	var h latencyHistogram
+	for i := 0; i < 98; i++ {
+		h.observe(100 * time.Microsecond)
+	}
+	h.observe(10 * time.Millisecond)
+	h.observe(time.Second)
+
+	l := h.summary()
+	if l.Count != 100 || l.Sum != 98*100*time.Microsecond+10*time.Millisecond+time.Second || l.P50 != 112*time.Microsecond || l.P95 != 112*time.Microsecond {
+		t.Errorf("unexpected summary %+v", l)
+	}
+	if l.P99 < 10*time.Millisecond || l.P99 > 12500*time.Microsecond {
+		t.Errorf("unexpected p99 %v", l.P99)
+	}
+}
@@ -0,0 +1,96 @@
+package aralog
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "sync/atomic"
+    "time"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+    LevelDebug Level = iota
+    LevelInfo
+    LevelWarn
+    LevelError
+    LevelFatal
+)
+
+var levelNames = []string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+func (lv Level) String() string {
+    if lv >= 0 && int(lv) < len(levelNames) {
+        return levelNames[lv]
+    }
+    return "Level(" + strconv.Itoa(int(lv)) + ")"
+}
+
+// ParseLevel parses a level name, case insensitive: debug, info, warn
+// (or warning), error or fatal.
+func ParseLevel(s string) (Level, error) {
+    name := strings.ToUpper(strings.TrimSpace(s))
+    if name == "WARNING" {
+        return LevelWarn, nil
+    }
+    for i, n := range levelNames {
+        if name == n {
+            return Level(i), nil
+        }
+    }
+    return LevelDebug, fmt.Errorf("aralog: unknown level %q", s)
+}
+
+// Level returns the minimal level of the entries the logger writes.
+func (l *Logger) Level() Level {
+    return Level(atomic.LoadInt32(&l.level))
+}
+
+// SetLevel sets the minimal level of the entries the logger writes, entries
+// below it are discarded. Fatal entries always exit the process.
+func (l *Logger) SetLevel(level Level) {
+    atomic.StoreInt32(&l.level, int32(level))
+}
+
+// Enabled reports whether the logger writes entries at level, as decided
+// by its LevelEnabler if it has one.
+func (l *Logger) Enabled(level Level) bool {
+    if atomic.LoadInt32(&l.hasEnabler) != 0 {
+        l.mu.Lock()
+        defer l.mu.Unlock()
+        if l.enabler != nil {
+            return l.enabler.Enabled(level, l.name)
+        }
+    }
+    return level >= l.Level()
+}
+
+// EnableDebugFor lowers the level of the logger to LevelDebug for d, then
+// restores the previous level. Calling it again during the window extends
+// it. The previous level is not restored if the level was changed by
+// SetLevel in the meantime.
+func (l *Logger) EnableDebugFor(d time.Duration) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if l.debugTimer != nil {
+        l.debugTimer.Stop()
+    } else {
+        l.debugPrev = l.Level()
+    }
+    l.SetLevel(LevelDebug)
+
+    var t *time.Timer
+    t = time.AfterFunc(d, func() {
+        l.mu.Lock()
+        defer l.mu.Unlock()
+        if l.debugTimer != t {
+            return // extended by a later call
+        }
+        l.debugTimer = nil
+        atomic.CompareAndSwapInt32(&l.level, int32(LevelDebug), int32(l.debugPrev))
+    })
+    l.debugTimer = t
+}
@@ -0,0 +1,26 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestEnableDebugFor(t *testing.T) {
+	logger := New(ioutil.Discard, "", 0)
+	logger.SetLevel(LevelWarn)
+	logger.EnableDebugFor(20 * time.Millisecond)
+	logger.EnableDebugFor(50 * time.Millisecond)
+	if logger.Level() != LevelDebug {
+		t.Fatalf("expected DEBUG, got %v", logger.Level())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if logger.Level() != LevelDebug {
+		t.Fatalf("window not extended, got %v", logger.Level())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if logger.Level() != LevelWarn {
+		t.Errorf("expected WARN restored, got %v", logger.Level())
+	}
+}
@@ -0,0 +1,94 @@
+package aralog
+
+import (
+    "fmt"
+    "strings"
+    "sync"
+)
+
+// Line endings of a LineEndingSink.
+const (
+    LF   = "\n"
+    CRLF = "\r\n"
+)
+
+// ParseLineEnding parses a line ending name, case insensitive: lf or crlf.
+func ParseLineEnding(s string) (string, error) {
+    switch strings.ToLower(strings.TrimSpace(s)) {
+    case "lf":
+        return LF, nil
+    case "crlf":
+        return CRLF, nil
+    }
+    return "", fmt.Errorf("aralog: unknown line ending %q", s)
+}
+
+// LineEndingSink is a Sink writing the entries to another sink with the
+// newlines of their formatted lines, those of multi-line messages too,
+// replaced by its line ending, for files read by Windows tools.
+type LineEndingSink struct {
+    mu     sync.Mutex
+    sink   Sink
+    ending string
+    buf    []byte
+}
+
+// NewLineEndingSink creates a LineEndingSink in front of sink ending the
+// lines with ending, LF or CRLF.
+func NewLineEndingSink(sink Sink, ending string) *LineEndingSink {
+    return &LineEndingSink{sink: sink, ending: ending}
+}
+
+// Write writes e to the underlying sink with its line endings replaced.
+func (s *LineEndingSink) Write(e Entry) error {
+    if s.ending == LF {
+        return s.sink.Write(e)
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.buf = s.buf[:0]
+    for i, c := range e.Formatted {
+        if c == '\n' && (i == 0 || e.Formatted[i - 1] != '\r') {
+            s.buf = append(s.buf, s.ending...)
+        } else {
+            s.buf = append(s.buf, c)
+        }
+    }
+    e.Formatted = s.buf
+    return s.sink.Write(e)
+}
+
+// Flush flushes the underlying sink.
+func (s *LineEndingSink) Flush() error {
+    return s.sink.Flush()
+}
+
+// Close closes the underlying sink.
+func (s *LineEndingSink) Close() error {
+    return s.sink.Close()
+}
+
+// Healthy reports whether the underlying sink is healthy.
+func (s *LineEndingSink) Healthy() bool {
+    return s.sink.Healthy()
+}
+
+// Describe describes the line ending and the underlying sink.
+func (s *LineEndingSink) Describe() SinkDescription {
+    name := "lf"
+    if s.ending == CRLF {
+        name = "crlf"
+    }
+    return SinkDescription{
+        Type:     "lineEnding",
+        Healthy:  s.Healthy(),
+        Settings: map[string]interface{}{"lineEnding": name},
+        Sinks:    []SinkDescription{DescribeSink(s.sink)},
+    }
+}
+
+// LastError returns the last error of the underlying sink.
+func (s *LineEndingSink) LastError() error {
+    return SinkLastError(s.sink)
+}
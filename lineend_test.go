@@ -0,0 +1,43 @@
+package aralog
+
+import (
+	"testing"
+)
+
+func TestLineEndingSink(t *testing.T) {
+	ring := NewRingSink(10)
+	l := NewSinkLogger(NewLineEndingSink(ring, CRLF), "", 0)
+	l.Info("one")
+	l.Info("two\nlines")
+	l.Info("already\r\n")
+
+	want := []string{"one\r\n", "two\r\nlines\r\n", "already\r\n"}
+	entries := ring.Entries()
+	for i, e := range entries {
+		if string(e.Formatted) != want[i] {
+			t.Errorf("entry %d: got %q, want %q", i, e.Formatted, want[i])
+		}
+	}
+
+	ring = NewRingSink(10)
+	NewSinkLogger(NewLineEndingSink(ring, LF), "", 0).Info("one")
+	if got := string(ring.Entries()[0].Formatted); got != "one\n" {
+		t.Errorf("LF: got %q", got)
+	}
+}
+
+func TestLineEndingConfig(t *testing.T) {
+	c := &Config{Outputs: []OutputConfig{{Type: "stdout", LineEnding: "CRLF"}}}
+	l, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := DescribeSink(l.Sink()); d.Type != "lineEnding" || d.Settings["lineEnding"] != "crlf" {
+		t.Errorf("unexpected sink %+v", d)
+	}
+
+	c.Outputs[0].LineEnding = "cr"
+	if err := c.Validate(); err == nil {
+		t.Error("line ending cr validated")
+	}
+}
@@ -0,0 +1,119 @@
+package aralog
+
+import (
+    "bytes"
+    "sync"
+    "unicode/utf8"
+)
+
+// LineLengthSink is a Sink capping the formatted lines it writes to
+// another sink at a number of bytes, for sinks like syslog over UDP which
+// can't take long lines while files can. Longer lines are cut, ending with
+// "…" within the cap, or split into several lines of at most the cap, never
+// within a UTF-8 sequence. The cap does not count the line ending.
+type LineLengthSink struct {
+    mu    sync.Mutex
+    sink  Sink
+    max   int
+    split bool
+    buf   []byte
+}
+
+// NewLineLengthSink creates a LineLengthSink in front of sink capping the
+// lines at max bytes, splitting them if split is set.
+func NewLineLengthSink(sink Sink, max int, split bool) *LineLengthSink {
+    return &LineLengthSink{sink: sink, max: max, split: split}
+}
+
+// Write writes e to the underlying sink, cut or split into several writes
+// if its formatted line is too long. It returns the first error.
+func (s *LineLengthSink) Write(e Entry) error {
+    line, ending := splitLineEnding(e.Formatted)
+    if s.max <= 0 || len(line) <= s.max {
+        return s.sink.Write(e)
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if !s.split {
+        mark := "…"
+        if s.max < len(mark) {
+            mark = ""
+        }
+        cut := cutUTF8(line, s.max - len(mark))
+        s.buf = append(append(append(s.buf[:0], line[:cut]...), mark...), ending...)
+        e.Formatted = s.buf
+        return s.sink.Write(e)
+    }
+
+    var first error
+    for len(line) > 0 {
+        cut := cutUTF8(line, s.max)
+        if cut == 0 {
+            _, cut = utf8.DecodeRune(line) // the cap is below the size of the rune
+        }
+        s.buf = append(append(s.buf[:0], line[:cut]...), ending...)
+        e.Formatted = s.buf
+        if err := s.sink.Write(e); err != nil && first == nil {
+            first = err
+        }
+        line = line[cut:]
+    }
+    return first
+}
+
+// splitLineEnding splits the trailing LF or CRLF off line.
+func splitLineEnding(line []byte) ([]byte, []byte) {
+    switch {
+    case bytes.HasSuffix(line, []byte(CRLF)):
+        return line[:len(line) - 2], line[len(line) - 2:]
+    case bytes.HasSuffix(line, []byte(LF)):
+        return line[:len(line) - 1], line[len(line) - 1:]
+    }
+    return line, nil
+}
+
+// cutUTF8 returns the largest length of at most n bytes at which b can be
+// cut without splitting a UTF-8 sequence.
+func cutUTF8(b []byte, n int) int {
+    if n <= 0 {
+        return 0
+    }
+    if n >= len(b) {
+        return len(b)
+    }
+    for n > 0 && !utf8.RuneStart(b[n]) {
+        n--
+    }
+    return n
+}
+
+// Flush flushes the underlying sink.
+func (s *LineLengthSink) Flush() error {
+    return s.sink.Flush()
+}
+
+// Close closes the underlying sink.
+func (s *LineLengthSink) Close() error {
+    return s.sink.Close()
+}
+
+// Healthy reports whether the underlying sink is healthy.
+func (s *LineLengthSink) Healthy() bool {
+    return s.sink.Healthy()
+}
+
+// Describe describes the cap and the underlying sink.
+func (s *LineLengthSink) Describe() SinkDescription {
+    return SinkDescription{
+        Type:     "lineLength",
+        Healthy:  s.Healthy(),
+        Settings: map[string]interface{}{"maxLineLength": s.max, "split": s.split},
+        Sinks:    []SinkDescription{DescribeSink(s.sink)},
+    }
+}
+
+// LastError returns the last error of the underlying sink.
+func (s *LineLengthSink) LastError() error {
+    return SinkLastError(s.sink)
+}
@@ -0,0 +1,65 @@
+package aralog
+
+import (
+	"testing"
+)
+
+func formattedLines(r *RingSink) []string {
+	var lines []string
+	for _, e := range r.Entries() {
+		lines = append(lines, string(e.Formatted))
+	}
+	return lines
+}
+
+func TestLineLengthSinkCut(t *testing.T) {
+	ring := NewRingSink(10)
+	l := NewSinkLogger(NewLineLengthSink(ring, 8, false), "", 0)
+	l.Info("short")
+	l.Info("exactly8")
+	l.Info("héllo wörld")
+
+	want := []string{"short\n", "exactly8\n", "héll…\n"}
+	got := formattedLines(ring)
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLineLengthSinkSplit(t *testing.T) {
+	ring := NewRingSink(10)
+	l := NewSinkLogger(NewLineEndingSink(NewLineLengthSink(ring, 4, true), CRLF), "", 0)
+	l.Info("abcdéfgh")
+
+	want := []string{"abcd\r\n", "éfg\r\n", "h\r\n"}
+	got := formattedLines(ring)
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLineLengthConfig(t *testing.T) {
+	c := &Config{Outputs: []OutputConfig{{Type: "stdout", MaxLineLength: 8192, SplitLines: true}}}
+	l, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := DescribeSink(l.Sink()); d.Type != "lineLength" || d.Settings["maxLineLength"] != 8192 {
+		t.Errorf("unexpected sink %+v", d)
+	}
+
+	c.Outputs[0].MaxLineLength = -1
+	if err := c.Validate(); err == nil {
+		t.Error("negative maxLineLength validated")
+	}
+}
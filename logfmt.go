@@ -0,0 +1,62 @@
+package aralog
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+)
+
+// LogfmtEncoder is an Encoder writing each entry as one logfmt line:
+// time=2009-01-23T01:23:23.123456+08:00 level=info caller=d.go:23 msg=message
+// followed by the fields as additional pairs. Values are quoted if needed.
+type LogfmtEncoder struct {
+    TimeLayout string // time.Format layout of "time", RFC3339 with microseconds by default
+}
+
+// NewLogfmtEncoder creates a LogfmtEncoder.
+func NewLogfmtEncoder() *LogfmtEncoder {
+    return &LogfmtEncoder{TimeLayout: rfc3339Micro}
+}
+
+func (lf *LogfmtEncoder) Encode(buf *[]byte, e Entry) {
+    layout := lf.TimeLayout
+    if len(layout) == 0 {
+        layout = time.RFC3339Nano
+    }
+
+    *buf = append(*buf, "time="...)
+    *buf = append(*buf, e.Time.Format(layout)...)
+    *buf = append(*buf, " level="...)
+    *buf = append(*buf, strings.ToLower(e.Level.String())...)
+    if len(e.File) > 0 {
+        *buf = append(*buf, " caller="...)
+        appendLogfmt(buf, callerString(e))
+    }
+    *buf = append(*buf, " msg="...)
+    appendLogfmt(buf, strings.TrimSuffix(e.Message, "\n"))
+    for _, f := range e.Fields {
+        *buf = append(*buf, ' ')
+        *buf = append(*buf, f.Key...)
+        *buf = append(*buf, '=')
+        if err, ok := f.Value.(error); ok {
+            appendLogfmt(buf, err.Error())
+        } else {
+            appendLogfmt(buf, fmt.Sprint(f.Value))
+        }
+    }
+    *buf = append(*buf, '\n')
+}
+
+// appendLogfmt appends the logfmt value s, quoted if it is empty or has
+// spaces, quotes, equal signs or unprintable characters.
+func appendLogfmt(buf *[]byte, s string) {
+    if len(s) > 0 && strings.IndexFunc(s, func(r rune) bool {
+        return r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r)
+    }) < 0 {
+        *buf = append(*buf, s...)
+        return
+    }
+    *buf = strconv.AppendQuote(*buf, s)
+}
@@ -0,0 +1,227 @@
+package aralog
+
+import (
+    "bytes"
+    "fmt"
+    "net/smtp"
+    "strings"
+    "sync"
+    "time"
+)
+
+// MailSink is a Sink mailing critical entries for environments without
+// chat or webhook infrastructure. Entries at MinLevel or above, error by
+// default, are collected for BatchDelay and sent in one mail; fatal entries
+// are sent at once with the pending ones. Once an entry of a group, same
+// message with digits ignored, is mailed, the group is quiet for Cooldown:
+// its entries are only counted, and the count is reported with the next
+// entry of the group mailed, or with its last entry in a mail sent when the
+// group is no longer quiet.
+type MailSink struct {
+    mu         sync.Mutex
+    addr       string
+    auth       smtp.Auth
+    from       string
+    to         []string
+    pending    []string  // lines of the next mail
+    groups     map[string]*mailGroup
+    latest     time.Time // of the entries written, groups quiet until before it are removed
+    timer      *time.Timer
+    err        error     // last send error
+    send       func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+    MinLevel   Level
+    BatchDelay time.Duration
+    Cooldown   time.Duration
+}
+
+type mailGroup struct {
+    quietUntil time.Time
+    suppressed int
+    last       Entry       // last suppressed entry
+    summary    *time.Timer // mails the suppressed entries once the group is no longer quiet
+}
+
+// NewMailSink creates a MailSink sending through the SMTP server at addr,
+// host:port, from the address from to the addresses to. auth may be nil.
+func NewMailSink(addr string, auth smtp.Auth, from string, to ...string) *MailSink {
+    return &MailSink{
+        addr:       addr,
+        auth:       auth,
+        from:       from,
+        to:         to,
+        groups:     map[string]*mailGroup{},
+        send:       smtp.SendMail,
+        MinLevel:   LevelError,
+        BatchDelay: time.Minute,
+        Cooldown:   15 * time.Minute,
+    }
+}
+
+// Write queues e for the next mail, unless its group is quiet.
+func (s *MailSink) Write(e Entry) error {
+    if e.Level < s.MinLevel {
+        return nil
+    }
+
+    s.mu.Lock()
+    if e.Time.After(s.latest) {
+        s.latest = e.Time
+    }
+    key := templateKey(e.Message)
+    g := s.groups[key]
+    if g == nil {
+        g = &mailGroup{}
+        s.groups[key] = g
+    }
+    if e.Level < LevelFatal && e.Time.Before(g.quietUntil) {
+        g.suppressed++
+        e.Formatted = nil
+        g.last = e
+        if g.summary == nil {
+            g.summary = s.summaryTimer(g.quietUntil, g.quietUntil.Sub(e.Time))
+        }
+        s.mu.Unlock()
+        return nil
+    }
+    s.pending = append(s.pending, mailLine(e, g.suppressed))
+    g.stopSummary()
+    g.quietUntil, g.suppressed, g.last = e.Time.Add(s.Cooldown), 0, Entry{}
+
+    if e.Level >= LevelFatal {
+        s.mu.Unlock()
+        return s.Flush()
+    }
+    if s.timer == nil {
+        s.timer = time.AfterFunc(s.BatchDelay, func() {
+            if err := s.Flush(); err != nil {
+                internalError("mail: %v", err)
+            }
+        })
+    }
+    s.mu.Unlock()
+    return nil
+}
+
+// summaryTimer flushes in d, when the entries are taken to be written up
+// to until, so the groups quiet until then are mailed even if no entry
+// follows them.
+func (s *MailSink) summaryTimer(until time.Time, d time.Duration) *time.Timer {
+    return time.AfterFunc(d, func() {
+        s.mu.Lock()
+        if s.latest.Before(until) {
+            s.latest = until
+        }
+        s.mu.Unlock()
+        if err := s.Flush(); err != nil {
+            internalError("mail: %v", err)
+        }
+    })
+}
+
+func (g *mailGroup) stopSummary() {
+    if g.summary != nil {
+        g.summary.Stop()
+        g.summary = nil
+    }
+}
+
+// mailLine renders e as a line of a mail, with the count of the similar
+// entries suppressed before it.
+func mailLine(e Entry, suppressed int) string {
+    line := e.Time.Format(time.RFC3339) + " " + e.Level.String() + " " + strings.TrimSuffix(e.Message, "\n")
+    if suppressed > 0 {
+        line += fmt.Sprintf(" (and %d similar entries)", suppressed)
+    }
+    var b []byte
+    appendFields(&b, e.Fields)
+    return line + string(b)
+}
+
+// prune removes the groups no longer quiet, so messages with ever new
+// texts don't grow the groups for the life of the process. The last
+// suppressed entry of a group is mailed with the count of the others.
+// s.mu must be held.
+func (s *MailSink) prune() {
+    for key, g := range s.groups {
+        if s.latest.Before(g.quietUntil) {
+            continue
+        }
+        if g.suppressed > 0 {
+            s.pending = append(s.pending, mailLine(g.last, g.suppressed - 1))
+        }
+        g.stopSummary()
+        delete(s.groups, key)
+    }
+}
+
+// Flush mails the pending entries, and the last suppressed entries of the
+// groups no longer quiet.
+func (s *MailSink) Flush() error {
+    s.mu.Lock()
+    s.prune()
+    lines := s.pending
+    s.pending = nil
+    if s.timer != nil {
+        s.timer.Stop()
+        s.timer = nil
+    }
+    s.mu.Unlock()
+    if len(lines) == 0 {
+        return nil
+    }
+
+    var msg bytes.Buffer
+    fmt.Fprintf(&msg, "From: %s\r\nTo: %s\r\n", s.from, strings.Join(s.to, ", "))
+    fmt.Fprintf(&msg, "Subject: [aralog] %d critical entries on %s\r\n", len(lines), hostname)
+    msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+    for _, line := range lines {
+        msg.WriteString(sanitize(line))
+        msg.WriteString("\r\n")
+    }
+    err := s.send(s.addr, s.auth, s.from, s.to, msg.Bytes())
+
+    s.mu.Lock()
+    s.err = err
+    s.mu.Unlock()
+    return err
+}
+
+// Close mails the pending entries. The entries of the groups still quiet
+// are not mailed.
+func (s *MailSink) Close() error {
+    s.mu.Lock()
+    for _, g := range s.groups {
+        g.stopSummary()
+    }
+    s.mu.Unlock()
+    return s.Flush()
+}
+
+// Healthy reports whether the last mail was sent.
+func (s *MailSink) Healthy() bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err == nil
+}
+
+// LastError returns the error of the last mail, nil if it was sent.
+func (s *MailSink) LastError() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err
+}
+
+// Describe describes the sink with its server, recipients and timings.
+func (s *MailSink) Describe() SinkDescription {
+    return SinkDescription{
+        Type:    "mail",
+        Healthy: s.Healthy(),
+        Settings: map[string]interface{}{
+            "addr":       s.addr,
+            "to":         s.to,
+            "minLevel":   s.MinLevel.String(),
+            "batchDelay": s.BatchDelay.String(),
+            "cooldown":   s.Cooldown.String(),
+        },
+    }
+}
@@ -0,0 +1,105 @@
+package aralog
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMailSink(t *testing.T) {
+	var mails []string
+	sink := NewMailSink("smtp.example.com:25", nil, "app@example.com", "oncall@example.com")
+	sink.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mails = append(mails, string(msg))
+		return nil
+	}
+	sink.BatchDelay = time.Hour
+
+	now := time.Now()
+	entry := func(level Level, msg string, d time.Duration) Entry {
+		return Entry{Time: now.Add(d), Level: level, Message: msg}
+	}
+	sink.Write(entry(LevelWarn, "ignored", 0))
+	sink.Write(entry(LevelError, "db timeout after 30s", 0))
+	sink.Write(entry(LevelError, "db timeout after 31s", time.Minute))
+	sink.Write(entry(LevelError, "disk full", time.Minute))
+	if len(mails) != 0 {
+		t.Fatal("mail sent before the batch delay")
+	}
+	sink.Flush()
+	sink.Write(entry(LevelError, "db timeout after 32s", 20*time.Minute))
+	sink.Close()
+
+	if len(mails) != 2 {
+		t.Fatalf("sent %d mails, want 2", len(mails))
+	}
+	if !strings.Contains(mails[0], "Subject: [aralog] 2 critical entries") ||
+		!strings.Contains(mails[0], "db timeout after 30s") || !strings.Contains(mails[0], "disk full") ||
+		strings.Contains(mails[0], "31s") {
+		t.Errorf("first mail:\n%s", mails[0])
+	}
+	if !strings.Contains(mails[1], "db timeout after 32s (and 1 similar entries)") {
+		t.Errorf("second mail:\n%s", mails[1])
+	}
+}
+
+func TestMailSinkPrunesGroups(t *testing.T) {
+	var mails []string
+	sink := NewMailSink("smtp.example.com:25", nil, "app@example.com", "oncall@example.com")
+	sink.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mails = append(mails, string(msg))
+		return nil
+	}
+	sink.BatchDelay = time.Hour
+
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		// letters, the digits of messages are ignored when grouping
+		sink.Write(Entry{Time: now, Level: LevelError, Message: fmt.Sprintf("host web-%c%c unreachable", 'a'+i%26, 'a'+i/26)})
+	}
+	sink.Write(Entry{Time: now.Add(time.Minute), Level: LevelError, Message: "host web-aa unreachable"})
+	sink.Write(Entry{Time: now.Add(2 * time.Minute), Level: LevelError, Message: "host web-aa unreachable"})
+	sink.Flush()
+	if len(sink.groups) != 100 {
+		t.Fatalf("%d groups while quiet, want 100", len(sink.groups))
+	}
+
+	sink.Write(Entry{Time: now.Add(time.Hour), Level: LevelError, Message: "disk full"})
+	sink.Flush()
+	if len(sink.groups) != 1 {
+		t.Errorf("%d groups after the cooldown, want 1", len(sink.groups))
+	}
+	if len(mails) != 2 || !strings.Contains(mails[1], "host web-aa unreachable (and 1 similar entries)") {
+		t.Errorf("suppressed entries not reported:\n%s", mails[len(mails)-1])
+	}
+}
+
+func TestMailSinkSummaryWithoutLaterEntries(t *testing.T) {
+	mails := make(chan string, 4)
+	sink := NewMailSink("smtp.example.com:25", nil, "app@example.com", "oncall@example.com")
+	sink.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mails <- string(msg)
+		return nil
+	}
+	sink.BatchDelay = time.Millisecond
+	sink.Cooldown = 50 * time.Millisecond
+	defer sink.Close()
+
+	now := time.Now()
+	sink.Write(Entry{Time: now, Level: LevelError, Message: "queue stalled"})
+	sink.Write(Entry{Time: now.Add(10 * time.Millisecond), Level: LevelError, Message: "queue stalled"})
+	sink.Write(Entry{Time: now.Add(20 * time.Millisecond), Level: LevelError, Message: "queue stalled"})
+
+	for _, want := range []string{"queue stalled\r\n", "queue stalled (and 1 similar entries)"} {
+		select {
+		case mail := <-mails:
+			if !strings.Contains(mail, want) {
+				t.Errorf("mail without %q:\n%s", want, mail)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no mail with %q", want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package aralog
+
+import (
+    "compress/gzip"
+    "container/heap"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+)
+
+// RotatedFiles returns the files of the log at path, oldest first: the
+// files rolled by a RollFileSink, named path followed by digits and
+// possibly gzip compressed with a .gz suffix, next to path or in its dated
+// archive directories, then path itself if it exists. Signatures and other
+// files next to them are left out. The %{hostname} and %{pid} tokens in
+// path are replaced like NewRollFileSink does.
+func RotatedFiles(path string) ([]string, error) {
+    path = expandPathTokens(path)
+    matches, err := filepath.Glob(globEscape(path) + "*")
+    if err != nil {
+        return nil, err
+    }
+    dated, err := filepath.Glob(filepath.Join(globEscape(filepath.Dir(path)), "[0-9][0-9][0-9][0-9]", "[0-9][0-9]", "[0-9][0-9]", globEscape(filepath.Base(path)) + "*"))
+    if err != nil {
+        return nil, err
+    }
+    base := filepath.Base(path)
+
+    type rotated struct {
+        path string
+        mod  int64
+    }
+    var files []rotated
+    for _, m := range append(matches, dated...) {
+        if m != path && !isRolledName(strings.TrimSuffix(filepath.Base(m)[len(base):], ".gz")) {
+            continue
+        }
+        fi, err := os.Stat(m)
+        if err != nil || !fi.Mode().IsRegular() {
+            continue
+        }
+        files = append(files, rotated{m, fi.ModTime().UnixNano()})
+    }
+    // the active file is the newest, the rolled ones were last written
+    // before they were rolled
+    sort.SliceStable(files, func(i, j int) bool {
+        if (files[i].path == path) != (files[j].path == path) {
+            return files[j].path == path
+        }
+        if files[i].mod != files[j].mod {
+            return files[i].mod < files[j].mod
+        }
+        return files[i].path < files[j].path
+    })
+
+    paths := make([]string, len(files))
+    for i, f := range files {
+        paths[i] = f.path
+    }
+    return paths, nil
+}
+
+// isRolledName reports whether suffix is the suffix a RollFileSink appends
+// to the rolled files, a time stamp or Unix time.
+func isRolledName(suffix string) bool {
+    if len(suffix) == 0 {
+        return false
+    }
+    for _, c := range suffix {
+        if c < '0' || c > '9' {
+            return false
+        }
+    }
+    return true
+}
+
+// globEscape escapes the meta characters of filepath.Match in path.
+func globEscape(path string) string {
+    r := strings.NewReplacer(`*`, `\*`, `?`, `\?`, `[`, `\[`)
+    if filepath.Separator == '\\' {
+        r = strings.NewReplacer(`*`, `[*]`, `?`, `[?]`, `[`, `[[]`)
+    }
+    return r.Replace(path)
+}
+
+// MergeReader is an EntryReader reading the entries of several logs in
+// time order, see OpenRotated.
+type MergeReader struct {
+    files   []io.Closer
+    sources mergeHeap
+    errs    []error // errors of the readers to return before the next entry
+}
+
+type mergeSource struct {
+    r     EntryReader
+    next  Entry
+    at    time.Time // of next, or of the entry before it if next has none
+    order int       // of the reader, breaks ties between entries of the same time
+}
+
+type mergeHeap []*mergeSource
+
+func (h mergeHeap) Len() int      { return len(h) }
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h mergeHeap) Less(i, j int) bool {
+    if !h[i].at.Equal(h[j].at) {
+        return h[i].at.Before(h[j].at)
+    }
+    return h[i].order < h[j].order
+}
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(*mergeSource)) }
+func (h *mergeHeap) Pop() interface{} {
+    old := *h
+    s := old[len(old) - 1]
+    *h = old[:len(old) - 1]
+    return s
+}
+
+// NewMergeReader creates an EntryReader yielding the entries of readers in
+// time order, each of them read oldest first. Entries of the same time keep
+// the order of readers, entries without a time the position they have in
+// their reader.
+func NewMergeReader(readers ...EntryReader) *MergeReader {
+    m := &MergeReader{}
+    for i, r := range readers {
+        m.fill(&mergeSource{r: r, order: i})
+    }
+    heap.Init(&m.sources)
+    return m
+}
+
+// OpenRotated opens the log at path with all its rotated and compressed
+// siblings, see RotatedFiles, and reads their entries in time order. Each
+// file is read by open, for instance NewJSONReader. The MergeReader must be
+// closed.
+func OpenRotated(path string, open func(io.Reader) EntryReader) (*MergeReader, error) {
+    paths, err := RotatedFiles(path)
+    if err != nil {
+        return nil, err
+    }
+
+    var files []io.Closer
+    var readers []EntryReader
+    closeAll := func() {
+        for _, f := range files {
+            f.Close()
+        }
+    }
+    for _, p := range paths {
+        f, err := os.Open(p)
+        if err != nil {
+            closeAll()
+            return nil, err
+        }
+        files = append(files, f)
+        var r io.Reader = f
+        if strings.HasSuffix(p, ".gz") {
+            zr, err := gzip.NewReader(f)
+            if err != nil {
+                closeAll()
+                return nil, err
+            }
+            files = append(files, zr)
+            r = zr
+        }
+        readers = append(readers, open(r))
+    }
+
+    m := NewMergeReader(readers...)
+    m.files = files
+    return m, nil
+}
+
+// fill reads the next entry of s and appends s to the sources unless it is
+// exhausted. Errors are queued to be returned by Read, s is dropped after
+// other errors than parse errors.
+func (m *MergeReader) fill(s *mergeSource) {
+    for {
+        e, err := s.r.Read()
+        if _, ok := err.(*ParseError); ok {
+            m.errs = append(m.errs, err)
+            continue
+        }
+        if err != nil {
+            if err != io.EOF {
+                m.errs = append(m.errs, err)
+            }
+            return
+        }
+        s.next = e
+        if !e.Time.IsZero() {
+            s.at = e.Time
+        }
+        m.sources = append(m.sources, s)
+        return
+    }
+}
+
+// Read returns the oldest entry not read yet, io.EOF once all readers are
+// exhausted. Lines which do not parse are returned as a *ParseError and
+// the errors of the readers as they are, reading can go on.
+func (m *MergeReader) Read() (Entry, error) {
+    if len(m.errs) > 0 {
+        err := m.errs[0]
+        m.errs = m.errs[1:]
+        return Entry{}, err
+    }
+    if len(m.sources) == 0 {
+        return Entry{}, io.EOF
+    }
+
+    s := heap.Pop(&m.sources).(*mergeSource)
+    e := s.next
+    n := len(m.sources)
+    m.fill(s)
+    if len(m.sources) > n {
+        heap.Fix(&m.sources, n)
+    }
+    return e, nil
+}
+
+// Close closes the files opened by OpenRotated.
+func (m *MergeReader) Close() error {
+    var first error
+    for i := len(m.files) - 1; i >= 0; i-- {
+        if err := m.files[i].Close(); err != nil && first == nil {
+            first = err
+        }
+    }
+    m.files = nil
+    return first
+}
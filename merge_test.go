@@ -0,0 +1,143 @@
+package aralog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	old := time.Now().Add(-time.Hour)
+	for i, name := range []string{"app.log", "app.log20090123020000", "app.log20090123010000.gz", "app.log20090123020000.sig", "app.log.offset", "app.logs"} {
+		p := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(p, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		mod := old.Add(time.Duration(-i) * time.Minute)
+		os.Chtimes(p, mod, mod)
+	}
+
+	got, err := RotatedFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{path + "20090123010000.gz", path + "20090123020000", path}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestOpenRotated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	line := func(min int, msg string) string {
+		return fmt.Sprintf(`{"time":"2009-01-23T01:%02d:00Z","level":"INFO","msg":%q}`+"\n", min, msg)
+	}
+	path := filepath.Join(dir, "app.log")
+	write := func(name, content string, mod time.Time) {
+		p := filepath.Join(dir, name)
+		f, err := os.Create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var w io.Writer = f
+		var zw *gzip.Writer
+		if strings.HasSuffix(name, ".gz") {
+			zw = gzip.NewWriter(f)
+			w = zw
+		}
+		io.WriteString(w, content)
+		if zw != nil {
+			zw.Close()
+		}
+		f.Close()
+		os.Chtimes(p, mod, mod)
+	}
+	now := time.Now()
+	write("app.log20090123010000.gz", line(1, "a")+line(3, "c"), now.Add(-2*time.Hour))
+	// entries of another process overlapping the first file
+	write("app.log20090123010400", line(2, "b")+"garbage\n"+line(4, "d"), now.Add(-time.Hour))
+	write("app.log", line(4, "e")+line(5, "f"), now)
+
+	m, err := OpenRotated(path, NewJSONReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	var got []string
+	parseErrors := 0
+	for {
+		e, err := m.Read()
+		if err == io.EOF {
+			break
+		}
+		if _, ok := err.(*ParseError); ok {
+			parseErrors++
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, "") != "abcdef" {
+		t.Errorf("merged %v", got)
+	}
+	if parseErrors != 1 {
+		t.Errorf("%d parse errors, want 1", parseErrors)
+	}
+}
+
+func TestDatedArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	sink, err := NewRollFileSink(path, 1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sink.SetDatedArchive(true)
+	now := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	logger := NewSinkLogger(sink, "", 0)
+	logger.SetClock(func() time.Time { return now })
+	logger.Info("before")
+	sink.size = sink.maxsize
+	logger.Info("after")
+
+	rolled := filepath.Join(dir, "2009", "01", "23", "app.log20090123012323")
+	b, err := ioutil.ReadFile(rolled)
+	if err != nil || string(b) != "before\n" {
+		t.Fatalf("rolled file: %q, %v", b, err)
+	}
+	files, err := RotatedFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{rolled, path}; !reflect.DeepEqual(files, want) {
+		t.Errorf("got %v, want %v", files, want)
+	}
+}
@@ -0,0 +1,71 @@
+package aralog
+
+import (
+    "fmt"
+    "os"
+    "sync/atomic"
+)
+
+// The meta logger receives the errors of aralog itself, ex: a failed file
+// rotation, which must neither corrupt the entries of the application nor
+// be lost.
+var (
+    metaLogger   atomic.Value // metaBox
+    errorHandler atomic.Value // handlerBox
+    reporting    int32        // 1 while an internal error is being logged
+)
+
+type handlerBox struct {
+    h func(error)
+}
+
+type metaBox struct {
+    l *Logger
+}
+
+var defaultMetaLogger = New(os.Stderr, "aralog: ", LstdFlags)
+
+// SetMetaLogger sets the logger receiving the errors of aralog itself, nil
+// restores the default which writes them to stderr. The meta logger should
+// not write to the sinks it reports on.
+func SetMetaLogger(l *Logger) {
+    metaLogger.Store(metaBox{l})
+}
+
+// MetaLogger returns the logger receiving the errors of aralog itself.
+func MetaLogger() *Logger {
+    if b, _ := metaLogger.Load().(metaBox); b.l != nil {
+        return b.l
+    }
+    return defaultMetaLogger
+}
+
+// SetErrorHandler sets a function called with every failure of aralog, so
+// the application can surface them to its monitoring: failed sink writes,
+// file rotations and values which could not be encoded. nil removes it. It
+// is called synchronously, possibly with a logger locked, and must not log
+// to the logger which failed.
+func SetErrorHandler(h func(error)) {
+    errorHandler.Store(handlerBox{h})
+}
+
+// reportError passes err to the error handler, if any.
+func reportError(err error) {
+    if b, _ := errorHandler.Load().(handlerBox); b.h != nil {
+        b.h(err)
+    }
+}
+
+// internalError reports an error of aralog itself to the error handler and
+// the meta logger. An error raised while logging it, ex: by a meta logger
+// writing to the failing sink, goes to stderr to avoid a loop. It must not
+// be called with the lock of a sink held.
+func internalError(format string, v ...interface{}) {
+    reportError(fmt.Errorf("aralog: " + format, v...))
+    if !atomic.CompareAndSwapInt32(&reporting, 0, 1) {
+        fmt.Fprintf(os.Stderr, "aralog: " + format + "\n", v...)
+        return
+    }
+    defer atomic.StoreInt32(&reporting, 0)
+    MetaLogger().Error(format, v...)
+}
@@ -0,0 +1,62 @@
+package aralog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetaLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var meta bytes.Buffer
+	SetMetaLogger(New(&meta, "", 0))
+	defer SetMetaLogger(nil)
+
+	path := filepath.Join(dir, "app.log")
+	sink, err := NewRollFileSink(path, 1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	// a non-empty directory in the way of the rolled file fails the rename
+	now := time.Now()
+	os.MkdirAll(filepath.Join(path+now.Format("20060102150405"), "x"), 0755)
+	sink.size = sink.maxsize
+	if err = sink.Write(Entry{Time: now, Formatted: []byte("entry\n")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(meta.String(), "rolling "+path+": ") {
+		t.Errorf("unexpected meta log %q", meta.String())
+	}
+	b, _ := ioutil.ReadFile(path + strconv.FormatInt(now.Unix(), 10))
+	if string(b) != "entry\n" {
+		t.Errorf("unexpected file content %q", b)
+	}
+}
+
+func TestSetErrorHandler(t *testing.T) {
+	var errs []string
+	SetErrorHandler(func(err error) { errs = append(errs, err.Error()) })
+	defer SetErrorHandler(nil)
+
+	NewSinkLogger(&failingSink{}, "", 0).Error("lost")
+	logger := NewSinkLogger(NewDiscardSink(), "", 0).With(F("ch", make(chan int)))
+	logger.SetEncoder(NewJSONEncoder())
+	logger.Info("unencodable field")
+
+	if len(errs) != 2 || errs[0] != "disk full" || !strings.HasPrefix(errs[1], "aralog: encoding chan int as JSON: ") {
+		t.Errorf("unexpected errors %q", errs)
+	}
+}
@@ -0,0 +1,79 @@
+package aralog
+
+import (
+    "expvar"
+    "sync/atomic"
+)
+
+// counters of the entries written by loggers, updated atomically.
+type counters struct {
+    entries     [LevelFatal + 1]uint64 // entries handed to the sink, by level
+    bytes       uint64                 // bytes of the entries the sink accepted without error
+    writeErrors uint64                 // entries the sink failed to write
+    dropped     uint64                 // entries dropped by sinks, ex: sampled out
+    latency     latencyHistogram       // durations of the sink writes
+    lastErr     atomic.Value           // errorBox of the last failed write
+}
+
+// add counts an entry of n bytes at level, written with the result err.
+func (c *counters) add(level Level, n int, err error) {
+    if level < LevelDebug || level > LevelFatal {
+        level = LevelInfo
+    }
+    atomic.AddUint64(&c.entries[level], 1)
+    if err != nil {
+        atomic.AddUint64(&c.writeErrors, 1)
+        c.lastErr.Store(errorBox{err})
+    } else {
+        atomic.AddUint64(&c.bytes, uint64(n))
+    }
+}
+
+// process wide metrics of all loggers and sinks.
+var (
+    totals    counters
+    rotations uint64 // files rolled by RollFileSink
+)
+
+// The metrics are published by expvar as "aralog", ex: on /debug/vars
+//
+//  "aralog": {"bytes": 5120, "dropped": 0, "entries": {"DEBUG": 0, "INFO": 42, ...}, "rotations": 1, "writeErrors": 0}
+func init() {
+    expvar.Publish("aralog", expvar.Func(func() interface{} {
+        entries := map[string]uint64{}
+        for level := range totals.entries {
+            entries[Level(level).String()] = atomic.LoadUint64(&totals.entries[level])
+        }
+        return map[string]interface{}{
+            "entries":     entries,
+            "bytes":       atomic.LoadUint64(&totals.bytes),
+            "dropped":     atomic.LoadUint64(&totals.dropped),
+            "rotations":   atomic.LoadUint64(&rotations),
+            "writeErrors": atomic.LoadUint64(&totals.writeErrors),
+        }
+    }))
+}
+
+// Stats are the counters of a Logger, see Logger.Stats.
+type Stats struct {
+    Entries     [LevelFatal + 1]uint64 // entries handed to the sink, by Level
+    Bytes       uint64                 // bytes of the entries the sink accepted without error
+    WriteErrors uint64                 // entries the sink failed to write
+    Dropped     uint64                 // entries dropped by sinks, ex: sampled out
+    Latency     Latency                // durations of the sink writes
+}
+
+// Stats returns the counters of the logger, including the entries of the
+// loggers derived from it by With. Entries filtered out by level, filter or
+// rule are not counted, only the entries lost after they were accepted.
+func (l *Logger) Stats() Stats {
+    var s Stats
+    for level := range s.Entries {
+        s.Entries[level] = atomic.LoadUint64(&l.stats.entries[level])
+    }
+    s.Bytes = atomic.LoadUint64(&l.stats.bytes)
+    s.WriteErrors = atomic.LoadUint64(&l.stats.writeErrors)
+    s.Dropped = atomic.LoadUint64(&l.stats.dropped)
+    s.Latency = l.stats.latency.summary()
+    return s
+}
@@ -0,0 +1,60 @@
+package aralog
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+type failingSink struct{ DiscardSink }
+
+func (failingSink) Write(Entry) error { return errors.New("disk full") }
+
+func TestExpvarMetrics(t *testing.T) {
+	var before, after struct {
+		Entries     map[string]uint64
+		Bytes       uint64
+		WriteErrors uint64
+	}
+	read := func(v interface{}) {
+		if err := json.Unmarshal([]byte(expvar.Get("aralog").String()), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read(&before)
+	New(ioutil.Discard, "", 0).Warn("12345")
+	NewSinkLogger(&failingSink{}, "", 0).Warn("lost")
+	read(&after)
+
+	if n := after.Entries["WARN"] - before.Entries["WARN"]; n != 2 {
+		t.Errorf("got %d WARN entries, want 2", n)
+	}
+	if n := after.Bytes - before.Bytes; n != 6 {
+		t.Errorf("got %d bytes, want 6", n)
+	}
+	if n := after.WriteErrors - before.WriteErrors; n != 1 {
+		t.Errorf("got %d write errors, want 1", n)
+	}
+}
+
+func TestStats(t *testing.T) {
+	logger := NewSinkLogger(NewSamplingSink(NewDiscardSink(), time.Hour, 1, 0), "", 0)
+	logger.SetLevel(LevelInfo)
+	logger.Debug("filtered by level")
+	logger.Info("sampled")
+	logger.With(F("k", "v")).Info("sampled")
+	logger.SetSink(&failingSink{})
+	logger.Error("failed")
+
+	s := logger.Stats()
+	if s.Entries[LevelDebug] != 0 || s.Entries[LevelInfo] != 2 || s.Entries[LevelError] != 1 {
+		t.Errorf("unexpected entries %v", s.Entries)
+	}
+	if s.Bytes != 20 || s.Dropped != 1 || s.WriteErrors != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
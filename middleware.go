@@ -0,0 +1,32 @@
+package aralog
+
+// Middleware processes every entry of a logger before it is numbered,
+// hooked and written. It may rewrite the message and level and replace
+// e.Fields with a new slice, the slice given must not be modified. It
+// returns false to drop the entry, which the following middleware and hooks
+// never see; audit loggers write it anyway. Middleware runs with the logger
+// locked and must not log to it.
+type Middleware func(e *Entry) bool
+
+// Use appends middleware to the chain of the logger, which runs in the
+// order it was added, ex:
+//
+//	l.Use(dropHealthChecks, addTenant)
+//
+// Loggers created from l by With afterwards inherit the chain.
+func (l *Logger) Use(m ...Middleware) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.middleware = append(l.middleware[:len(l.middleware):len(l.middleware)], m...)
+}
+
+// runMiddleware passes e through the middleware chain and reports whether it
+// is kept. l.mu must be held.
+func (l *Logger) runMiddleware(e *Entry) bool {
+    for _, m := range l.middleware {
+        if !m(e) && !l.audit {
+            return false
+        }
+    }
+    return true
+}
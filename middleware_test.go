@@ -0,0 +1,32 @@
+package aralog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUse(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	var order []string
+	logger.Use(func(e *Entry) bool {
+		order = append(order, "drop")
+		return !strings.HasPrefix(e.Message, "GET /health")
+	}, func(e *Entry) bool {
+		order = append(order, "tenant")
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], F("tenant", "acme"))
+		e.Message = strings.ToUpper(e.Message)
+		return true
+	})
+
+	logger.Info("GET /health")
+	logger.Info("GET /orders")
+
+	if want := "GET /ORDERS tenant=acme\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if strings.Join(order, ",") != "drop,drop,tenant" {
+		t.Errorf("middleware ran as %v", order)
+	}
+}
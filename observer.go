@@ -0,0 +1,211 @@
+package aralog
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+)
+
+// ObservedEntry is an entry recorded by ObservedLogs, with its structure
+// kept rather than formatted.
+type ObservedEntry struct {
+    Time    time.Time
+    Level   Level
+    Logger  string
+    Message string
+    Fields  []Field
+}
+
+// FieldMap returns the fields of the entry by key.
+func (e ObservedEntry) FieldMap() map[string]interface{} {
+    m := make(map[string]interface{}, len(e.Fields))
+    for _, f := range e.Fields {
+        m[f.Key] = f.Value
+    }
+    return m
+}
+
+// ObservedLogs is an in-memory Sink recording structured entries, to be
+// inspected by tests or served on a debug endpoint. The Filter methods
+// return a new ObservedLogs with the matching entries recorded so far, so
+// they can be chained:
+//
+//	obs.FilterLevel(aralog.LevelError).FilterField(aralog.F("order", 7)).Len()
+type ObservedLogs struct {
+    mu      sync.Mutex
+    entries []ObservedEntry
+    max     int // 0 for no limit
+}
+
+// NewObservedLogs creates an ObservedLogs keeping the last max entries, all
+// of them if max is 0.
+func NewObservedLogs(max int) *ObservedLogs {
+    return &ObservedLogs{max: max}
+}
+
+// Write records e.
+func (o *ObservedLogs) Write(e Entry) error {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    if o.max > 0 && len(o.entries) == o.max {
+        copy(o.entries, o.entries[1:])
+        o.entries = o.entries[:o.max - 1]
+    }
+    o.entries = append(o.entries, ObservedEntry{
+        Time:    e.Time,
+        Level:   e.Level,
+        Logger:  e.Logger,
+        Message: strings.TrimSuffix(e.Message, "\n"),
+        Fields:  append([]Field(nil), e.Fields...),
+    })
+    return nil
+}
+
+// Flush does nothing.
+func (o *ObservedLogs) Flush() error {
+    return nil
+}
+
+// Close does nothing, the entries stay available.
+func (o *ObservedLogs) Close() error {
+    return nil
+}
+
+// Healthy always returns true.
+func (o *ObservedLogs) Healthy() bool {
+    return true
+}
+
+// Describe describes the sink with its limit.
+func (o *ObservedLogs) Describe() SinkDescription {
+    return SinkDescription{Type: "observer", Healthy: true, Settings: map[string]interface{}{"max": o.max}}
+}
+
+// Len returns the number of recorded entries.
+func (o *ObservedLogs) Len() int {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    return len(o.entries)
+}
+
+// All returns the recorded entries, oldest first.
+func (o *ObservedLogs) All() []ObservedEntry {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    return append([]ObservedEntry(nil), o.entries...)
+}
+
+// TakeAll returns the recorded entries and forgets them.
+func (o *ObservedLogs) TakeAll() []ObservedEntry {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    entries := o.entries
+    o.entries = nil
+    return entries
+}
+
+// Filter returns the entries for which keep returns true.
+func (o *ObservedLogs) Filter(keep func(ObservedEntry) bool) *ObservedLogs {
+    f := &ObservedLogs{}
+    for _, e := range o.All() {
+        if keep(e) {
+            f.entries = append(f.entries, e)
+        }
+    }
+    return f
+}
+
+// FilterLevel returns the entries at level or above.
+func (o *ObservedLogs) FilterLevel(level Level) *ObservedLogs {
+    return o.Filter(func(e ObservedEntry) bool { return e.Level >= level })
+}
+
+// FilterMessage returns the entries with the message msg.
+func (o *ObservedLogs) FilterMessage(msg string) *ObservedLogs {
+    return o.Filter(func(e ObservedEntry) bool { return e.Message == msg })
+}
+
+// FilterMessageSnippet returns the entries with a message containing s.
+func (o *ObservedLogs) FilterMessageSnippet(s string) *ObservedLogs {
+    return o.Filter(func(e ObservedEntry) bool { return strings.Contains(e.Message, s) })
+}
+
+// FilterField returns the entries with the field f, values are compared in
+// their fmt.Sprint form.
+func (o *ObservedLogs) FilterField(f Field) *ObservedLogs {
+    want := fmt.Sprint(f.Value)
+    return o.Filter(func(e ObservedEntry) bool {
+        for _, ef := range e.Fields {
+            if ef.Key == f.Key && fmt.Sprint(ef.Value) == want {
+                return true
+            }
+        }
+        return false
+    })
+}
+
+// FilterFieldKey returns the entries with a field with the key.
+func (o *ObservedLogs) FilterFieldKey(key string) *ObservedLogs {
+    return o.Filter(func(e ObservedEntry) bool {
+        for _, f := range e.Fields {
+            if f.Key == key {
+                return true
+            }
+        }
+        return false
+    })
+}
+
+type observedBody struct {
+    Time    time.Time              `json:"time"`
+    Level   string                 `json:"level"`
+    Logger  string                 `json:"logger,omitempty"`
+    Message string                 `json:"msg"`
+    Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// ServeHTTP lists the recorded entries as a JSON array, to be mounted on a
+// debug mux. They are filtered by the optional query parameters level,
+// lowest level, msg, message snippet, and field, key=value.
+func (o *ObservedLogs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    q := r.URL.Query()
+    logs := o
+    if s := q.Get("level"); len(s) > 0 {
+        level, err := ParseLevel(s)
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+        logs = logs.FilterLevel(level)
+    }
+    if s := q.Get("msg"); len(s) > 0 {
+        logs = logs.FilterMessageSnippet(s)
+    }
+    for _, s := range q["field"] {
+        kv := strings.SplitN(s, "=", 2)
+        if len(kv) != 2 {
+            http.Error(w, "field must be key=value: " + s, http.StatusBadRequest)
+            return
+        }
+        logs = logs.FilterField(F(kv[0], kv[1]))
+    }
+
+    body := []observedBody{}
+    for _, e := range logs.All() {
+        b := observedBody{Time: e.Time, Level: e.Level.String(), Logger: e.Logger, Message: e.Message}
+        if len(e.Fields) > 0 {
+            b.Fields = e.FieldMap()
+            for k, v := range b.Fields {
+                if err, ok := v.(error); ok {
+                    b.Fields[k] = err.Error()
+                }
+            }
+        }
+        body = append(body, b)
+    }
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,46 @@
+package aralog
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestObservedLogs(t *testing.T) {
+	obs := NewObservedLogs(3)
+	logger := NewSinkLogger(obs, "", 0)
+	logger.Debug("dropped by the limit")
+	logger.Info("order placed")
+	logger.With(F("order", 7)).Error("payment failed")
+	logger.With(F("order", 8)).Error("payment failed")
+
+	if obs.Len() != 3 {
+		t.Fatalf("recorded %d entries, want 3", obs.Len())
+	}
+	if n := obs.FilterLevel(LevelError).FilterField(F("order", 7)).Len(); n != 1 {
+		t.Errorf("%d entries for order 7", n)
+	}
+	if n := obs.FilterMessage("payment failed").Len(); n != 2 {
+		t.Errorf("%d payment failures", n)
+	}
+	if n := obs.FilterMessageSnippet("order").FilterFieldKey("order").Len(); n != 0 {
+		t.Errorf("%d entries with order in the message and fields", n)
+	}
+	if e := obs.All()[2]; e.FieldMap()["order"] != 8 {
+		t.Errorf("unexpected fields %v", e.Fields)
+	}
+
+	rec := httptest.NewRecorder()
+	obs.ServeHTTP(rec, httptest.NewRequest("GET", "/logs?level=error&field=order=8", nil))
+	var body []observedBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 1 || body[0].Message != "payment failed" || body[0].Fields["order"] != float64(8) {
+		t.Errorf("unexpected body %s", rec.Body)
+	}
+
+	if len(obs.TakeAll()) != 3 || obs.Len() != 0 {
+		t.Error("TakeAll kept the entries")
+	}
+}
@@ -0,0 +1,29 @@
+//go:build aralog_otel
+// +build aralog_otel
+
+// With the aralog_otel tag, the context-aware logging methods log the
+// OpenTelemetry span active on the context:
+//
+//	go build -tags aralog_otel
+
+package aralog
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/trace"
+)
+
+func init() {
+    SpanFromContext = otelSpan
+}
+
+// otelSpan returns the OpenTelemetry span active on ctx, or else the
+// TraceContext stored by WithTrace.
+func otelSpan(ctx context.Context) (TraceContext, bool) {
+    sc := trace.SpanContextFromContext(ctx)
+    if !sc.IsValid() {
+        return TraceFromContext(ctx)
+    }
+    return TraceContext{TraceID: sc.TraceID().String(), SpanID: sc.SpanID().String(), Sampled: sc.IsSampled()}, true
+}
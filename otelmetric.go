@@ -0,0 +1,53 @@
+//go:build aralog_otel
+// +build aralog_otel
+
+package aralog
+
+import (
+    "context"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/metric"
+)
+
+// otelRecorder is the MetricsRecorder created by NewOTelRecorder.
+type otelRecorder struct {
+    records  metric.Int64Counter
+    dropped  metric.Int64Counter
+    duration metric.Float64Histogram
+}
+
+// NewOTelRecorder creates a MetricsRecorder from an OpenTelemetry
+// MeterProvider: it adds to a log.records and a log.dropped counter and
+// records the write latency in a log.sink.duration histogram, by logger and
+// level, ex:
+//
+//	r, err := aralog.NewOTelRecorder(otel.GetMeterProvider())
+//	aralog.SetMetricsRecorder(r)
+func NewOTelRecorder(mp metric.MeterProvider) (MetricsRecorder, error) {
+    meter := mp.Meter("github.com/araframework/aralog")
+    records, err := meter.Int64Counter("log.records", metric.WithDescription("Entries handed to the sinks."))
+    if err != nil {
+        return nil, err
+    }
+    dropped, err := meter.Int64Counter("log.dropped", metric.WithDescription("Entries dropped by sinks, ex: sampled out."))
+    if err != nil {
+        return nil, err
+    }
+    duration, err := meter.Float64Histogram("log.sink.duration", metric.WithDescription("Duration of the sink writes."), metric.WithUnit("s"))
+    if err != nil {
+        return nil, err
+    }
+    return otelRecorder{records, dropped, duration}, nil
+}
+
+func (r otelRecorder) RecordEntry(logger string, level Level, d time.Duration, err error) {
+    attrs := metric.WithAttributes(attribute.String("logger", logger), attribute.String("level", level.String()), attribute.Bool("error", err != nil))
+    r.records.Add(context.Background(), 1, attrs)
+    r.duration.Record(context.Background(), d.Seconds(), attrs)
+}
+
+func (r otelRecorder) RecordDropped(logger string, level Level) {
+    r.dropped.Add(context.Background(), 1, metric.WithAttributes(attribute.String("logger", logger), attribute.String("level", level.String())))
+}
@@ -0,0 +1,277 @@
+package aralog
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// An EntryReader reads entries back from a log, oldest first. Read returns
+// io.EOF after the last entry.
+type EntryReader interface {
+    Read() (Entry, error)
+}
+
+// ParseError is returned for a line which can't be parsed.
+type ParseError struct {
+    Line string
+    Msg  string
+}
+
+func (e *ParseError) Error() string {
+    return fmt.Sprintf("aralog: parsing %q: %s", e.Line, e.Msg)
+}
+
+// Parser parses lines of the text format back into entries. It must be
+// given the prefix and flags the lines were written with; the header
+// tokens of a HeaderHook are not supported. The text format does not tell
+// fields from a message ending with key=value words, the latter are parsed
+// as fields too.
+type Parser struct {
+    Prefix     string
+    Flag       int
+    TimeFormat string         // layout set by SetTimeFormat, if any
+    Location   *time.Location // of the times without zone, time.Local if nil, UTC with LUTC
+    Level      Level          // of the entries, the text format has no level
+    Fields     bool           // parse the trailing key=value pairs of the messages as fields
+}
+
+// NewParser creates a Parser for the lines written with prefix and flag.
+// The entries are given LevelInfo and their fields are parsed.
+func NewParser(prefix string, flag int) *Parser {
+    return &Parser{Prefix: prefix, Flag: flag, Level: LevelInfo, Fields: true}
+}
+
+// fieldSuffix matches the last " key=value" pair of a line.
+var fieldSuffix = regexp.MustCompile(` ([^\s="]+)=("(?:[^"\\]|\\.)*"|[^\s="]*)$`)
+
+// Parse parses one line, with or without its newline. Values of parsed
+// fields are strings; Formatted is the line with a newline.
+func (p *Parser) Parse(line string) (Entry, error) {
+    line = strings.TrimSuffix(line, "\n")
+    e := Entry{Level: p.Level, Formatted: []byte(line + "\n")}
+    s := line
+    fail := func(what string) (Entry, error) {
+        return Entry{}, &ParseError{line, "bad " + what}
+    }
+
+    if p.Flag & Lmsgprefix == 0 {
+        if !strings.HasPrefix(s, p.Prefix) {
+            return fail("prefix")
+        }
+        s = s[len(p.Prefix):]
+    }
+
+    var err error
+    if e.Time, s, err = p.parseTime(s); err != nil {
+        return fail("time")
+    }
+    if p.Flag & Lelapsed != 0 && !skipToken(&s, "+") {
+        return fail("elapsed time")
+    }
+    if p.Flag & Ldelta != 0 && !skipToken(&s, "+") {
+        return fail("delta")
+    }
+    if p.Flag & Lseq != 0 {
+        tok, ok := token(&s, "#")
+        if e.Seq, err = strconv.ParseUint(tok, 10, 64); !ok || err != nil {
+            return fail("sequence number")
+        }
+    }
+    if p.Flag & (Lhostname | Lpid) != 0 && !skipToken(&s, "") {
+        return fail("host")
+    }
+    if p.Flag & Lgoroutine != 0 {
+        tok, ok := token(&s, "g")
+        if e.Goroutine, err = strconv.ParseInt(tok, 10, 64); !ok || err != nil {
+            return fail("goroutine")
+        }
+    }
+    if p.Flag & (Lshortfile | Llongfile) != 0 && p.Flag & Lcallerlast == 0 {
+        i := strings.Index(s, ": ")
+        if i < 0 || !parseCaller(s[:i], &e) {
+            return fail("caller")
+        }
+        s = s[i + 2:]
+    }
+    if p.Flag & (Lfuncname | Llongfuncname) != 0 {
+        i := strings.Index(s, ": ")
+        if i < 0 {
+            return fail("function")
+        }
+        e.Func, s = s[:i], s[i + 2:]
+    }
+    if p.Flag & Lmsgprefix != 0 {
+        if !strings.HasPrefix(s, p.Prefix) {
+            return fail("prefix")
+        }
+        s = s[len(p.Prefix):]
+    }
+
+    if p.Flag & (Lshortfile | Llongfile) != 0 && p.Flag & Lcallerlast != 0 {
+        i := strings.LastIndex(s, " (")
+        if i < 0 || !strings.HasSuffix(s, ")") || !parseCaller(s[i + 2:len(s) - 1], &e) {
+            return fail("caller")
+        }
+        s = s[:i]
+    }
+    if p.Fields {
+        s, e.Fields = parseFields(s)
+    }
+    e.Message = s
+    return e, nil
+}
+
+// parseTime parses the time at the start of s.
+func (p *Parser) parseTime(s string) (time.Time, string, error) {
+    loc := p.Location
+    if p.Flag & LUTC != 0 {
+        loc = time.UTC
+    } else if loc == nil {
+        loc = time.Local
+    }
+
+    var t time.Time
+    var err error
+    switch {
+    case len(p.TimeFormat) > 0:
+        n := strings.Count(p.TimeFormat, " ") + 1
+        fields := strings.SplitN(s, " ", n + 1)
+        if len(fields) <= n {
+            return t, s, fmt.Errorf("short line")
+        }
+        t, err = time.ParseInLocation(p.TimeFormat, strings.Join(fields[:n], " "), loc)
+        return t, fields[n], err
+    case p.Flag & (LEpochMillis | LEpochNanos) != 0:
+        tok, _ := token(&s, "")
+        n, err := strconv.ParseInt(tok, 10, 64)
+        if p.Flag & LEpochNanos != 0 {
+            return time.Unix(0, n).In(loc), s, err
+        }
+        return time.Unix(0, n * 1e6).In(loc), s, err
+    case p.Flag & LRFC3339 != 0:
+        tok, _ := token(&s, "")
+        t, err = time.Parse(time.RFC3339Nano, tok)
+        return t, s, err
+    case p.Flag & (Ldate | Ltime | Lmicroseconds) != 0:
+        var layout, value []string
+        if p.Flag & Ldate != 0 {
+            tok, _ := token(&s, "")
+            layout, value = append(layout, "2006/01/02"), append(value, tok)
+        }
+        if p.Flag & (Ltime | Lmicroseconds) != 0 {
+            tok, _ := token(&s, "")
+            l := "15:04:05"
+            if p.Flag & Lmicroseconds != 0 {
+                l += ".000000"
+            }
+            layout, value = append(layout, l), append(value, tok)
+        }
+        t, err = time.ParseInLocation(strings.Join(layout, " "), strings.Join(value, " "), loc)
+        return t, s, err
+    }
+    return t, s, nil
+}
+
+// token cuts the token up to the next space off s, and reports whether it
+// starts with prefix, which is removed.
+func token(s *string, prefix string) (string, bool) {
+    tok := *s
+    if i := strings.IndexByte(tok, ' '); i >= 0 {
+        tok, *s = tok[:i], tok[i + 1:]
+    } else {
+        *s = ""
+    }
+    if !strings.HasPrefix(tok, prefix) || len(tok) == 0 {
+        return tok, false
+    }
+    return tok[len(prefix):], true
+}
+
+func skipToken(s *string, prefix string) bool {
+    _, ok := token(s, prefix)
+    return ok
+}
+
+// parseCaller parses file:line into e.
+func parseCaller(s string, e *Entry) bool {
+    i := strings.LastIndexByte(s, ':')
+    if i < 0 {
+        return false
+    }
+    line, err := strconv.Atoi(s[i + 1:])
+    if err != nil {
+        return false
+    }
+    e.File, e.Line = s[:i], line
+    return true
+}
+
+// parseFields cuts the trailing key=value pairs off msg.
+func parseFields(msg string) (string, []Field) {
+    var fields []Field
+    for {
+        m := fieldSuffix.FindStringSubmatchIndex(msg)
+        if m == nil {
+            break
+        }
+        key, value := msg[m[2]:m[3]], msg[m[4]:m[5]]
+        if strings.HasPrefix(value, `"`) {
+            if v, err := strconv.Unquote(value); err == nil {
+                value = v
+            }
+        }
+        fields = append([]Field{F(key, value)}, fields...)
+        msg = msg[:m[0]]
+    }
+    return msg, fields
+}
+
+// textReader is the EntryReader of NewTextReader.
+type textReader struct {
+    r       *bufio.Reader
+    p       *Parser
+    pending *Entry // parsed, returned once its continuation lines are read
+    err     error
+}
+
+// NewTextReader creates an EntryReader parsing the text format by p. Lines
+// which do not parse are taken as continuation lines of a message with
+// newlines, and appended to the previous entry; before the first entry
+// they are skipped.
+func NewTextReader(r io.Reader, p *Parser) EntryReader {
+    return &textReader{r: bufio.NewReader(r), p: p}
+}
+
+func (t *textReader) Read() (Entry, error) {
+    for t.err == nil {
+        line, err := t.r.ReadString('\n')
+        t.err = err
+        if len(line) == 0 {
+            break
+        }
+        e, perr := t.p.Parse(line)
+        if perr != nil {
+            if t.pending != nil {
+                t.pending.Message += "\n" + strings.TrimSuffix(line, "\n")
+                t.pending.Formatted = append(t.pending.Formatted, line...)
+            }
+            continue
+        }
+        if prev := t.pending; prev != nil {
+            t.pending = &e
+            return *prev, nil
+        }
+        t.pending = &e
+    }
+    if t.pending != nil {
+        e := *t.pending
+        t.pending = nil
+        return e, nil
+    }
+    return Entry{}, t.err
+}
@@ -0,0 +1,71 @@
+package aralog
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestParser(t *testing.T) {
+	flag := LstdFlags | Lmicroseconds | LUTC | Lseq | Lgoroutine | Lshortfile
+	var buf bytes.Buffer
+	logger := New(&buf, "app: ", flag)
+	now := time.Date(2009, 1, 23, 1, 23, 23, 123456000, time.UTC)
+	logger.SetClock(func() time.Time { return now })
+	logger.Info("first")
+	logger.With(F("user", "jane doe"), F("id", 42)).Info("second a=b")
+	logger.Info("multi\nline")
+
+	r := NewTextReader(&buf, NewParser("app: ", flag))
+	var entries []Entry
+	for {
+		e, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("read %d entries, want 3", len(entries))
+	}
+	e := entries[0]
+	if !e.Time.Equal(now) || e.Seq != 1 || e.Goroutine == 0 || e.File != "parse_test.go" || e.Line == 0 || e.Message != "first" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	e = entries[1]
+	if e.Message != "second" || len(e.Fields) != 3 || e.Fields[0] != F("a", "b") || e.Fields[1] != F("user", "jane doe") || e.Fields[2] != F("id", "42") {
+		t.Errorf("unexpected message %q and fields %v", e.Message, e.Fields)
+	}
+	if e = entries[2]; e.Message != "multi\nline" || e.Seq != 2 {
+		t.Errorf("unexpected multi-line entry %+v", e)
+	}
+}
+
+func TestParserFormats(t *testing.T) {
+	tests := []struct {
+		flag int
+		line string
+		want time.Time
+	}{
+		{LRFC3339, "2009-01-23T01:23:23+08:00 msg", time.Date(2009, 1, 23, 1, 23, 23, 0, time.FixedZone("", 8*3600))},
+		{LEpochMillis, "1232673803123 msg", time.Unix(1232673803, 123e6)},
+		{Ltime | LUTC | Lcallerlast | Lshortfile, "01:23:23 msg (d.go:23)", time.Date(0, 1, 1, 1, 23, 23, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		e, err := NewParser("", tt.flag).Parse(tt.line)
+		if err != nil {
+			t.Errorf("%q: %v", tt.line, err)
+			continue
+		}
+		if !e.Time.Equal(tt.want) || e.Message != "msg" {
+			t.Errorf("%q: got %v %q", tt.line, e.Time, e.Message)
+		}
+	}
+	if _, err := NewParser("", LstdFlags).Parse("not a log line"); err == nil {
+		t.Error("garbage parsed")
+	}
+}
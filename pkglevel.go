@@ -0,0 +1,59 @@
+package aralog
+
+import (
+    "sort"
+    "strings"
+    "sync/atomic"
+)
+
+type packageLevel struct {
+    prefix string
+    level  Level
+}
+
+// SetPackageLevels sets levels for the entries logged from packages, keyed
+// by import path prefix, ex: {"github.com/acme/svc/db": LevelDebug}. A
+// prefix covers the package and its subpackages; the longest matching
+// prefix wins over the level of the logger. nil removes them.
+//
+// The caller of every entry has to be looked up while package levels are
+// set, which costs about as much as the Lshortfile flag.
+func (l *Logger) SetPackageLevels(levels map[string]Level) {
+    var pl []packageLevel
+    min := LevelFatal + 1
+    for prefix, level := range levels {
+        pl = append(pl, packageLevel{strings.TrimSuffix(prefix, "/"), level})
+        if level < min {
+            min = level
+        }
+    }
+    sort.Slice(pl, func(i, j int) bool {
+        return len(pl[i].prefix) > len(pl[j].prefix)
+    })
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.pkgLevels = pl
+    atomic.StoreInt32(&l.pkgMin, int32(min))
+}
+
+// mayLog reports whether an entry at level may be written, by the level
+// of the logger or of a package, or in audit mode. output decides on the
+// package levels and the enabler.
+func (l *Logger) mayLog(level Level) bool {
+    return l.audit || level >= l.Level() || level >= Level(atomic.LoadInt32(&l.pkgMin)) ||
+        atomic.LoadInt32(&l.hasEnabler) != 0
+}
+
+// packageLevel returns the level for entries logged from the function fn,
+// as named by runtime.FuncForPC. l.mu must be held.
+func (l *Logger) packageLevel(fn string) (Level, bool) {
+    for _, pl := range l.pkgLevels {
+        if strings.HasPrefix(fn, pl.prefix) && len(fn) > len(pl.prefix) {
+            if c := fn[len(pl.prefix)]; c == '.' || c == '/' {
+                return pl.level, true
+            }
+        }
+    }
+    return 0, false
+}
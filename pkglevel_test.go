@@ -0,0 +1,26 @@
+package aralog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetPackageLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	logger.SetLevel(LevelError)
+	logger.SetPackageLevels(map[string]Level{"github.com/araframework/aralog": LevelInfo})
+
+	logger.Debug("dropped by package level")
+	logger.Info("kept by package level")
+	if buf.String() != "kept by package level\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.SetPackageLevels(map[string]Level{"github.com/araframework/aral": LevelDebug})
+	logger.Info("dropped, prefix is not a package path")
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
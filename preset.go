@@ -0,0 +1,31 @@
+package aralog
+
+import (
+    "os"
+    "time"
+)
+
+// NewDevelopment creates a Logger for local development: colored console
+// output to stderr with microsecond times and callers, at LevelDebug.
+func NewDevelopment() *Logger {
+    l := NewConsoleLogger(os.Stderr, Lshortfile)
+    l.Encoder().(*ConsoleEncoder).TimeLayout = "15:04:05.000000"
+    l.SetLevel(LevelDebug)
+    return l
+}
+
+// NewProduction creates a Logger for production services: JSON entries with
+// callers, at LevelInfo, to a file at path rolled at 100MB. Repetitive
+// entries below LevelError are sampled: per second, the first 100 with the
+// same message are written, then every 100th.
+func NewProduction(path string) (*Logger, error) {
+    sink, err := NewRollFileSink(path, 100 * 1024 * 1024)
+    if err != nil {
+        return nil, err
+    }
+
+    l := NewSinkLogger(NewSamplingSink(sink, time.Second, 100, 100), "", Lshortfile)
+    l.SetEncoder(NewJSONEncoder())
+    l.SetLevel(LevelInfo)
+    return l, nil
+}
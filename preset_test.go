@@ -0,0 +1,47 @@
+package aralog
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewProduction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	logger, err := NewProduction(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all entries within one sampling tick
+	now := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	logger.SetClock(func() time.Time { return now })
+	logger.Debug("dropped")
+	for i := 0; i < 250; i++ {
+		logger.Info("hot loop")
+	}
+	logger.Close()
+
+	f, _ := os.Open(path)
+	defer f.Close()
+	lines := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); lines++ {
+		var m map[string]interface{}
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil || m["msg"] != "hot loop" {
+			t.Fatalf("unexpected entry %s", sc.Bytes())
+		}
+	}
+	// the first 100, then every 100th: the 200th
+	if lines != 101 {
+		t.Errorf("expected 101 sampled entries, got %d", lines)
+	}
+}
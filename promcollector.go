@@ -0,0 +1,64 @@
+//go:build aralog_prometheus
+// +build aralog_prometheus
+
+// The Prometheus collector is built with the aralog_prometheus tag, so
+// aralog does not depend on the Prometheus client library unless it is
+// used:
+//
+//	go build -tags aralog_prometheus
+
+package aralog
+
+import (
+    "strings"
+    "sync/atomic"
+
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+    promEntriesDesc   = prometheus.NewDesc("aralog_entries_total", "Entries handed to the sinks, by logger and level.", []string{"logger", "level"}, nil)
+    promBytesDesc     = prometheus.NewDesc("aralog_bytes_total", "Bytes of the entries the sinks accepted without error, by logger.", []string{"logger"}, nil)
+    promErrorsDesc    = prometheus.NewDesc("aralog_write_errors_total", "Entries the sinks failed to write, by logger.", []string{"logger"}, nil)
+    promDroppedDesc   = prometheus.NewDesc("aralog_dropped_entries_total", "Entries dropped by sinks, ex: sampled out, by logger.", []string{"logger"}, nil)
+    promLatencyDesc   = prometheus.NewDesc("aralog_write_latency_seconds", "Durations of the sink writes, by logger.", []string{"logger"}, nil)
+    promRotationsDesc = prometheus.NewDesc("aralog_rotations_total", "Log files rolled.", nil, nil)
+)
+
+// promCollector is the prometheus.Collector created by
+// NewPrometheusCollector.
+type promCollector struct{}
+
+// NewPrometheusCollector returns a prometheus.Collector of the metrics
+// MetricsHandler exposes, ex:
+//
+//	prometheus.MustRegister(aralog.NewPrometheusCollector())
+func NewPrometheusCollector() prometheus.Collector {
+    return promCollector{}
+}
+
+func (promCollector) Describe(ch chan<- *prometheus.Desc) {
+    for _, d := range []*prometheus.Desc{promEntriesDesc, promBytesDesc, promErrorsDesc, promDroppedDesc, promLatencyDesc, promRotationsDesc} {
+        ch <- d
+    }
+}
+
+func (promCollector) Collect(ch chan<- prometheus.Metric) {
+    for _, name := range LoggerNames() {
+        l := Lookup(name)
+        if l == nil {
+            continue
+        }
+        s := l.Stats()
+        for level, n := range s.Entries {
+            ch <- prometheus.MustNewConstMetric(promEntriesDesc, prometheus.CounterValue, float64(n), name, strings.ToLower(Level(level).String()))
+        }
+        ch <- prometheus.MustNewConstMetric(promBytesDesc, prometheus.CounterValue, float64(s.Bytes), name)
+        ch <- prometheus.MustNewConstMetric(promErrorsDesc, prometheus.CounterValue, float64(s.WriteErrors), name)
+        ch <- prometheus.MustNewConstMetric(promDroppedDesc, prometheus.CounterValue, float64(s.Dropped), name)
+        lat := s.Latency
+        quantiles := map[float64]float64{0.5: lat.P50.Seconds(), 0.95: lat.P95.Seconds(), 0.99: lat.P99.Seconds()}
+        ch <- prometheus.MustNewConstSummary(promLatencyDesc, lat.Count, lat.Sum.Seconds(), quantiles, name)
+    }
+    ch <- prometheus.MustNewConstMetric(promRotationsDesc, prometheus.CounterValue, float64(atomic.LoadUint64(&rotations)))
+}
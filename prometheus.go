@@ -0,0 +1,105 @@
+package aralog
+
+import (
+    "bufio"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync/atomic"
+    "time"
+)
+
+// MetricsHandler returns an http.Handler exposing the metrics of aralog in
+// the Prometheus text format, to be scraped next to the metrics of the
+// application:
+//
+//	mux.Handle("/metrics/aralog", aralog.MetricsHandler())
+//
+// Entries, bytes, write errors and dropped entries are exposed by
+// registered logger, see Register; file rotations for the whole process. The
+// handler writes the format itself, so aralog does not depend on the
+// Prometheus client library; building with the aralog_prometheus tag adds
+// NewPrometheusCollector to register the same metrics with it.
+func MetricsHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+        b := bufio.NewWriter(w)
+        defer b.Flush()
+
+        type named struct {
+            name  string
+            stats Stats
+        }
+        var loggers []named
+        for _, name := range LoggerNames() {
+            if l := Lookup(name); l != nil {
+                loggers = append(loggers, named{name, l.Stats()})
+            }
+        }
+
+        promHeader(b, "aralog_entries_total", "Entries handed to the sinks, by logger and level.")
+        for _, l := range loggers {
+            for level, n := range l.stats.Entries {
+                promSample(b, "aralog_entries_total", n, "logger", l.name, "level", strings.ToLower(Level(level).String()))
+            }
+        }
+        promHeader(b, "aralog_bytes_total", "Bytes of the entries the sinks accepted without error, by logger.")
+        for _, l := range loggers {
+            promSample(b, "aralog_bytes_total", l.stats.Bytes, "logger", l.name)
+        }
+        promHeader(b, "aralog_write_errors_total", "Entries the sinks failed to write, by logger.")
+        for _, l := range loggers {
+            promSample(b, "aralog_write_errors_total", l.stats.WriteErrors, "logger", l.name)
+        }
+        promHeader(b, "aralog_dropped_entries_total", "Entries dropped by sinks, ex: sampled out, by logger.")
+        for _, l := range loggers {
+            promSample(b, "aralog_dropped_entries_total", l.stats.Dropped, "logger", l.name)
+        }
+        b.WriteString("# HELP aralog_write_latency_seconds Durations of the sink writes, by logger.\n")
+        b.WriteString("# TYPE aralog_write_latency_seconds summary\n")
+        for _, l := range loggers {
+            lat := l.stats.Latency
+            for _, q := range []struct {
+                q string
+                d time.Duration
+            }{{"0.5", lat.P50}, {"0.95", lat.P95}, {"0.99", lat.P99}} {
+                b.WriteString("aralog_write_latency_seconds{logger=\"" + promEscaper.Replace(l.name) + "\",quantile=\"" + q.q + "\"} ")
+                b.WriteString(strconv.FormatFloat(q.d.Seconds(), 'g', -1, 64) + "\n")
+            }
+            b.WriteString("aralog_write_latency_seconds_sum{logger=\"" + promEscaper.Replace(l.name) + "\"} ")
+            b.WriteString(strconv.FormatFloat(lat.Sum.Seconds(), 'g', -1, 64) + "\n")
+            promSample(b, "aralog_write_latency_seconds_count", lat.Count, "logger", l.name)
+        }
+        promHeader(b, "aralog_rotations_total", "Log files rolled.")
+        promSample(b, "aralog_rotations_total", atomic.LoadUint64(&rotations))
+    })
+}
+
+func promHeader(b *bufio.Writer, name, help string) {
+    b.WriteString("# HELP " + name + " " + help + "\n")
+    b.WriteString("# TYPE " + name + " counter\n")
+}
+
+// promSample writes one sample of name, labels are given as name, value pairs.
+func promSample(b *bufio.Writer, name string, v uint64, labels ...string) {
+    b.WriteString(name)
+    for i := 0; i + 1 < len(labels); i += 2 {
+        if i == 0 {
+            b.WriteByte('{')
+        } else {
+            b.WriteByte(',')
+        }
+        b.WriteString(labels[i])
+        b.WriteString(`="`)
+        b.WriteString(promEscaper.Replace(labels[i + 1]))
+        b.WriteByte('"')
+        if i + 3 >= len(labels) {
+            b.WriteByte('}')
+        }
+    }
+    b.WriteByte(' ')
+    b.WriteString(strconv.FormatUint(v, 10))
+    b.WriteByte('\n')
+}
+
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
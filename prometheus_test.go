@@ -0,0 +1,35 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	db := New(ioutil.Discard, "", 0)
+	Register(`test."db"`, db)
+	defer Unregister(`test."db"`)
+	db.Info("one")
+	db.With(F("table", "users")).Info("two")
+	NewSinkLogger(&failingSink{}, "", 0).Error("not registered")
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE aralog_entries_total counter\n",
+		`aralog_entries_total{logger="test.\"db\"",level="info"} 2` + "\n",
+		`aralog_bytes_total{logger="test.\"db\""} 20` + "\n",
+		`aralog_write_errors_total{logger="test.\"db\""} 0` + "\n",
+		`aralog_dropped_entries_total{logger="test.\"db\""} 0` + "\n",
+		`aralog_write_latency_seconds_sum{logger="test.\"db\""} `,
+		`aralog_write_latency_seconds_count{logger="test.\"db\""} 2` + "\n",
+		"\naralog_rotations_total ",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("%q missing from\n%s", want, body)
+		}
+	}
+}
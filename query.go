@@ -0,0 +1,223 @@
+package aralog
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// ParseJSONLine parses a line written by the JSONEncoder. The keys time,
+// level, caller and msg fill the entry, the other keys are its fields, in
+// order; numbers are int64 if integral, float64 otherwise. Times with the
+// layout of a custom JSONEncoder.TimeLayout are left zero.
+func ParseJSONLine(line string) (Entry, error) {
+    e := Entry{Level: LevelInfo, Formatted: []byte(strings.TrimSuffix(line, "\n") + "\n")}
+    dec := json.NewDecoder(strings.NewReader(line))
+    dec.UseNumber()
+    if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+        return Entry{}, &ParseError{line, "not a JSON object"}
+    }
+    for dec.More() {
+        tok, err := dec.Token()
+        if err != nil {
+            return Entry{}, &ParseError{line, err.Error()}
+        }
+        key, _ := tok.(string)
+        var v interface{}
+        if err := dec.Decode(&v); err != nil {
+            return Entry{}, &ParseError{line, err.Error()}
+        }
+        if !e.setStandard(key, v) {
+            e.Fields = append(e.Fields, F(key, jsonValue(v)))
+        }
+    }
+    return e, nil
+}
+
+// setStandard sets the part of e named by the key of a JSON or logfmt line,
+// and reports whether key names one.
+func (e *Entry) setStandard(key string, v interface{}) bool {
+    s, ok := v.(string)
+    if !ok {
+        return false
+    }
+    switch key {
+    case "time", "ts":
+        e.Time, _ = time.Parse(time.RFC3339Nano, s)
+    case "level", "lvl":
+        level, err := ParseLevel(s)
+        if err != nil {
+            return false
+        }
+        e.Level = level
+    case "msg", "message":
+        e.Message = s
+    case "caller":
+        if !parseCaller(s, e) {
+            return false
+        }
+    default:
+        return false
+    }
+    return true
+}
+
+func jsonValue(v interface{}) interface{} {
+    if n, ok := v.(json.Number); ok {
+        if i, err := n.Int64(); err == nil {
+            return i
+        }
+        f, _ := n.Float64()
+        return f
+    }
+    return v
+}
+
+// ParseLogfmtLine parses a logfmt line: key=value pairs separated by
+// spaces, values quoted if needed. The keys time or ts, level or lvl, msg or
+// message and caller fill the entry, the other keys are its fields, with
+// string values.
+func ParseLogfmtLine(line string) (Entry, error) {
+    line = strings.TrimSuffix(line, "\n")
+    e := Entry{Level: LevelInfo, Formatted: []byte(line + "\n")}
+    s := strings.TrimSpace(line)
+    for len(s) > 0 {
+        i := strings.IndexByte(s, '=')
+        if i <= 0 || strings.IndexByte(s[:i], ' ') >= 0 {
+            return Entry{}, &ParseError{line, "expected key=value"}
+        }
+        key, value := s[:i], ""
+        s = s[i + 1:]
+        if strings.HasPrefix(s, `"`) {
+            q, err := strconv.QuotedPrefix(s)
+            if err != nil {
+                return Entry{}, &ParseError{line, err.Error()}
+            }
+            value, _ = strconv.Unquote(q)
+            s = s[len(q):]
+        } else if j := strings.IndexByte(s, ' '); j >= 0 {
+            value, s = s[:j], s[j:]
+        } else {
+            value, s = s, ""
+        }
+        s = strings.TrimLeft(s, " ")
+        if !e.setStandard(key, value) {
+            e.Fields = append(e.Fields, F(key, value))
+        }
+    }
+    return e, nil
+}
+
+// lineReader is an EntryReader decoding one entry per line.
+type lineReader struct {
+    r      *bufio.Reader
+    decode func(string) (Entry, error)
+}
+
+// NewJSONReader creates an EntryReader parsing the lines of r written by the
+// JSONEncoder, see ParseJSONLine.
+func NewJSONReader(r io.Reader) EntryReader {
+    return &lineReader{bufio.NewReader(r), ParseJSONLine}
+}
+
+// NewLogfmtReader creates an EntryReader parsing the logfmt lines of r, see
+// ParseLogfmtLine.
+func NewLogfmtReader(r io.Reader) EntryReader {
+    return &lineReader{bufio.NewReader(r), ParseLogfmtLine}
+}
+
+// Read returns the entry of the next non-empty line. A line which does not
+// parse is an error, reading can go on with the following line.
+func (l *lineReader) Read() (Entry, error) {
+    for {
+        line, err := l.r.ReadString('\n')
+        if len(strings.TrimSpace(line)) > 0 {
+            return l.decode(line)
+        }
+        if err != nil {
+            return Entry{}, err
+        }
+    }
+}
+
+// Query selects entries by time range, level, fields and a rule. The zero
+// Query selects all entries.
+type Query struct {
+    From   time.Time         // earliest time, inclusive, unbounded if zero
+    To     time.Time         // latest time, exclusive, unbounded if zero
+    Level  Level             // lowest level
+    Fields map[string]string // fields which must be present with these values, in their fmt.Sprint form
+    Rule   *Rule             // rule the entries must match, see CompileRule, may be nil
+}
+
+// Match reports whether e is selected by q.
+func (q *Query) Match(e Entry) bool {
+    if e.Level < q.Level || (!q.From.IsZero() && e.Time.Before(q.From)) || (!q.To.IsZero() && !e.Time.Before(q.To)) {
+        return false
+    }
+    for key, want := range q.Fields {
+        found := false
+        for _, f := range e.Fields {
+            if f.Key == key && fmt.Sprint(f.Value) == want {
+                found = true
+                break
+            }
+        }
+        if !found {
+            return false
+        }
+    }
+    return q.Rule == nil || q.Rule.Match(e)
+}
+
+// Run calls fn with the entries of r selected by q, until r is exhausted or
+// fn returns an error, which is returned. Lines which do not parse are
+// skipped.
+func (q *Query) Run(r EntryReader, fn func(Entry) error) error {
+    for {
+        e, err := r.Read()
+        if err == io.EOF {
+            return nil
+        }
+        if _, ok := err.(*ParseError); ok {
+            continue
+        }
+        if err != nil {
+            return err
+        }
+        if q.Match(e) {
+            if err := fn(e); err != nil {
+                return err
+            }
+        }
+    }
+}
+
+// QueryFile returns the entries of the file at path selected by q. The file
+// is read as JSON if its first line is an object, as logfmt otherwise.
+func QueryFile(path string, q Query) ([]Entry, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    br := bufio.NewReader(f)
+    head, _ := br.Peek(1)
+    var r EntryReader = &lineReader{br, ParseLogfmtLine}
+    if bytes.HasPrefix(head, []byte("{")) {
+        r = &lineReader{br, ParseJSONLine}
+    }
+    var entries []Entry
+    err = q.Run(r, func(e Entry) error {
+        entries = append(entries, e)
+        return nil
+    })
+    return entries, err
+}
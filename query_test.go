@@ -0,0 +1,88 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseJSONLine(t *testing.T) {
+	e, err := ParseJSONLine(`{"time":"2009-01-23T01:23:23.123456Z","level":"WARN","caller":"d.go:23","msg":"slow","ms":1200,"ratio":0.5,"user":"jane"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2009, 1, 23, 1, 23, 23, 123456000, time.UTC)
+	if !e.Time.Equal(want) || e.Level != LevelWarn || e.File != "d.go" || e.Line != 23 || e.Message != "slow" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if len(e.Fields) != 3 || e.Fields[0] != F("ms", int64(1200)) || e.Fields[1] != F("ratio", 0.5) || e.Fields[2] != F("user", "jane") {
+		t.Errorf("unexpected fields %v", e.Fields)
+	}
+	if _, err := ParseJSONLine("plain text"); err == nil {
+		t.Error("plain text parsed")
+	}
+}
+
+func TestParseLogfmtLine(t *testing.T) {
+	e, err := ParseLogfmtLine(`ts=2009-01-23T01:23:23Z lvl=error msg="payment failed" order=7 reason="card \"declined\""`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Level != LevelError || e.Message != "payment failed" || e.Time.Year() != 2009 {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if len(e.Fields) != 2 || e.Fields[0] != F("order", "7") || e.Fields[1] != F("reason", `card "declined"`) {
+		t.Errorf("unexpected fields %v", e.Fields)
+	}
+}
+
+func TestQueryFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(f, "", 0)
+	logger.SetEncoder(NewJSONEncoder())
+	start := time.Date(2009, 1, 23, 1, 0, 0, 0, time.UTC)
+	now := start
+	logger.SetClock(func() time.Time { now = now.Add(time.Minute); return now }) // start at +1m, entries from +2m
+	logger.With(F("tenant", "a")).Error("one")
+	logger.With(F("tenant", "b")).Error("two")
+	logger.With(F("tenant", "a")).Info("three")
+	logger.With(F("tenant", "a")).Error("four")
+	f.WriteString("garbage\n")
+	logger.With(F("tenant", "a")).Error("five")
+	f.Close()
+
+	entries, err := QueryFile(path, Query{
+		From:   start.Add(3 * time.Minute),
+		To:     start.Add(6 * time.Minute),
+		Level:  LevelError,
+		Fields: map[string]string{"tenant": "a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, ",") != "four" {
+		t.Errorf("selected %v", got)
+	}
+
+	entries, _ = QueryFile(path, Query{Rule: MustCompileRule(`message =~ "^f"`)})
+	if len(entries) != 2 {
+		t.Errorf("rule selected %d entries, want 2", len(entries))
+	}
+}
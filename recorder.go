@@ -0,0 +1,46 @@
+package aralog
+
+import (
+    "sync/atomic"
+    "time"
+)
+
+// A MetricsRecorder receives the measurements of aralog as they are taken,
+// to forward them to a metrics API. Its methods are called on the logging
+// path and must be fast and safe for concurrent use. Building with the
+// aralog_otel tag adds NewOTelRecorder, recording to OpenTelemetry metrics.
+type MetricsRecorder interface {
+    // RecordEntry is called after an entry was handed to the sink of a
+    // logger, with the duration of the write and its error.
+    RecordEntry(logger string, level Level, d time.Duration, err error)
+    // RecordDropped is called when a sink drops an entry, ex: sampled out.
+    RecordDropped(logger string, level Level)
+}
+
+type recorderBox struct {
+    r MetricsRecorder
+}
+
+var recorder atomic.Value // recorderBox
+
+// SetMetricsRecorder sets the recorder of the measurements of all loggers,
+// nil stops recording.
+func SetMetricsRecorder(r MetricsRecorder) {
+    recorder.Store(recorderBox{r})
+}
+
+func metricsRecorder() MetricsRecorder {
+    b, _ := recorder.Load().(recorderBox)
+    return b.r
+}
+
+// countDropped counts an entry dropped by a sink.
+func countDropped(e Entry) {
+    atomic.AddUint64(&totals.dropped, 1)
+    if e.stats != nil {
+        atomic.AddUint64(&e.stats.dropped, 1)
+    }
+    if r := metricsRecorder(); r != nil {
+        r.RecordDropped(e.Logger, e.Level)
+    }
+}
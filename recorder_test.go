@@ -0,0 +1,43 @@
+package aralog
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type testRecorder struct {
+	mu      sync.Mutex
+	records map[string]int
+	dropped int
+}
+
+func (r *testRecorder) RecordEntry(logger string, level Level, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[logger+"/"+level.String()]++
+}
+
+func (r *testRecorder) RecordDropped(logger string, level Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropped++
+}
+
+func TestSetMetricsRecorder(t *testing.T) {
+	r := &testRecorder{records: map[string]int{}}
+	SetMetricsRecorder(r)
+	defer SetMetricsRecorder(nil)
+
+	logger := NewSinkLogger(NewSamplingSink(NewDiscardSink(), time.Hour, 1, 0), "", 0)
+	Register("test.otel", logger)
+	defer Unregister("test.otel")
+	logger.Info("repeated")
+	logger.Info("repeated")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.records["test.otel/INFO"] != 2 || r.dropped != 1 {
+		t.Errorf("got records %v, dropped %d", r.records, r.dropped)
+	}
+}
@@ -0,0 +1,40 @@
+package aralog
+
+import (
+    "runtime/debug"
+)
+
+// RecoverAndLog recovers a panic and logs its value with the stack at
+// LevelError, ex: in a goroutine which must not crash the process:
+//
+//	defer aralog.RecoverAndLog(logger)
+//
+// It must be deferred directly, recover has no effect otherwise.
+func RecoverAndLog(l *Logger) {
+    if p := recover(); p != nil {
+        logPanic(l, p)
+    }
+}
+
+// RecoverAndRepanic logs a panic like RecoverAndLog, flushes the logger so
+// the entry is not lost, and panics again with the same value.
+func RecoverAndRepanic(l *Logger) {
+    if p := recover(); p != nil {
+        logPanic(l, p)
+        l.Flush()
+        panic(p)
+    }
+}
+
+// Go runs fn in a new goroutine, logging and recovering a panic of fn
+// with RecoverAndLog.
+func Go(l *Logger, fn func()) {
+    go func() {
+        defer RecoverAndLog(l)
+        fn()
+    }()
+}
+
+func logPanic(l *Logger, p interface{}) {
+    l.With(F("stack", string(debug.Stack()))).Error("panic: %v", p)
+}
@@ -0,0 +1,44 @@
+package aralog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecoverAndLog(t *testing.T) {
+	ring := NewRingSink(4)
+	logger := NewSinkLogger(ring, "", 0)
+
+	done := make(chan struct{})
+	Go(logger, func() {
+		defer close(done)
+		panic("boom")
+	})
+	<-done
+	// the panic is logged after fn returned
+	for len(ring.Entries()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	func() {
+		defer func() {
+			if p := recover(); p != "again" {
+				t.Errorf("expected the panic to continue, got %v", p)
+			}
+		}()
+		defer RecoverAndRepanic(logger)
+		panic("again")
+	}()
+
+	entries := ring.Entries()
+	if len(entries) != 2 || entries[1].Message != "panic: again" {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+	if entries[0].Message != "panic: boom" || entries[0].Level != LevelError {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+	if stack := entries[0].Fields[0].Value.(string); !strings.Contains(stack, "TestRecoverAndLog") {
+		t.Errorf("stack without the panicking function:\n%s", stack)
+	}
+}
@@ -0,0 +1,106 @@
+package aralog
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "regexp"
+    "strings"
+)
+
+// Redaction replaces the parts of messages matching Pattern by Mask, which
+// may refer to submatches as in regexp.Regexp.ReplaceAllString.
+type Redaction struct {
+    Pattern *regexp.Regexp
+    Mask    string
+}
+
+// Common redactions of personal data.
+var (
+    RedactEmails      = Redaction{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`), "[EMAIL]"}
+    RedactCreditCards = Redaction{regexp.MustCompile(`\b\d{4}[ -]?\d{4}[ -]?\d{4}[ -]?\d{1,7}\b`), "[CARD]"}
+    RedactSSNs        = Redaction{regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), "[SSN]"}
+)
+
+// redactionNames maps the names used in configurations to the redactions.
+var redactionNames = map[string]Redaction{
+    "email": RedactEmails,
+    "card":  RedactCreditCards,
+    "ssn":   RedactSSNs,
+}
+
+// SetRedactions sets the redactions applied in order to every message of
+// the logger before it is encoded, so the data they match never reaches a
+// sink. No redactions removes them, ex:
+//
+//	l.SetRedactions(aralog.RedactEmails, aralog.RedactCreditCards)
+func (l *Logger) SetRedactions(r ...Redaction) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.redact = append([]Redaction(nil), r...)
+}
+
+// redact applies the redactions to s.
+func redact(s string, redactions []Redaction) string {
+    for _, r := range redactions {
+        s = r.Pattern.ReplaceAllString(s, r.Mask)
+    }
+    return s
+}
+
+// FieldMask is how the value of a redacted field is written.
+type FieldMask int
+
+const (
+    MaskRedact FieldMask = iota // the value is replaced by [REDACTED]
+    MaskHash                    // the value is replaced by sha256: and 16 hex digits of its hash, so equal values can still be correlated
+)
+
+// SensitiveKeys are field keys which usually hold secrets.
+var SensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "api_key", "apikey"}
+
+// SetRedactedFields masks the values of the fields with the given keys,
+// case insensitive, in every entry of the logger, whether they are bound
+// by With or come from a context, ex:
+//
+//	l.SetRedactedFields(aralog.MaskRedact, aralog.SensitiveKeys...)
+//	l.SetRedactedFields(aralog.MaskHash, "email")
+//
+// Keys set by an earlier call keep their mask unless given again.
+func (l *Logger) SetRedactedFields(mask FieldMask, keys ...string) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    masks := map[string]FieldMask{}
+    for k, m := range l.fieldMasks {
+        masks[k] = m
+    }
+    for _, k := range keys {
+        masks[strings.ToLower(k)] = mask
+    }
+    l.fieldMasks = masks
+}
+
+// maskFields returns fields with the values of the redacted keys masked,
+// fields itself if none is redacted.
+func maskFields(fields []Field, masks map[string]FieldMask) []Field {
+    var masked []Field
+    for i, f := range fields {
+        mask, ok := masks[strings.ToLower(f.Key)]
+        if !ok {
+            continue
+        }
+        if masked == nil {
+            masked = append([]Field(nil), fields...)
+        }
+        if mask == MaskHash {
+            sum := sha256.Sum256([]byte(fmt.Sprint(f.Value)))
+            masked[i].Value = "sha256:" + hex.EncodeToString(sum[:8])
+        } else {
+            masked[i].Value = "[REDACTED]"
+        }
+    }
+    if masked == nil {
+        return fields
+    }
+    return masked
+}
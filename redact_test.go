@@ -0,0 +1,42 @@
+package aralog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestSetRedactions(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	logger.SetRedactions(RedactEmails, RedactCreditCards, RedactSSNs)
+
+	logger.Info("order by jane.doe@example.co.uk paid with 4111 1111 1111 1111, ssn 078-05-1120, order 12345")
+	want := "order by [EMAIL] paid with [CARD], ssn [SSN], order 12345\n"
+	if buf.String() != want {
+		t.Errorf("got %q", buf.String())
+	}
+}
+
+func TestSetRedactedFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	logger.SetEncoder(NewJSONEncoder())
+	logger.SetRedactedFields(MaskRedact, SensitiveKeys...)
+	logger.SetRedactedFields(MaskHash, "email")
+
+	logger.With(F("Password", "hunter2"), F("user", "jane"), F("email", "jane@example.com")).Info("login")
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "jane@example.com") {
+		t.Fatalf("secret written: %s", out)
+	}
+	if !strings.Contains(out, `"Password":"[REDACTED]"`) || !strings.Contains(out, `"user":"jane"`) {
+		t.Errorf("got %s", out)
+	}
+	sum := sha256.Sum256([]byte("jane@example.com"))
+	if !strings.Contains(out, `"email":"sha256:`+hex.EncodeToString(sum[:8])+`"`) {
+		t.Errorf("email not hashed: %s", out)
+	}
+}
@@ -0,0 +1,79 @@
+package aralog
+
+import (
+    "sort"
+    "sync"
+)
+
+// the registry of named loggers
+var (
+    registryMu sync.RWMutex
+    registry   = map[string]*Logger{}
+)
+
+// Register registers l under name, replacing any logger registered under
+// the same name, and sets the name of l. Registered loggers can be managed
+// at runtime, ex: by LevelHandler.
+func Register(name string, l *Logger) {
+    l.mu.Lock()
+    l.name = name
+    l.mu.Unlock()
+
+    registryMu.Lock()
+    defer registryMu.Unlock()
+    registry[name] = l
+}
+
+// Unregister removes the logger registered under name.
+func Unregister(name string) {
+    registryMu.Lock()
+    defer registryMu.Unlock()
+    delete(registry, name)
+}
+
+// Lookup returns the logger registered under name, nil if there is none.
+func Lookup(name string) *Logger {
+    registryMu.RLock()
+    defer registryMu.RUnlock()
+    return registry[name]
+}
+
+// LoggerNames returns the names of the registered loggers, sorted.
+func LoggerNames() []string {
+    registryMu.RLock()
+    defer registryMu.RUnlock()
+
+    names := make([]string, 0, len(registry))
+    for name := range registry {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// registeredLoggers returns the registered loggers.
+func registeredLoggers() []*Logger {
+    registryMu.RLock()
+    defer registryMu.RUnlock()
+
+    loggers := make([]*Logger, 0, len(registry))
+    for _, l := range registry {
+        loggers = append(loggers, l)
+    }
+    return loggers
+}
+
+// SetGlobalLevel sets the level of every registered logger at once, ex: to
+// quiet or debug a whole process in an emergency.
+func SetGlobalLevel(level Level) {
+    for _, l := range registeredLoggers() {
+        l.SetLevel(level)
+    }
+}
+
+// Name returns the name the logger is registered under, empty if it is not.
+func (l *Logger) Name() string {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.name
+}
@@ -0,0 +1,24 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestSetGlobalLevel(t *testing.T) {
+	a, b := New(ioutil.Discard, "", 0), New(ioutil.Discard, "", 0)
+	b.SetLevel(LevelWarn)
+	Register("test.a", a)
+	Register("test.b", b)
+	defer Unregister("test.a")
+	defer Unregister("test.b")
+	unregistered := New(ioutil.Discard, "", 0)
+
+	SetGlobalLevel(LevelError)
+	if a.Level() != LevelError || b.Level() != LevelError || unregistered.Level() != LevelDebug {
+		t.Errorf("unexpected levels %v %v %v", a.Level(), b.Level(), unregistered.Level())
+	}
+	if Lookup("test.a") != a || a.Name() != "test.a" {
+		t.Error("expected test.a to be registered")
+	}
+}
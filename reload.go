@@ -0,0 +1,119 @@
+package aralog
+
+import (
+    "os"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// Reconfigure applies c to the logger at runtime: level, package levels,
+// flags, prefix, time format, location, message length, encoding, filter,
+// redactions, UTF-8 mode and outputs are replaced at once, so no entry is
+// written with half of the new settings. If c has a Name, l is registered
+// under it. The previous outputs are closed, loggers derived from l by With
+// keep using them and must be recreated.
+func (l *Logger) Reconfigure(c *Config) error {
+    n, err := c.build()
+    if err != nil {
+        return err
+    }
+
+    l.mu.Lock()
+    old := l.sink
+    l.sink = n.sink
+    l.flag = n.flag
+    l.prefix = n.prefix
+    l.layout = n.layout
+    l.loc = n.loc
+    l.maxlen = n.maxlen
+    l.enc = n.enc
+    l.filter = n.filter
+    l.rule = n.rule
+    l.redact = n.redact
+    l.fieldMasks = n.fieldMasks
+    l.keepSecrets = n.keepSecrets
+    l.utf8Mode = n.utf8Mode
+    l.pkgLevels = n.pkgLevels
+    atomic.StoreInt32(&l.pkgMin, atomic.LoadInt32(&n.pkgMin))
+    l.SetLevel(n.Level())
+    l.mu.Unlock()
+
+    if len(c.Name) > 0 {
+        Register(c.Name, l)
+    }
+    return old.Close()
+}
+
+// ConfigWatcher reloads a config file into a Logger whenever it changes.
+type ConfigWatcher struct {
+    logger   *Logger
+    path     string
+    modTime  time.Time
+    size     int64
+    stop     chan struct{}
+    stopOnce sync.Once
+
+    // OnError is called with errors reading or applying the config file,
+    // they are logged to the meta logger if it is nil, see SetMetaLogger.
+    // The previous configuration stays in effect.
+    OnError func(error)
+}
+
+// WatchConfigFile polls the config file at path every interval and applies
+// it to l by Reconfigure whenever its modification time or size changes.
+// The file is not applied initially, l is expected to be built from it.
+func WatchConfigFile(l *Logger, path string, interval time.Duration) (*ConfigWatcher, error) {
+    fi, err := os.Stat(path)
+    if err != nil {
+        return nil, err
+    }
+
+    w := &ConfigWatcher{logger: l, path: path, modTime: fi.ModTime(), size: fi.Size(), stop: make(chan struct{})}
+    go w.run(interval)
+    return w, nil
+}
+
+func (w *ConfigWatcher) run(interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-w.stop:
+            return
+        case <-ticker.C:
+            if err := w.check(); err != nil {
+                if w.OnError != nil {
+                    w.OnError(err)
+                } else {
+                    internalError("reload config: %v", err)
+                }
+            }
+        }
+    }
+}
+
+// check applies the config file if it changed since the last check.
+func (w *ConfigWatcher) check() error {
+    fi, err := os.Stat(w.path)
+    if err != nil {
+        return err
+    }
+    if fi.ModTime().Equal(w.modTime) && fi.Size() == w.size {
+        return nil
+    }
+    w.modTime, w.size = fi.ModTime(), fi.Size()
+
+    c, err := LoadConfig(w.path)
+    if err != nil {
+        return err
+    }
+    return w.logger.Reconfigure(c)
+}
+
+// Stop stops watching the config file.
+func (w *ConfigWatcher) Stop() {
+    w.stopOnce.Do(func() {
+        close(w.stop)
+    })
+}
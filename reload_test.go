@@ -0,0 +1,73 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "aralog.json")
+	ioutil.WriteFile(path, []byte(`{"level": "error", "outputs": [{"type": "stderr"}]}`), 0600)
+	logger, err := NewFromConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := WatchConfigFile(logger, path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	errs := make(chan error, 1)
+	w.OnError = func(err error) { errs <- err }
+
+	ioutil.WriteFile(path, []byte(`{"level": "debug", "outputs": [{"type": "stderr"}], "flags": []}`), 0600)
+	for i := 0; i < 200 && logger.Level() != LevelDebug; i++ {
+		select {
+		case err := <-errs:
+			t.Fatal(err)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	if logger.Level() != LevelDebug || logger.Flags() != 0 {
+		t.Errorf("config not reloaded: level %v, flags %d", logger.Level(), logger.Flags())
+	}
+}
+
+func TestReconfigureNamed(t *testing.T) {
+	c := &Config{Name: "reload-test", Level: "warn", Flags: []string{}, Outputs: []OutputConfig{{Type: "stderr"}}}
+	logger, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Unregister("reload-test")
+
+	c.Location = "Asia/Tokyo"
+	c.MaxMessageLength = 10
+	if err := logger.Reconfigure(c); err != nil {
+		t.Fatal(err)
+	}
+	if Lookup("reload-test") != logger {
+		t.Fatal("reconfigured logger not registered")
+	}
+	SetGlobalLevel(LevelDebug)
+	if logger.Level() != LevelDebug {
+		t.Errorf("level %v after SetGlobalLevel, want DEBUG", logger.Level())
+	}
+
+	logger.mu.Lock()
+	loc, maxlen := logger.loc, logger.maxlen
+	logger.mu.Unlock()
+	if loc == nil || loc.String() != "Asia/Tokyo" || maxlen != 10 {
+		t.Errorf("location %v, max message length %d not reloaded", loc, maxlen)
+	}
+}
@@ -0,0 +1,41 @@
+package aralog
+
+import (
+    "io"
+)
+
+// OriginalTimeKey is the key of the field Replay keeps the time of the
+// replayed entries in.
+const OriginalTimeKey = "original_time"
+
+// Replay logs the entries of r again through l, with its level, filters,
+// encoder and sink, for instance to backfill a new collector from old
+// files. The replayed entries get the current time, their own is kept in
+// an OriginalTimeKey field, RFC3339 with microseconds. l should not have
+// caller flags, the callers would be those of Replay. Lines of r which do
+// not parse are skipped. Replay stops at the first error writing to the
+// sink and returns the number of entries logged.
+func (l *Logger) Replay(r EntryReader) (int, error) {
+    n := 0
+    for {
+        e, err := r.Read()
+        if err == io.EOF {
+            return n, nil
+        }
+        if _, ok := err.(*ParseError); ok {
+            continue
+        }
+        if err != nil {
+            return n, err
+        }
+        if !l.Enabled(e.Level) {
+            continue
+        }
+
+        fields := append(e.Fields[:len(e.Fields):len(e.Fields)], F(OriginalTimeKey, e.Time.Format(rfc3339Micro)))
+        if err := l.outputFields(2, e.Level, e.Message, fields); err != nil {
+            return n, err
+        }
+        n++
+    }
+}
@@ -0,0 +1,44 @@
+package aralog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	in := `{"time":"2009-01-23T01:23:23Z","level":"DEBUG","msg":"noise"}` + "\n" +
+		`{"time":"2009-01-23T01:23:24Z","level":"ERROR","msg":"failed","order":7}` + "\n" +
+		"garbage\n" +
+		`{"time":"2009-01-23T01:23:25Z","level":"INFO","msg":"done"}` + "\n"
+
+	ring := NewRingSink(10)
+	l := NewSinkLogger(ring, "", 0)
+	l.SetLevel(LevelInfo)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.SetClock(func() time.Time { return now })
+
+	n, err := l.Replay(NewJSONReader(strings.NewReader(in)))
+	if err != nil || n != 2 {
+		t.Fatalf("replayed %d entries, %v", n, err)
+	}
+	entries := ring.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	e := entries[0]
+	if !e.Time.Equal(now) || e.Level != LevelError || e.Message != "failed" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if len(e.Fields) != 2 || e.Fields[0] != F("order", int64(7)) || e.Fields[1] != F(OriginalTimeKey, "2009-01-23T01:23:24.000000Z") {
+		t.Errorf("unexpected fields %v", e.Fields)
+	}
+	if got := string(entries[1].Formatted); got != "done original_time=2009-01-23T01:23:25.000000Z\n" {
+		t.Errorf("formatted %q", got)
+	}
+
+	failing := NewSinkLogger(&failingSink{}, "", 0)
+	if n, err := failing.Replay(NewJSONReader(strings.NewReader(in))); err == nil || n != 0 {
+		t.Errorf("replay to a failing sink: %d entries, %v", n, err)
+	}
+}
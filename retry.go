@@ -0,0 +1,73 @@
+package aralog
+
+import (
+    "errors"
+    "math/rand"
+    "net"
+    "time"
+)
+
+// RetryPolicy describes how network sinks retry a failed delivery:
+// up to MaxAttempts tries, sleeping an exponentially growing, jittered
+// backoff between them, and only for errors Retryable accepts.
+type RetryPolicy struct {
+    MaxAttempts    int              // total tries, including the first one
+    InitialBackoff time.Duration    // sleep before the second try
+    MaxBackoff     time.Duration    // upper bound of the sleep between tries
+    Jitter         float64          // randomize each sleep by +/- this fraction, 0 - 1
+    Retryable      func(error) bool // nil means IsRetryable
+}
+
+// DefaultRetryPolicy is a reasonable policy for remote collectors.
+var DefaultRetryPolicy = RetryPolicy{
+    MaxAttempts:    3,
+    InitialBackoff: 100 * time.Millisecond,
+    MaxBackoff:     2 * time.Second,
+    Jitter:         0.2,
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, or the
+// attempts are exhausted. The last error is returned.
+func (p *RetryPolicy) Do(fn func() error) error {
+    retryable := p.Retryable
+    if retryable == nil {
+        retryable = IsRetryable
+    }
+
+    backoff := p.InitialBackoff
+    var err error
+    for attempt := 1; ; attempt++ {
+        err = fn()
+        if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+            return err
+        }
+
+        time.Sleep(p.jitter(backoff))
+        backoff *= 2
+        if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+            backoff = p.MaxBackoff
+        }
+    }
+}
+
+func (p *RetryPolicy) jitter(d time.Duration) time.Duration {
+    if p.Jitter <= 0 || d <= 0 {
+        return d
+    }
+    delta := (rand.Float64() * 2 - 1) * p.Jitter * float64(d)
+    return d + time.Duration(delta)
+}
+
+// IsRetryable reports whether err is a transient network error worth
+// retrying: timeouts, refused or reset connections and broken pipes.
+func IsRetryable(err error) bool {
+    if err == nil {
+        return false
+    }
+
+    var ne net.Error
+    if errors.As(err, &ne) && ne.Timeout() {
+        return true
+    }
+    return isConnError(err)
+}
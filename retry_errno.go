@@ -0,0 +1,17 @@
+//go:build !plan9
+// +build !plan9
+
+package aralog
+
+import (
+    "errors"
+    "syscall"
+)
+
+// isConnError reports whether err is a refused or reset connection or a
+// broken pipe.
+func isConnError(err error) bool {
+    return errors.Is(err, syscall.ECONNREFUSED) ||
+        errors.Is(err, syscall.ECONNRESET) ||
+        errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,21 @@
+//go:build !plan9
+// +build !plan9
+
+package aralog
+
+import (
+	"fmt"
+	"syscall"
+	"testing"
+)
+
+func TestIsRetryableErrno(t *testing.T) {
+	for _, err := range []error{syscall.ECONNREFUSED, syscall.ECONNRESET, fmt.Errorf("write: %w", syscall.EPIPE)} {
+		if !IsRetryable(err) {
+			t.Errorf("%v not retryable", err)
+		}
+	}
+	if IsRetryable(syscall.EACCES) {
+		t.Error("EACCES retryable")
+	}
+}
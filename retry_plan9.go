@@ -0,0 +1,17 @@
+//go:build plan9
+// +build plan9
+
+package aralog
+
+import (
+    "strings"
+)
+
+// isConnError reports whether err is a refused or reset connection or a
+// broken pipe. Plan 9 has no error numbers, its errors are matched by text.
+func isConnError(err error) bool {
+    s := err.Error()
+    return strings.Contains(s, "connection refused") ||
+        strings.Contains(s, "connection reset") ||
+        strings.Contains(s, "hungup")
+}
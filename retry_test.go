@@ -0,0 +1,30 @@
+package aralog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	calls := 0
+	err := p.Do(func() error {
+		calls++
+		return context.DeadlineExceeded
+	})
+	if err != context.DeadlineExceeded || calls != 3 {
+		t.Errorf("retryable: got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = p.Do(func() error {
+		calls++
+		return errors.New("bad request")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("non-retryable: got %v after %d calls", err, calls)
+	}
+}
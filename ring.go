@@ -0,0 +1,102 @@
+package aralog
+
+import (
+    "io"
+    "sync"
+)
+
+// RingSink is an in-memory Sink which keeps only the last N entries
+// written to it. It is useful to embed recent log history into crash
+// reports or debug pages.
+type RingSink struct {
+    mu      sync.Mutex
+    entries []Entry
+    next    int  // index of the slot to write next
+    full    bool // whether all slots are used
+}
+
+// NewRingSink creates a RingSink which keeps the last size entries.
+func NewRingSink(size int) *RingSink {
+    if size < 1 {
+        size = 1
+    }
+    return &RingSink{entries: make([]Entry, size)}
+}
+
+// Write stores a copy of e, discarding the oldest entry if the ring is full.
+func (r *RingSink) Write(e Entry) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    // the Logger reuses its buffer, so keep a copy
+    e.Formatted = append(r.entries[r.next].Formatted[:0], e.Formatted...)
+    r.entries[r.next] = e
+    r.next++
+    if r.next == len(r.entries) {
+        r.next = 0
+        r.full = true
+    }
+    return nil
+}
+
+// Entries returns copies of the stored entries, oldest first.
+func (r *RingSink) Entries() []Entry {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    var out []Entry
+    if r.full {
+        out = appendEntryCopies(out, r.entries[r.next:])
+    }
+    return appendEntryCopies(out, r.entries[:r.next])
+}
+
+func appendEntryCopies(dst, src []Entry) []Entry {
+    for _, e := range src {
+        e.Formatted = append([]byte(nil), e.Formatted...)
+        dst = append(dst, e)
+    }
+    return dst
+}
+
+// Dump writes the formatted stored entries to w, oldest first.
+func (r *RingSink) Dump(w io.Writer) error {
+    for _, e := range r.Entries() {
+        if _, err := w.Write(e.Formatted); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Reset discards all stored entries.
+func (r *RingSink) Reset() {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    for i := range r.entries {
+        r.entries[i] = Entry{Formatted: r.entries[i].Formatted[:0]}
+    }
+    r.next = 0
+    r.full = false
+}
+
+// Flush does nothing.
+func (r *RingSink) Flush() error {
+    return nil
+}
+
+// Close does nothing, the entries stay available.
+func (r *RingSink) Close() error {
+    return nil
+}
+
+// Healthy always returns true.
+func (r *RingSink) Healthy() bool {
+    return true
+}
+
+// Describe describes the sink with its capacity.
+func (r *RingSink) Describe() SinkDescription {
+    return SinkDescription{Type: "ring", Healthy: true, Settings: map[string]interface{}{"size": len(r.entries)}}
+}
@@ -0,0 +1,22 @@
+package aralog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRingSink(t *testing.T) {
+	ring := NewRingSink(3)
+	logger := NewSinkLogger(ring, "", 0)
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		logger.Debug("%s", s)
+	}
+
+	var buf bytes.Buffer
+	if err := ring.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "c\nd\ne\n" {
+		t.Errorf("unexpected dump: %q", buf.String())
+	}
+}
@@ -0,0 +1,218 @@
+package aralog
+
+import (
+    "crypto/ed25519"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// RollFileSink is a Sink writing to a file which is rolled once it grows
+// beyond maxsize: the current file is renamed to pathYYYYMMDDhhmmss, or
+// moved to a YYYY/MM/DD directory next to it, see SetDatedArchive, and a
+// new one is started at path.
+type RollFileSink struct {
+    mu      sync.Mutex
+    file    *os.File
+    path    string             // file path
+    size    uint               // current size of log file
+    maxsize uint               // minimal maxsize should >= 1MB
+    err     error              // last write or rotation error
+    rolled  time.Time          // time of the last rotation
+    signKey ed25519.PrivateKey // signs the rolled files if set
+    dated   bool               // move the rolled files to YYYY/MM/DD directories
+}
+
+// NewRollFileSink opens or creates the file at path for appending, rolling it
+// at maxsize. If path ends with a separator, the file is named aralog.log in
+// that directory. The tokens %{hostname} and %{pid} in path are replaced by
+// the host name and process id, so instances sharing a volume never write
+// to or roll the files of each other: app-%{hostname}-%{pid}.log.
+func NewRollFileSink(path string, maxsize uint) (*RollFileSink, error) {
+    path = expandPathTokens(path)
+    if strings.HasSuffix(path, string(filepath.Separator)) {
+        path = path + "aralog.log" // the default log file name if not provided
+    }
+    if dir := filepath.Dir(path); dir != "." {
+        os.MkdirAll(dir, 0755)
+    }
+
+    out, err := os.OpenFile(path, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0600)
+    if err != nil {
+        return nil, err
+    }
+
+    var size uint
+    if fi, err := out.Stat(); err == nil {
+        size = uint(fi.Size())
+    }
+
+    // minimal maxsize should >= 1MB
+    if maxsize < 1024 * 1024 {
+        maxsize = 1024 * 1024 * 10
+    }
+
+    return &RollFileSink{file: out, path: path, size: size, maxsize: maxsize}, nil
+}
+
+func (s *RollFileSink) Write(e Entry) error {
+    s.mu.Lock()
+    s.err = nil
+    s.size += uint(len(e.Formatted))
+    var errs []error
+    var rolled string
+    if s.size >= s.maxsize {
+        rolled, errs = s.rollFile(e)
+    }
+    if s.err == nil {
+        _, s.err = s.file.Write(e.Formatted)
+    }
+    err := s.err
+    key := s.signKey
+    s.mu.Unlock()
+
+    if key != nil && rolled != "" {
+        if serr := SignFile(rolled, key); serr != nil {
+            errs = append(errs, serr)
+        }
+    }
+    // reported without the lock, the meta logger may write to this sink
+    for _, rerr := range errs {
+        internalError("rolling %s: %v", s.path, rerr)
+    }
+    return err
+}
+
+// rollFile renames the current file and opens a new one at s.path. It
+// returns the path of the rolled file, empty if it could not be renamed,
+// and the errors to report; s.err is set if no file could be opened.
+func (s *RollFileSink) rollFile(e Entry) (string, []error) {
+    var errs []error
+    var rolled string
+    now := e.Time
+
+    // close file before rename it, ignore if Close() failed
+    if err := s.file.Close(); err != nil {
+        errs = append(errs, err)
+    }
+
+    newPath := s.path
+
+    // rename s.path to nameYYYYMMDDhhmmss
+    target := s.path + now.Format("20060102150405")
+    if s.dated {
+        target = datedPath(s.path, now)
+        if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+            errs = append(errs, err)
+            target = s.path + now.Format("20060102150405")
+        }
+    }
+    err := os.Rename(s.path, target)
+    if err == nil {
+        // TODO zip it
+        rolled = target
+    } else {
+        errs = append(errs, err)
+
+        // if rename failed, start a new log file with different name
+        newPath = s.path + strconv.FormatInt(now.Unix(), 10)
+    }
+
+    newOut, err := os.OpenFile(newPath, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0600)
+    if err != nil {
+        s.err = err
+        return rolled, append(errs, err)
+    }
+
+    s.file = newOut
+    s.size = uint(len(e.Formatted))
+    s.rolled = now
+    atomic.AddUint64(&rotations, 1)
+    return rolled, errs
+}
+
+// expandPathTokens replaces the %{hostname} and %{pid} tokens in path.
+func expandPathTokens(path string) string {
+    if !strings.Contains(path, "%{") {
+        return path
+    }
+    return strings.NewReplacer("%{hostname}", pathSafe(hostname), "%{pid}", strconv.Itoa(pid)).Replace(path)
+}
+
+// datedPath returns the path of the file at path rolled at t into the
+// YYYY/MM/DD directory next to it: dir/2009/01/23/nameYYYYMMDDhhmmss.
+func datedPath(path string, t time.Time) string {
+    return filepath.Join(filepath.Dir(path), t.Format("2006"), t.Format("01"), t.Format("02"), filepath.Base(path) + t.Format("20060102150405"))
+}
+
+// SetDatedArchive sets whether the rolled files are moved to YYYY/MM/DD
+// directories next to the active file, by the time they are rolled, which
+// keeps the directories small over a long retention.
+func (s *RollFileSink) SetDatedArchive(on bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.dated = on
+}
+
+// SetSigningKey makes the sink sign every file it rolls with key, writing
+// the detached signature next to it, see SignFile. nil stops signing.
+func (s *RollFileSink) SetSigningKey(key ed25519.PrivateKey) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.signKey = key
+}
+
+// Flush commits the file to stable storage.
+func (s *RollFileSink) Flush() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.file.Sync()
+}
+
+// Close closes the current file.
+func (s *RollFileSink) Close() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.file.Close()
+}
+
+// Healthy reports whether the last write and rotation succeeded.
+func (s *RollFileSink) Healthy() bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err == nil
+}
+
+// Path returns the path of the active file.
+func (s *RollFileSink) Path() string {
+    return s.path
+}
+
+// Describe describes the sink with its path, roll size and current size.
+func (s *RollFileSink) Describe() SinkDescription {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return SinkDescription{
+        Type:     "file",
+        Healthy:  s.err == nil,
+        Settings: map[string]interface{}{"path": s.path, "maxSize": s.maxsize, "size": s.size},
+    }
+}
+
+// LastError returns the error of the last write or rotation, nil if it
+// succeeded.
+func (s *RollFileSink) LastError() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err
+}
+
+func (s *RollFileSink) snapshot() FileSnapshot {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return FileSnapshot{Path: s.path, Size: s.size, LastRotation: s.rolled}
+}
@@ -0,0 +1,253 @@
+package aralog
+
+import (
+    "container/list"
+    "fmt"
+    "path/filepath"
+    "strings"
+    "sync"
+)
+
+// RouteSink is a Sink writing each entry to the file at the path its route
+// returns, every file rolled on its own by a RollFileSink. At most maxOpen
+// files are kept open, the least recently written is closed to open another
+// and reopened for appending when needed again.
+type RouteSink struct {
+    mu      sync.Mutex
+    route   func(e Entry) string
+    maxsize uint
+    maxOpen int
+    files   map[string]*list.Element // of the *routeFiles in lru, by route
+    lru     *list.List               // the open *routeFiles, most recently written first
+    err     error                    // last open or write error
+}
+
+// routeFile is an open file of a RouteSink with the route it was opened
+// for, which differs from its path if the route has %{hostname} or %{pid}
+// tokens.
+type routeFile struct {
+    route string
+    sink  *RollFileSink
+}
+
+// NewRouteSink creates a RouteSink writing the entries to the paths route
+// returns, rolling the files at maxsize, see NewRollFileSink. maxOpen less
+// than 1 keeps 1 file open.
+func NewRouteSink(route func(e Entry) string, maxsize uint, maxOpen int) *RouteSink {
+    if maxOpen < 1 {
+        maxOpen = 1
+    }
+    return &RouteSink{route: route, maxsize: maxsize, maxOpen: maxOpen, files: map[string]*list.Element{}, lru: list.New()}
+}
+
+// NewTenantSink creates a RouteSink writing the entries of each tenant, the
+// value of their field key, to dir/<tenant>/aralog.log and those without
+// it to dir/aralog.log. Characters of the tenant other than letters, digits,
+// '-', '_' and inner dots are replaced by '_', so it can't escape dir.
+func NewTenantSink(dir, key string, maxsize uint, maxOpen int) *RouteSink {
+    return NewRouteSink(func(e Entry) string {
+        for _, f := range e.Fields {
+            if f.Key == key {
+                return filepath.Join(dir, pathSafe(fmt.Sprint(f.Value)), "aralog.log")
+            }
+        }
+        return filepath.Join(dir, "aralog.log")
+    }, maxsize, maxOpen)
+}
+
+// NewLevelSink creates a RouteSink writing each band of levels to its own
+// file in dir, for separate retention and alerting: error and fatal entries
+// to error.log, warn entries to warn.log and the others to app.log.
+func NewLevelSink(dir string, maxsize uint) *RouteSink {
+    return NewRouteSink(func(e Entry) string {
+        switch {
+        case e.Level >= LevelError:
+            return filepath.Join(dir, "error.log")
+        case e.Level == LevelWarn:
+            return filepath.Join(dir, "warn.log")
+        }
+        return filepath.Join(dir, "app.log")
+    }, maxsize, 3)
+}
+
+// NewTemplateSink creates a RouteSink writing the entries to the paths
+// of a template with placeholders, such as logs/%{service}/%{date}.log:
+//
+//	%{date}      the date of the entry: 2009-01-23
+//	%{level}     the level of the entry in lower case: error
+//	%{hostname}  the host name of the machine: web01
+//	%{pid}       the process id: 1234
+//	%{name}      the value of the field name, _ if the entry has none
+//
+// The values are made safe path elements like the tenants of
+// NewTenantSink. The files are rolled at maxsize, see NewRouteSink.
+func NewTemplateSink(template string, maxsize uint, maxOpen int) (*RouteSink, error) {
+    route, err := parsePathTemplate(template)
+    if err != nil {
+        return nil, err
+    }
+    return NewRouteSink(route, maxsize, maxOpen), nil
+}
+
+// parsePathTemplate parses a template of NewTemplateSink into the route
+// expanding it.
+func parsePathTemplate(template string) (func(e Entry) string, error) {
+    var parts []func(buf []byte, e Entry) []byte
+    rest := template
+    for len(rest) > 0 {
+        i := strings.Index(rest, "%{")
+        if i < 0 {
+            i = len(rest)
+        }
+        if lit := rest[:i]; len(lit) > 0 {
+            parts = append(parts, func(buf []byte, e Entry) []byte { return append(buf, lit...) })
+        }
+        if i == len(rest) {
+            break
+        }
+        rest = rest[i + 2:]
+        j := strings.IndexByte(rest, '}')
+        if j < 0 {
+            return nil, fmt.Errorf("aralog: unterminated placeholder in path template %q", template)
+        }
+        name := rest[:j]
+        rest = rest[j + 1:]
+        switch name {
+        case "":
+            return nil, fmt.Errorf("aralog: empty placeholder in path template %q", template)
+        case "date":
+            parts = append(parts, func(buf []byte, e Entry) []byte { return e.Time.AppendFormat(buf, "2006-01-02") })
+        case "level":
+            parts = append(parts, func(buf []byte, e Entry) []byte { return append(buf, strings.ToLower(e.Level.String())...) })
+        case "hostname", "pid":
+            token := expandPathTokens("%{" + name + "}")
+            parts = append(parts, func(buf []byte, e Entry) []byte { return append(buf, token...) })
+        default:
+            parts = append(parts, func(buf []byte, e Entry) []byte {
+                for _, f := range e.Fields {
+                    if f.Key == name {
+                        return append(buf, pathSafe(fmt.Sprint(f.Value))...)
+                    }
+                }
+                return append(buf, pathSafe("")...)
+            })
+        }
+    }
+
+    return func(e Entry) string {
+        var buf []byte
+        for _, part := range parts {
+            buf = part(buf, e)
+        }
+        return string(buf)
+    }, nil
+}
+
+// pathSafe makes s usable as one path element.
+func pathSafe(s string) string {
+    if len(s) == 0 {
+        return "_"
+    }
+    s = strings.Map(func(r rune) rune {
+        if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+            return r
+        }
+        return '_'
+    }, s)
+    if s[0] == '.' {
+        s = "_" + s[1:]
+    }
+    return s
+}
+
+// Write writes e to the file of its route, opening it if needed.
+func (s *RouteSink) Write(e Entry) error {
+    path := s.route(e)
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    var f *RollFileSink
+    if el, ok := s.files[path]; ok {
+        s.lru.MoveToFront(el)
+        f = el.Value.(*routeFile).sink
+    } else {
+        for s.lru.Len() >= s.maxOpen {
+            s.evict(s.lru.Back())
+        }
+        if f, s.err = NewRollFileSink(path, s.maxsize); s.err != nil {
+            return s.err
+        }
+        s.files[path] = s.lru.PushFront(&routeFile{path, f})
+    }
+    s.err = f.Write(e)
+    return s.err
+}
+
+// evict closes the file of el. s.mu must be held.
+func (s *RouteSink) evict(el *list.Element) {
+    f := s.lru.Remove(el).(*routeFile)
+    delete(s.files, f.route)
+    if err := f.sink.Close(); err != nil {
+        internalError("closing %s: %v", f.sink.Path(), err)
+    }
+}
+
+// Flush flushes the open files and returns the first error.
+func (s *RouteSink) Flush() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    var first error
+    for el := s.lru.Front(); el != nil; el = el.Next() {
+        if err := el.Value.(*routeFile).sink.Flush(); err != nil && first == nil {
+            first = err
+        }
+    }
+    return first
+}
+
+// Close closes the open files and returns the first error. Writing again
+// reopens them.
+func (s *RouteSink) Close() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    var first error
+    for el := s.lru.Front(); el != nil; el = s.lru.Front() {
+        f := s.lru.Remove(el).(*routeFile)
+        if err := f.sink.Close(); err != nil && first == nil {
+            first = err
+        }
+    }
+    s.files = map[string]*list.Element{}
+    return first
+}
+
+// Healthy reports whether the last write succeeded.
+func (s *RouteSink) Healthy() bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err == nil
+}
+
+// Open returns the number of open files.
+func (s *RouteSink) Open() int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.lru.Len()
+}
+
+// Describe describes the sink with the limit of open files and the open
+// files.
+func (s *RouteSink) Describe() SinkDescription {
+    d := SinkDescription{Type: "route", Healthy: s.Healthy(), Settings: map[string]interface{}{"maxOpen": s.maxOpen, "maxSize": s.maxsize}}
+    for _, f := range s.inner() {
+        d.Sinks = append(d.Sinks, DescribeSink(f))
+    }
+    return d
+}
+
+// LastError returns the error of the last write, nil if it succeeded.
+func (s *RouteSink) LastError() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err
+}
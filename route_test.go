@@ -0,0 +1,168 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTenantSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink := NewTenantSink(dir, "tenant", 0, 2)
+	l := NewSinkLogger(sink, "", 0)
+	l.With(F("tenant", "acme")).Info("a1")
+	l.With(F("tenant", "globex")).Info("g1")
+	l.With(F("tenant", "../initech")).Info("i1")
+	if n := sink.Open(); n != 2 {
+		t.Errorf("%d files open, want 2", n)
+	}
+	l.With(F("tenant", "acme")).Info("a2")
+	l.Info("untenanted")
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"acme/aralog.log":       "a1 tenant=acme\na2 tenant=acme\n",
+		"globex/aralog.log":     "g1 tenant=globex\n",
+		"_._initech/aralog.log": "i1 tenant=../initech\n",
+		"aralog.log":            "untenanted\n",
+	}
+	for name, content := range want {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(b) != content {
+			t.Errorf("%s: got %q, want %q", name, b, content)
+		}
+	}
+}
+
+func TestTenantConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Config{Outputs: []OutputConfig{{Type: "tenant", Path: dir, Key: "tenant"}}}
+	l, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Sink().Close()
+	if d := DescribeSink(l.Sink()); d.Type != "route" || d.Settings["maxOpen"] != 64 {
+		t.Errorf("unexpected sink %+v", d)
+	}
+
+	c.Outputs[0].Key = ""
+	if err := c.Validate(); err == nil {
+		t.Error("tenant output without key validated")
+	}
+}
+
+func TestTemplateSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink, err := NewTemplateSink(filepath.Join(dir, "%{service}", "%{date}-%{level}.log"), 0, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewSinkLogger(sink, "", 0)
+	day := time.Date(2009, 1, 23, 1, 23, 23, 0, time.Local)
+	l.SetClock(func() time.Time { return day })
+	l.With(F("service", "billing")).Error("declined")
+	l.With(F("service", "billing")).Info("charged")
+	l.Info("no service")
+	day = day.Add(24 * time.Hour)
+	l.With(F("service", "billing")).Info("next day")
+	sink.Close()
+
+	for _, name := range []string{"billing/2009-01-23-error.log", "billing/2009-01-23-info.log", "_/2009-01-23-info.log", "billing/2009-01-24-info.log"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	route, _ := parsePathTemplate("%{hostname}/%{pid}.log")
+	if got, want := route(Entry{}), pathSafe(hostname)+"/"+strconv.Itoa(os.Getpid())+".log"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	for _, bad := range []string{"logs/%{service.log", "logs/%{}.log"} {
+		if _, err := NewTemplateSink(bad, 0, 1); err == nil {
+			t.Errorf("template %q parsed", bad)
+		}
+	}
+}
+
+func TestLevelSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Config{Flags: []string{}, Outputs: []OutputConfig{{Type: "levels", Path: dir}}}
+	l, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("checking")
+	l.Info("started")
+	l.Warn("slow")
+	l.Error("failed")
+	l.Sink().Close()
+
+	want := map[string]string{
+		"app.log":   "checking\nstarted\n",
+		"warn.log":  "slow\n",
+		"error.log": "failed\n",
+	}
+	for name, content := range want {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(b) != content {
+			t.Errorf("%s: got %q, want %q", name, b, content)
+		}
+	}
+}
+
+func TestRouteSinkEvictExpandedPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink := NewRouteSink(func(e Entry) string {
+		return filepath.Join(dir, e.Message+"-%{pid}.log")
+	}, 1024*1024, 1)
+	defer sink.Close()
+	logger := NewSinkLogger(sink, "", 0)
+	for _, msg := range []string{"a", "b", "a"} {
+		if err := logger.Info("%s", msg); err != nil {
+			t.Fatalf("writing %s: %v", msg, err)
+		}
+	}
+	if sink.Open() != 1 {
+		t.Errorf("%d files open, want 1", sink.Open())
+	}
+}
@@ -0,0 +1,49 @@
+package aralog
+
+import (
+    "time"
+)
+
+// rpcClientErrors are the gRPC status codes caused by the caller rather
+// than the server, logged at LevelWarn.
+var rpcClientErrors = map[string]bool{
+    "Canceled":           true,
+    "InvalidArgument":    true,
+    "NotFound":           true,
+    "AlreadyExists":      true,
+    "PermissionDenied":   true,
+    "Unauthenticated":    true,
+    "FailedPrecondition": true,
+    "OutOfRange":         true,
+    "ResourceExhausted":  true,
+    "Aborted":            true,
+}
+
+// logRPC logs one gRPC call as "grpc server" or "grpc client", by side,
+// with the fields method, code, duration, peer and, with payloads, request
+// and response. Calls are logged at LevelInfo, LevelWarn or LevelError by
+// their status code. The interceptors built with the aralog_grpc tag use it.
+func logRPC(l *Logger, side, method, code string, d time.Duration, peer string, err error, payloads bool, req, resp interface{}) {
+    fields := []Field{F("method", method), F("code", code), F("duration", d), F("peer", peer)}
+    if err != nil {
+        fields = append(fields, F("error", err))
+    }
+    if payloads {
+        if req != nil {
+            fields = append(fields, F("request", req))
+        }
+        if resp != nil {
+            fields = append(fields, F("response", resp))
+        }
+    }
+
+    log := l.With(fields...)
+    switch {
+    case code == "OK":
+        log.Info("grpc %s", side)
+    case rpcClientErrors[code]:
+        log.Warn("grpc %s", side)
+    default:
+        log.Error("grpc %s", side)
+    }
+}
@@ -0,0 +1,28 @@
+package aralog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogRPC(t *testing.T) {
+	ring := NewRingSink(4)
+	logger := NewSinkLogger(ring, "", 0)
+	logRPC(logger, "server", "/pb.Users/Get", "OK", time.Millisecond, "10.0.0.1:5000", nil, true, "id:1", "name:bob")
+	logRPC(logger, "client", "/pb.Users/Get", "NotFound", time.Millisecond, "users:443", errors.New("no user"), false, "id:2", nil)
+	logRPC(logger, "server", "/pb.Users/Get", "Internal", time.Millisecond, "", errors.New("db down"), false, nil, nil)
+
+	entries := ring.Entries()
+	if len(entries) != 3 || entries[0].Level != LevelInfo || entries[1].Level != LevelWarn || entries[2].Level != LevelError {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+	want := `grpc server method=/pb.Users/Get code=OK duration=1ms peer=10.0.0.1:5000 request=id:1 response=name:bob`
+	if line := strings.TrimSuffix(string(entries[0].Formatted), "\n"); line != want {
+		t.Errorf("got %q", line)
+	}
+	if line := string(entries[1].Formatted); strings.Contains(line, "request=") || !strings.Contains(line, `error="no user"`) {
+		t.Errorf("unexpected payload or error in %q", line)
+	}
+}
@@ -0,0 +1,322 @@
+package aralog
+
+import (
+    "errors"
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// Rule is a compiled filter expression over entries, see CompileRule.
+type Rule struct {
+    src  string
+    eval func(e *Entry) bool
+}
+
+// CompileRule compiles a filter expression, ex:
+//
+//  level >= warn || fields.tenant == "acme"
+//
+// The operands are level, logger, message, fields.<key>, quoted strings and
+// bare words or numbers. The operators are == != < <= > >=, =~ matching a
+// quoted regexp, && || ! and parentheses. Levels compare by severity;
+// other values compare as numbers if both sides are numbers, as strings
+// otherwise. A comparison with a field the entry does not have is false.
+func CompileRule(src string) (*Rule, error) {
+    toks, err := tokenizeRule(src)
+    if err != nil {
+        return nil, fmt.Errorf("aralog: rule %q: %v", src, err)
+    }
+    p := &ruleParser{toks: toks}
+    eval, err := p.or()
+    if err == nil && p.pos < len(p.toks) {
+        err = fmt.Errorf("unexpected %s", p.toks[p.pos].text)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("aralog: rule %q: %v", src, err)
+    }
+    return &Rule{src: src, eval: eval}, nil
+}
+
+// MustCompileRule is like CompileRule but panics if the expression is invalid.
+func MustCompileRule(src string) *Rule {
+    r, err := CompileRule(src)
+    if err != nil {
+        panic(err)
+    }
+    return r
+}
+
+// Match reports whether e satisfies the rule.
+func (r *Rule) Match(e Entry) bool {
+    return r.eval(&e)
+}
+
+// String returns the source of the rule.
+func (r *Rule) String() string {
+    return r.src
+}
+
+// SetFilterRule writes only the entries of the logger which match r, nil
+// removes the rule. The rule sees the fields bound to the logger and the
+// message before sanitizing and truncation.
+func (l *Logger) SetFilterRule(r *Rule) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.rule = r
+}
+
+type ruleToken struct {
+    kind byte   // 'w' word, 's' string, 'o' operator
+    text string // unquoted for strings
+}
+
+func tokenizeRule(src string) ([]ruleToken, error) {
+    var toks []ruleToken
+    for i := 0; i < len(src); {
+        c := src[i]
+        switch {
+        case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+            i++
+        case c == '"':
+            j := i + 1
+            for ; j < len(src) && src[j] != '"'; j++ {
+                if src[j] == '\\' {
+                    j++
+                }
+            }
+            if j >= len(src) {
+                return nil, errors.New("unterminated string")
+            }
+            s, err := strconv.Unquote(src[i:j + 1])
+            if err != nil {
+                return nil, fmt.Errorf("invalid string %s", src[i:j + 1])
+            }
+            toks = append(toks, ruleToken{'s', s})
+            i = j + 1
+        case strings.IndexByte("()", c) >= 0:
+            toks = append(toks, ruleToken{'o', src[i:i + 1]})
+            i++
+        case strings.IndexByte("=!<>&|", c) >= 0:
+            op := src[i:i + 1]
+            if i + 1 < len(src) {
+                switch two := src[i:i + 2]; two {
+                case "==", "!=", "<=", ">=", "=~", "&&", "||":
+                    op = two
+                }
+            }
+            if op == "=" || op == "&" || op == "|" {
+                return nil, fmt.Errorf("unknown operator %s", op)
+            }
+            toks = append(toks, ruleToken{'o', op})
+            i += len(op)
+        default:
+            j := i
+            for j < len(src) && strings.IndexByte(" \t\n\r\"()=!<>&|", src[j]) < 0 {
+                j++
+            }
+            toks = append(toks, ruleToken{'w', src[i:j]})
+            i = j
+        }
+    }
+    return toks, nil
+}
+
+type ruleParser struct {
+    toks []ruleToken
+    pos  int
+}
+
+func (p *ruleParser) accept(op string) bool {
+    if p.pos < len(p.toks) && p.toks[p.pos].kind == 'o' && p.toks[p.pos].text == op {
+        p.pos++
+        return true
+    }
+    return false
+}
+
+func (p *ruleParser) or() (func(*Entry) bool, error) {
+    left, err := p.and()
+    for err == nil && p.accept("||") {
+        var right func(*Entry) bool
+        if right, err = p.and(); err == nil {
+            l, r := left, right
+            left = func(e *Entry) bool { return l(e) || r(e) }
+        }
+    }
+    return left, err
+}
+
+func (p *ruleParser) and() (func(*Entry) bool, error) {
+    left, err := p.unary()
+    for err == nil && p.accept("&&") {
+        var right func(*Entry) bool
+        if right, err = p.unary(); err == nil {
+            l, r := left, right
+            left = func(e *Entry) bool { return l(e) && r(e) }
+        }
+    }
+    return left, err
+}
+
+func (p *ruleParser) unary() (func(*Entry) bool, error) {
+    if p.accept("!") {
+        f, err := p.unary()
+        if err != nil {
+            return nil, err
+        }
+        return func(e *Entry) bool { return !f(e) }, nil
+    }
+    if p.accept("(") {
+        f, err := p.or()
+        if err == nil && !p.accept(")") {
+            err = errors.New("missing )")
+        }
+        return f, err
+    }
+    return p.comparison()
+}
+
+func (p *ruleParser) operand() (ruleToken, error) {
+    if p.pos >= len(p.toks) {
+        return ruleToken{}, errors.New("unexpected end")
+    }
+    t := p.toks[p.pos]
+    if t.kind == 'o' {
+        return t, fmt.Errorf("unexpected %s", t.text)
+    }
+    p.pos++
+    return t, nil
+}
+
+func (p *ruleParser) comparison() (func(*Entry) bool, error) {
+    left, err := p.operand()
+    if err != nil {
+        return nil, err
+    }
+    if p.pos >= len(p.toks) || p.toks[p.pos].kind != 'o' {
+        return nil, fmt.Errorf("expected an operator after %s", left.text)
+    }
+    op := p.toks[p.pos].text
+    switch op {
+    case "==", "!=", "<", "<=", ">", ">=", "=~":
+        p.pos++
+    default:
+        return nil, fmt.Errorf("expected an operator after %s", left.text)
+    }
+    right, err := p.operand()
+    if err != nil {
+        return nil, err
+    }
+
+    if op == "=~" {
+        if right.kind != 's' {
+            return nil, errors.New("=~ expects a quoted regexp")
+        }
+        re, err := regexp.Compile(right.text)
+        if err != nil {
+            return nil, err
+        }
+        get := ruleValue(left)
+        return func(e *Entry) bool {
+            v, ok := get(e)
+            return ok && re.MatchString(v)
+        }, nil
+    }
+
+    if left.kind == 'w' && left.text == "level" || right.kind == 'w' && right.text == "level" {
+        return levelComparison(left, op, right)
+    }
+
+    lget, rget := ruleValue(left), ruleValue(right)
+    return func(e *Entry) bool {
+        l, lok := lget(e)
+        r, rok := rget(e)
+        return lok && rok && compareRuleValues(l, op, r)
+    }, nil
+}
+
+// levelComparison compiles a comparison of the level with a level name.
+func levelComparison(left ruleToken, op string, right ruleToken) (func(*Entry) bool, error) {
+    if right.kind == 'w' && right.text == "level" {
+        // name op level is level reversed(op) name
+        left, right = right, left
+        switch op {
+        case "<":
+            op = ">"
+        case "<=":
+            op = ">="
+        case ">":
+            op = "<"
+        case ">=":
+            op = "<="
+        }
+    }
+    level, err := ParseLevel(right.text)
+    if err != nil {
+        return nil, fmt.Errorf("unknown level %s", right.text)
+    }
+    return func(e *Entry) bool {
+        return compareOrdered(int(e.Level) - int(level), op)
+    }, nil
+}
+
+// ruleValue returns the getter of an operand: a property of the entry or
+// a literal.
+func ruleValue(t ruleToken) func(*Entry) (string, bool) {
+    if t.kind == 'w' {
+        switch {
+        case t.text == "logger":
+            return func(e *Entry) (string, bool) { return e.Logger, true }
+        case t.text == "message":
+            return func(e *Entry) (string, bool) { return strings.TrimSuffix(e.Message, "\n"), true }
+        case strings.HasPrefix(t.text, "fields."):
+            key := t.text[len("fields."):]
+            return func(e *Entry) (string, bool) {
+                // the last bound field with the key wins
+                for i := len(e.Fields) - 1; i >= 0; i-- {
+                    if e.Fields[i].Key == key {
+                        return fmt.Sprint(e.Fields[i].Value), true
+                    }
+                }
+                return "", false
+            }
+        }
+    }
+    return func(*Entry) (string, bool) { return t.text, true }
+}
+
+func compareRuleValues(l, op, r string) bool {
+    if lf, err := strconv.ParseFloat(l, 64); err == nil {
+        if rf, err := strconv.ParseFloat(r, 64); err == nil {
+            switch {
+            case lf < rf:
+                return compareOrdered(-1, op)
+            case lf > rf:
+                return compareOrdered(1, op)
+            }
+            return compareOrdered(0, op)
+        }
+    }
+    return compareOrdered(strings.Compare(l, r), op)
+}
+
+// compareOrdered applies op to the sign of a comparison result.
+func compareOrdered(c int, op string) bool {
+    switch op {
+    case "==":
+        return c == 0
+    case "!=":
+        return c != 0
+    case "<":
+        return c < 0
+    case "<=":
+        return c <= 0
+    case ">":
+        return c > 0
+    case ">=":
+        return c >= 0
+    }
+    return false
+}
@@ -0,0 +1,47 @@
+package aralog
+
+import (
+	"testing"
+)
+
+func TestRule(t *testing.T) {
+	acme := []Field{F("tenant", "acme"), F("attempt", 3)}
+	tests := []struct {
+		rule string
+		e    Entry
+		want bool
+	}{
+		{`level>=warn || fields.tenant=="acme"`, Entry{Level: LevelError}, true},
+		{`level>=warn || fields.tenant=="acme"`, Entry{Level: LevelInfo, Fields: acme}, true},
+		{`level>=warn || fields.tenant=="acme"`, Entry{Level: LevelInfo}, false},
+		{`info < level`, Entry{Level: LevelWarn}, true},
+		{`fields.attempt > 10`, Entry{Fields: acme}, false},
+		{`fields.attempt >= 3 && !(logger == db)`, Entry{Logger: "http", Fields: acme}, true},
+		{`fields.missing != "x"`, Entry{}, false},
+		{`message =~ "^timeout"`, Entry{Message: "timeout after 3s\n"}, true},
+	}
+	for _, test := range tests {
+		if got := MustCompileRule(test.rule).Match(test.e); got != test.want {
+			t.Errorf("%s on %+v: got %v", test.rule, test.e, got)
+		}
+	}
+
+	for _, bad := range []string{`level >= loud`, `level = warn`, `(level > info`, `message =~ x`, `fields.a == "b`} {
+		if _, err := CompileRule(bad); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestSetFilterRule(t *testing.T) {
+	ring := NewRingSink(10)
+	logger := NewSinkLogger(ring, "", 0)
+	logger.SetFilterRule(MustCompileRule(`level >= warn || fields.tenant == "acme"`))
+	logger.Info("dropped")
+	logger.With(F("tenant", "acme")).Info("kept")
+	logger.Warn("kept")
+
+	if n := len(ring.Entries()); n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+}
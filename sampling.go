@@ -0,0 +1,93 @@
+package aralog
+
+import (
+    "sync"
+    "time"
+)
+
+// SamplingSink caps the volume of repetitive entries: within each Tick, the
+// first First entries with the same level and message are written, then
+// only every Thereafter-th of them. Entries at LevelError and above are
+// never sampled.
+type SamplingSink struct {
+    mu         sync.Mutex
+    sink       Sink
+    counts     map[samplingKey]uint64
+    reset      time.Time // end of the current tick
+    Tick       time.Duration
+    First      uint64
+    Thereafter uint64 // 0 drops all entries after the first First
+}
+
+type samplingKey struct {
+    level   Level
+    message string
+}
+
+// NewSamplingSink creates a SamplingSink in front of sink.
+func NewSamplingSink(sink Sink, tick time.Duration, first, thereafter uint64) *SamplingSink {
+    return &SamplingSink{
+        sink:       sink,
+        counts:     map[samplingKey]uint64{},
+        Tick:       tick,
+        First:      first,
+        Thereafter: thereafter,
+    }
+}
+
+// Write writes e to the sink unless it is sampled out.
+func (s *SamplingSink) Write(e Entry) error {
+    if e.Level < LevelError && !s.sample(e) {
+        countDropped(e)
+        return nil
+    }
+    return s.sink.Write(e)
+}
+
+func (s *SamplingSink) sample(e Entry) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if !e.Time.Before(s.reset) {
+        s.counts = map[samplingKey]uint64{}
+        s.reset = e.Time.Add(s.Tick)
+    }
+
+    key := samplingKey{e.Level, e.Message}
+    n := s.counts[key] + 1
+    s.counts[key] = n
+    if n <= s.First {
+        return true
+    }
+    return s.Thereafter > 0 && (n - s.First) % s.Thereafter == 0
+}
+
+// Flush flushes the underlying sink.
+func (s *SamplingSink) Flush() error {
+    return s.sink.Flush()
+}
+
+// Close closes the underlying sink.
+func (s *SamplingSink) Close() error {
+    return s.sink.Close()
+}
+
+// Healthy reports whether the underlying sink is healthy.
+func (s *SamplingSink) Healthy() bool {
+    return s.sink.Healthy()
+}
+
+// Describe describes the sampling and the underlying sink.
+func (s *SamplingSink) Describe() SinkDescription {
+    return SinkDescription{
+        Type:     "sampling",
+        Healthy:  s.Healthy(),
+        Settings: map[string]interface{}{"tick": s.Tick.String(), "first": s.First, "thereafter": s.Thereafter},
+        Sinks:    []SinkDescription{DescribeSink(s.sink)},
+    }
+}
+
+// LastError returns the last error of the underlying sink.
+func (s *SamplingSink) LastError() error {
+    return SinkLastError(s.sink)
+}
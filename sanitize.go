@@ -0,0 +1,32 @@
+package aralog
+
+import (
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+// sanitize escapes newlines and other control characters in s, so a
+// message can't forge extra entries or terminal escapes: "a\nb" becomes
+// `a\nb`. Tabs are kept.
+func sanitize(s string) string {
+    if strings.IndexFunc(s, isUnsafeControl) < 0 {
+        return s
+    }
+
+    var b strings.Builder
+    for _, r := range s {
+        if !isUnsafeControl(r) {
+            b.WriteRune(r)
+            continue
+        }
+        // strip the quotes of the Go escaped form
+        q := strconv.QuoteRune(r)
+        b.WriteString(q[1:len(q) - 1])
+    }
+    return b.String()
+}
+
+func isUnsafeControl(r rune) bool {
+    return r != '\t' && unicode.IsControl(r)
+}
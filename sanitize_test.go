@@ -0,0 +1,17 @@
+package aralog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLsanitize(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", Lsanitize)
+	logger.With(F("user", "x\n2009/01/23 fake")).Info("login %s\n", "bob\r\nINFO admin logged in\x1b[2J")
+
+	want := "login bob\\r\\nINFO admin logged in\\x1b[2J user=\"x\\n2009/01/23 fake\"\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
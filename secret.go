@@ -0,0 +1,77 @@
+package aralog
+
+import (
+    "regexp"
+    "strings"
+)
+
+// Redactions of common credentials, masked in every message unless the
+// logger keeps secrets, see SetSecretMasking. Tokens must follow an
+// Authorization header scheme, Bearer or a token key and be at least 20
+// characters long, so words like "basic authentication" are kept.
+var (
+    RedactBearerTokens = Redaction{regexp.MustCompile(`(?i)\b(authorization:\s*(?:bearer|basic)\s+|bearer\s+|(?:access_|api_|auth_)?token=)[A-Za-z0-9\-._~+/]{20,}=*`), "$1[SECRET]"}
+    RedactAWSKeys      = Redaction{regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`), "[AWS_KEY]"}
+)
+
+// secretPattern matches what RedactBearerTokens and RedactAWSKeys match and
+// runs of base64 characters long enough to be keys, in one scan: group 1 is
+// the scheme or key before a token, group 3 an AWS key and group 4 a blob.
+// '/' is left out of the blobs, so paths and URLs are not taken for keys.
+var secretPattern = regexp.MustCompile(`(?i:\b(authorization:\s*(?:bearer|basic)\s+|bearer\s+|(?:access_|api_|auth_)?token=)([A-Za-z0-9\-._~+/]{20,}=*))|\b((?:AKIA|ASIA)[0-9A-Z]{16})\b|([A-Za-z0-9+_-]{32,}={0,2})`)
+
+// SetSecretMasking sets whether the messages of the logger are scanned for
+// obvious secrets, bearer tokens, AWS access keys and long base64 blobs,
+// which are masked. It is on by default; turning it off saves the scan for
+// messages known not to hold secrets.
+func (l *Logger) SetSecretMasking(on bool) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.keepSecrets = !on
+}
+
+// maskSecrets masks the secrets found in s.
+func maskSecrets(s string) string {
+    matches := secretPattern.FindAllStringSubmatchIndex(s, -1)
+    if len(matches) == 0 {
+        return s
+    }
+
+    var b strings.Builder
+    last := 0
+    for _, m := range matches {
+        switch {
+        case m[2] >= 0:
+            b.WriteString(s[last:m[3]])
+            b.WriteString("[SECRET]")
+        case m[6] >= 0:
+            b.WriteString(s[last:m[0]])
+            b.WriteString("[AWS_KEY]")
+        case mixedCase(s[m[0]:m[1]]):
+            b.WriteString(s[last:m[0]])
+            b.WriteString("[SECRET]")
+        default:
+            b.WriteString(s[last:m[1]]) // hex digests, identifiers and words are kept
+        }
+        last = m[1]
+    }
+    b.WriteString(s[last:])
+    return b.String()
+}
+
+// mixedCase reports whether s has lower and upper case letters and digits,
+// as random keys do while words and hex digests do not.
+func mixedCase(s string) bool {
+    var lower, upper, digit bool
+    for i := 0; i < len(s); i++ {
+        switch c := s[i]; {
+        case c >= 'a' && c <= 'z':
+            lower = true
+        case c >= 'A' && c <= 'Z':
+            upper = true
+        case c >= '0' && c <= '9':
+            digit = true
+        }
+    }
+    return lower && upper && digit
+}
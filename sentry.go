@@ -0,0 +1,245 @@
+package aralog
+
+import (
+    "bytes"
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/url"
+    "path/filepath"
+    "runtime"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// SentryHook is a LevelHook sending error and fatal entries to a Sentry
+// compatible server, with the message, fields and the stack of the logging
+// goroutine. Events are sent in the background, except fatal ones which are
+// sent before the process exits; events beyond the rate limit, or while the
+// server asks to back off, are dropped.
+type SentryHook struct {
+    client *http.Client
+    url    string // of the store endpoint
+    auth   string // X-Sentry-Auth header
+    events chan []byte
+    done   chan struct{}
+
+    mu         sync.Mutex
+    closed     bool // events fired after Close are dropped
+    perMinute  float64
+    tokens     float64
+    refilled   time.Time
+    retryAfter time.Time // set by a 429 response
+}
+
+// NewSentryHook creates a SentryHook sending to the project of dsn, ex:
+// "https://key@sentry.example.com/42", at most perMinute events per minute.
+func NewSentryHook(dsn string, perMinute int) (*SentryHook, error) {
+    u, err := url.Parse(dsn)
+    if err != nil || u.User == nil || u.Host == "" {
+        return nil, fmt.Errorf("aralog: invalid sentry DSN %q", dsn)
+    }
+    i := strings.LastIndex(u.Path, "/")
+    project := u.Path[i + 1:]
+    if project == "" {
+        return nil, fmt.Errorf("aralog: sentry DSN %q has no project", dsn)
+    }
+    auth := "Sentry sentry_version=7, sentry_client=aralog/1.0, sentry_key=" + u.User.Username()
+    if secret, ok := u.User.Password(); ok {
+        auth += ", sentry_secret=" + secret
+    }
+
+    h := &SentryHook{
+        client:    &http.Client{Timeout: 5 * time.Second},
+        url:       u.Scheme + "://" + u.Host + u.Path[:i] + "/api/" + project + "/store/",
+        auth:      auth,
+        events:    make(chan []byte, 100),
+        done:      make(chan struct{}),
+        perMinute: float64(perMinute),
+        tokens:    float64(perMinute),
+        refilled:  time.Now(),
+    }
+    go h.run()
+    return h, nil
+}
+
+// Levels returns LevelError and LevelFatal.
+func (h *SentryHook) Levels() []Level {
+    return []Level{LevelError, LevelFatal}
+}
+
+type sentryEvent struct {
+    EventID    string                 `json:"event_id"`
+    Timestamp  string                 `json:"timestamp"`
+    Level      string                 `json:"level"`
+    Logger     string                 `json:"logger,omitempty"`
+    Platform   string                 `json:"platform"`
+    ServerName string                 `json:"server_name,omitempty"`
+    Message    string                 `json:"message"`
+    Extra      map[string]interface{} `json:"extra,omitempty"`
+    Stacktrace *sentryStacktrace      `json:"stacktrace,omitempty"`
+}
+
+type sentryStacktrace struct {
+    Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+    Function string `json:"function"`
+    Filename string `json:"filename"`
+    AbsPath  string `json:"abs_path"`
+    Lineno   int    `json:"lineno"`
+}
+
+// Fire queues e for sending, or sends it at once if it is fatal.
+func (h *SentryHook) Fire(e *Entry) error {
+    if !h.allow(e.Time) {
+        return nil
+    }
+
+    id := make([]byte, 16)
+    rand.Read(id)
+    ev := sentryEvent{
+        EventID:    hex.EncodeToString(id),
+        Timestamp:  e.Time.UTC().Format(time.RFC3339),
+        Level:      strings.ToLower(e.Level.String()),
+        Logger:     e.Logger,
+        Platform:   "go",
+        ServerName: hostname,
+        Message:    strings.TrimSuffix(e.Message, "\n"),
+        Stacktrace: callerStacktrace(),
+    }
+    if len(e.Fields) > 0 {
+        ev.Extra = map[string]interface{}{}
+        for _, f := range e.Fields {
+            if err, ok := f.Value.(error); ok {
+                ev.Extra[f.Key] = err.Error()
+            } else {
+                ev.Extra[f.Key] = f.Value
+            }
+        }
+    }
+    b, err := json.Marshal(ev)
+    if err != nil {
+        return err
+    }
+
+    if e.Level == LevelFatal {
+        return h.send(b)
+    }
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if h.closed {
+        countDropped(*e)
+        return nil
+    }
+    select {
+    case h.events <- b:
+        return nil
+    default:
+        return fmt.Errorf("aralog: sentry queue full, event dropped")
+    }
+}
+
+// allow takes a token of the rate limit.
+func (h *SentryHook) allow(now time.Time) bool {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if now.Before(h.retryAfter) {
+        return false
+    }
+    h.tokens += now.Sub(h.refilled).Minutes() * h.perMinute
+    if h.tokens > h.perMinute {
+        h.tokens = h.perMinute
+    }
+    h.refilled = now
+    if h.tokens < 1 {
+        return false
+    }
+    h.tokens--
+    return true
+}
+
+func (h *SentryHook) run() {
+    defer close(h.done)
+    for b := range h.events {
+        if err := h.send(b); err != nil {
+            internalError("sentry: %v", err)
+        }
+    }
+}
+
+// send posts one event to the store endpoint.
+func (h *SentryHook) send(b []byte) error {
+    req, err := http.NewRequest("POST", h.url, bytes.NewReader(b))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("X-Sentry-Auth", h.auth)
+    resp, err := h.client.Do(req)
+    if err != nil {
+        return err
+    }
+    resp.Body.Close()
+
+    if resp.StatusCode == http.StatusTooManyRequests {
+        wait := time.Minute
+        if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+            wait = time.Duration(s) * time.Second
+        }
+        h.mu.Lock()
+        h.retryAfter = time.Now().Add(wait)
+        h.mu.Unlock()
+    }
+    if resp.StatusCode >= 300 {
+        return fmt.Errorf("%s: %s", h.url, resp.Status)
+    }
+    return nil
+}
+
+// Close sends the queued events and stops the hook. Events fired after
+// Close are dropped.
+func (h *SentryHook) Close() error {
+    h.mu.Lock()
+    if !h.closed {
+        h.closed = true
+        close(h.events)
+    }
+    h.mu.Unlock()
+    <-h.done
+    return nil
+}
+
+// aralogDir is the directory of the aralog sources, whose frames are left
+// out of stack traces.
+var aralogDir = func() string {
+    _, file, _, _ := runtime.Caller(0)
+    return filepath.Dir(file)
+}()
+
+// callerStacktrace returns the stack of the calling goroutine, oldest frame
+// first, without the frames of aralog.
+func callerStacktrace() *sentryStacktrace {
+    pcs := make([]uintptr, 64)
+    frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+    var st sentryStacktrace
+    for {
+        f, more := frames.Next()
+        inAralog := filepath.Dir(f.File) == aralogDir && !strings.HasSuffix(f.File, "_test.go")
+        if !inAralog || len(st.Frames) > 0 {
+            st.Frames = append(st.Frames, sentryFrame{Function: f.Function, Filename: filepath.Base(f.File), AbsPath: f.File, Lineno: f.Line})
+        }
+        if !more {
+            break
+        }
+    }
+    for i, j := 0, len(st.Frames) - 1; i < j; i, j = i + 1, j - 1 {
+        st.Frames[i], st.Frames[j] = st.Frames[j], st.Frames[i]
+    }
+    return &st
+}
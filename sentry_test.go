@@ -0,0 +1,84 @@
+package aralog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSentryHook(t *testing.T) {
+	events := make(chan sentryEvent, 10)
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/store/" {
+			t.Errorf("posted to %s", r.URL.Path)
+		}
+		auth = r.Header.Get("X-Sentry-Auth")
+		var ev sentryEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Error(err)
+		}
+		events <- ev
+	}))
+	defer srv.Close()
+
+	hook, err := NewSentryHook(strings.Replace(srv.URL, "http://", "http://pub@", 1)+"/42", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(ioutil.Discard, "", 0)
+	logger.AddHook(hook)
+
+	logger.Info("not sent")
+	logger.With(F("order", 7)).Error("payment failed")
+	logger.Error("second")
+	logger.Error("over the rate limit")
+	hook.Close()
+	close(events)
+
+	var got []sentryEvent
+	for ev := range events {
+		got = append(got, ev)
+	}
+	if len(got) != 2 {
+		t.Fatalf("sent %d events, want 2", len(got))
+	}
+	ev := got[0]
+	if ev.Message != "payment failed" || ev.Level != "error" || ev.Extra["order"] != float64(7) {
+		t.Errorf("unexpected event %+v", ev)
+	}
+	frames := ev.Stacktrace.Frames
+	if len(frames) == 0 || !strings.HasSuffix(frames[len(frames)-1].Function, "TestSentryHook") {
+		t.Errorf("stack does not end in the test: %+v", frames)
+	}
+	if !strings.Contains(auth, "sentry_key=pub") {
+		t.Errorf("auth header %q", auth)
+	}
+}
+
+func TestNewSentryHookInvalid(t *testing.T) {
+	for _, dsn := range []string{"https://sentry.example.com/42", "https://key@sentry.example.com/", "::"} {
+		if _, err := NewSentryHook(dsn, 10); err == nil {
+			t.Errorf("%q accepted", dsn)
+		}
+	}
+}
+
+func TestSentryHookFireAfterClose(t *testing.T) {
+	hook, err := NewSentryHook("http://pub@127.0.0.1:1/42", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(ioutil.Discard, "", 0)
+	logger.AddHook(hook)
+	hook.Close()
+
+	logger.Error("after close")
+	if s := logger.Stats(); s.Dropped != 1 {
+		t.Errorf("dropped %d entries, want 1", s.Dropped)
+	}
+	hook.Close()
+}
@@ -0,0 +1,73 @@
+package aralog
+
+import (
+    "crypto"
+    "crypto/ed25519"
+    "crypto/sha512"
+    "encoding/base64"
+    "errors"
+    "io"
+    "io/ioutil"
+    "os"
+    "strings"
+)
+
+// SignatureSuffix is appended to the path of a file for its detached
+// signature.
+const SignatureSuffix = ".sig"
+
+// ErrBadSignature is returned by VerifyFile for a file which does not match
+// its signature.
+var ErrBadSignature = errors.New("aralog: signature mismatch")
+
+// SignFile signs the file at path with key and writes the signature, base64
+// encoded on one line, to path + SignatureSuffix. The file is hashed as it
+// is read and signed by Ed25519ph, so segments of any size can be signed.
+// Recipients holding the public key check the file by VerifyFile.
+func SignFile(path string, key ed25519.PrivateKey) error {
+    digest, err := fileDigest(path)
+    if err != nil {
+        return err
+    }
+    sig, err := key.Sign(nil, digest, &ed25519.Options{Hash: crypto.SHA512})
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(path + SignatureSuffix, []byte(base64.StdEncoding.EncodeToString(sig) + "\n"), 0600)
+}
+
+// VerifyFile checks the file at path against its signature made by SignFile.
+// It returns ErrBadSignature if the file was altered or signed by another
+// key.
+func VerifyFile(path string, pub ed25519.PublicKey) error {
+    b, err := ioutil.ReadFile(path + SignatureSuffix)
+    if err != nil {
+        return err
+    }
+    sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+    if err != nil {
+        return ErrBadSignature
+    }
+    digest, err := fileDigest(path)
+    if err != nil {
+        return err
+    }
+    if ed25519.VerifyWithOptions(pub, digest, sig, &ed25519.Options{Hash: crypto.SHA512}) != nil {
+        return ErrBadSignature
+    }
+    return nil
+}
+
+// fileDigest returns the SHA-512 of the file at path.
+func fileDigest(path string) ([]byte, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    h := sha512.New()
+    if _, err := io.Copy(h, f); err != nil {
+        return nil, err
+    }
+    return h.Sum(nil), nil
+}
@@ -0,0 +1,55 @@
+package aralog
+
+import (
+	"crypto/ed25519"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSigningKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink, err := NewRollFileSink(filepath.Join(dir, "app.log"), 1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sink.SetSigningKey(key)
+
+	logger := NewSinkLogger(sink, "", 0)
+	logger.Info("before rotation")
+	sink.size = sink.maxsize
+	logger.Info("after rotation")
+
+	files, _ := filepath.Glob(filepath.Join(dir, "app.log2*"))
+	var rolled string
+	for _, f := range files {
+		if !strings.HasSuffix(f, SignatureSuffix) {
+			rolled = f
+		}
+	}
+	if rolled == "" || len(files) != 2 {
+		t.Fatalf("expected rolled file and signature, got %v", files)
+	}
+	if err := VerifyFile(rolled, pub); err != nil {
+		t.Fatal(err)
+	}
+
+	f, _ := os.OpenFile(rolled, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("forged\n")
+	f.Close()
+	if err := VerifyFile(rolled, pub); err != ErrBadSignature {
+		t.Errorf("altered file: got %v", err)
+	}
+}
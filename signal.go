@@ -0,0 +1,58 @@
+package aralog
+
+import (
+    "os"
+    "os/signal"
+)
+
+// HandleVerbositySignals makes the process adjust log levels on signals:
+// SIGUSR1 raises the verbosity one step (ex: INFO to DEBUG) and SIGUSR2
+// lowers it one step, on the given loggers or on all registered loggers if
+// none are given. Call the returned function to stop handling the signals.
+// Platforms without SIGUSR1 and SIGUSR2, like Windows, ignore it.
+func HandleVerbositySignals(loggers ...*Logger) (stop func()) {
+    if len(verbositySignals) == 0 {
+        return func() {}
+    }
+
+    c := make(chan os.Signal, 1)
+    done := make(chan struct{})
+    signal.Notify(c, verbositySignals...)
+    go func() {
+        for {
+            select {
+            case <-done:
+                return
+            case sig := <-c:
+                step := -1 // more verbose
+                if sig == verbositySignals[1] {
+                    step = 1
+                }
+                targets := loggers
+                if len(targets) == 0 {
+                    targets = registeredLoggers()
+                }
+                for _, l := range targets {
+                    stepLevel(l, step)
+                }
+            }
+        }
+    }()
+
+    return func() {
+        signal.Stop(c)
+        close(done)
+    }
+}
+
+// stepLevel moves the level of l by step, staying within DEBUG and FATAL.
+func stepLevel(l *Logger, step int) {
+    level := l.Level() + Level(step)
+    if level < LevelDebug {
+        level = LevelDebug
+    }
+    if level > LevelFatal {
+        level = LevelFatal
+    }
+    l.SetLevel(level)
+}
@@ -0,0 +1,11 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package aralog
+
+import (
+    "os"
+)
+
+// verbositySignals is empty, there are no user signals on this platform.
+var verbositySignals []os.Signal
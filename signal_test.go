@@ -0,0 +1,35 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package aralog
+
+import (
+	"io/ioutil"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleVerbositySignals(t *testing.T) {
+	logger := New(ioutil.Discard, "", 0)
+	logger.SetLevel(LevelInfo)
+	stop := HandleVerbositySignals(logger)
+	defer stop()
+
+	for _, step := range []struct {
+		sig  syscall.Signal
+		want Level
+	}{
+		{syscall.SIGUSR1, LevelDebug},
+		{syscall.SIGUSR2, LevelInfo},
+		{syscall.SIGUSR2, LevelWarn},
+	} {
+		syscall.Kill(syscall.Getpid(), step.sig)
+		for i := 0; i < 100 && logger.Level() != step.want; i++ {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if logger.Level() != step.want {
+			t.Fatalf("expected %v after %v, got %v", step.want, step.sig, logger.Level())
+		}
+	}
+}
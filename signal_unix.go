@@ -0,0 +1,12 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package aralog
+
+import (
+    "os"
+    "syscall"
+)
+
+// verbositySignals raise and lower the verbosity, see HandleVerbositySignals.
+var verbositySignals = []os.Signal{syscall.SIGUSR1, syscall.SIGUSR2}
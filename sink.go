@@ -0,0 +1,194 @@
+package aralog
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "sync"
+    "time"
+)
+
+// Entry is a single log event as handed to a Sink.
+type Entry struct {
+    Time    time.Time
+    Level   Level
+    Logger  string // name the logger is registered under, see Register
+    Message string // the message without header
+    File    string // caller file, empty unless a caller flag is set
+    Line    int    // caller line, 0 unless a caller flag is set
+    Func    string // caller function with import path, empty unless a caller flag is set
+
+    Goroutine int64   // id of the logging goroutine, 0 unless Lgoroutine is set
+    Seq       uint64  // sequence number of the entry in its logger, starting at 1
+    Fields    []Field // fields bound to the logger, must not be modified
+
+    // Formatted is the complete line rendered by the Logger: header, message
+    // and trailing newline. It is only valid during the call to Sink.Write;
+    // sinks which keep it must copy it.
+    Formatted []byte
+
+    stats *counters // of the logger, to count the entry if it is dropped
+}
+
+// A Sink is a destination for log entries. The Logger serializes calls to
+// its sink, but a sink shared by several loggers must be safe for
+// concurrent use.
+type Sink interface {
+    // Write delivers one entry.
+    Write(e Entry) error
+    // Flush writes out any buffered entries.
+    Flush() error
+    // Close flushes and releases the resources of the sink.
+    Close() error
+    // Healthy reports whether the sink is currently able to deliver entries.
+    Healthy() bool
+}
+
+// WriterSink is a Sink writing the formatted entries to an io.Writer.
+type WriterSink struct {
+    mu  sync.Mutex
+    w   io.Writer
+    err error // last write error
+}
+
+// NewWriterSink creates a Sink writing to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+    return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Write(e Entry) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    _, s.err = s.w.Write(e.Formatted)
+    return s.err
+}
+
+// Flush calls Flush or Sync of the underlying writer if it has one.
+func (s *WriterSink) Flush() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    // syncing a terminal or pipe fails, and there is nothing to flush
+    if isStdStream(s.w) {
+        return nil
+    }
+    switch w := s.w.(type) {
+    case interface{ Flush() error }:
+        return w.Flush()
+    case interface{ Sync() error }:
+        return w.Sync()
+    }
+    return nil
+}
+
+// Close flushes and closes the underlying writer if it is an io.Closer.
+// The standard streams are never closed.
+func (s *WriterSink) Close() error {
+    err := s.Flush()
+    if isStdStream(s.w) {
+        return err
+    }
+    if c, ok := s.w.(io.Closer); ok {
+        if cerr := c.Close(); err == nil {
+            err = cerr
+        }
+    }
+    return err
+}
+
+// Healthy reports whether the last write succeeded.
+func (s *WriterSink) Healthy() bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err == nil
+}
+
+func isStdStream(w io.Writer) bool {
+    return w == io.Writer(os.Stdout) || w == io.Writer(os.Stderr)
+}
+
+// MultiSink is a Sink writing every entry to all of its sinks.
+type MultiSink []Sink
+
+// NewMultiSink creates a Sink duplicating its entries to all sinks.
+func NewMultiSink(sinks ...Sink) MultiSink {
+    return MultiSink(sinks)
+}
+
+// Write writes e to every sink and returns the first error.
+func (m MultiSink) Write(e Entry) error {
+    var err error
+    for _, s := range m {
+        if werr := s.Write(e); err == nil {
+            err = werr
+        }
+    }
+    return err
+}
+
+// Flush flushes every sink and returns the first error.
+func (m MultiSink) Flush() error {
+    var err error
+    for _, s := range m {
+        if ferr := s.Flush(); err == nil {
+            err = ferr
+        }
+    }
+    return err
+}
+
+// Close closes every sink and returns the first error.
+func (m MultiSink) Close() error {
+    var err error
+    for _, s := range m {
+        if cerr := s.Close(); err == nil {
+            err = cerr
+        }
+    }
+    return err
+}
+
+// Healthy reports whether all sinks are healthy.
+func (m MultiSink) Healthy() bool {
+    for _, s := range m {
+        if !s.Healthy() {
+            return false
+        }
+    }
+    return true
+}
+
+// Describe describes the sink with the name of the file it writes to, if any.
+func (s *WriterSink) Describe() SinkDescription {
+    d := SinkDescription{Type: "writer", Healthy: s.Healthy()}
+    switch w := s.w.(type) {
+    case *os.File:
+        d.Settings = map[string]interface{}{"file": w.Name()}
+    default:
+        d.Settings = map[string]interface{}{"writer": fmt.Sprintf("%T", w)}
+    }
+    return d
+}
+
+// Describe describes the sink and all of its sinks.
+func (m MultiSink) Describe() SinkDescription {
+    return SinkDescription{Type: "multi", Healthy: m.Healthy(), Sinks: describeSinks(m)}
+}
+
+// LastError returns the error of the last write, nil if it succeeded.
+func (s *WriterSink) LastError() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err
+}
+
+// LastError returns the first error of the sinks.
+func (m MultiSink) LastError() error {
+    for _, s := range m {
+        if err := SinkLastError(s); err != nil {
+            return err
+        }
+    }
+    return nil
+}
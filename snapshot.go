@@ -0,0 +1,92 @@
+package aralog
+
+import (
+    "time"
+)
+
+// Snapshot is the state of a Logger at one point in time, for periodic
+// self-reporting, see Logger.Snapshot.
+type Snapshot struct {
+    Stats
+    Time       time.Time
+    Files      []FileSnapshot // files written by RollFileSinks
+    QueueDepth int            // entries waiting in the QueueSinks
+}
+
+// FileSnapshot is the state of the file of a RollFileSink.
+type FileSnapshot struct {
+    Path         string
+    Size         uint      // current size of the active file
+    LastRotation time.Time // zero if the file was not rolled yet
+}
+
+// A QueueSink is a Sink which holds entries before delivering them.
+type QueueSink interface {
+    QueueDepth() int // entries not delivered yet
+}
+
+// sinkWrapper is implemented by the sinks delivering to other sinks.
+type sinkWrapper interface {
+    inner() []Sink
+}
+
+// Snapshot returns the counters of the logger, see Stats, with the state
+// of its files and queues, walking the sinks it writes to.
+func (l *Logger) Snapshot() Snapshot {
+    s := Snapshot{Time: time.Now(), Stats: l.Stats()}
+    var walk func(Sink)
+    walk = func(sink Sink) {
+        if f, ok := sink.(*RollFileSink); ok {
+            s.Files = append(s.Files, f.snapshot())
+        }
+        if q, ok := sink.(QueueSink); ok {
+            s.QueueDepth += q.QueueDepth()
+        }
+        if w, ok := sink.(sinkWrapper); ok {
+            for _, inner := range w.inner() {
+                walk(inner)
+            }
+        }
+    }
+    walk(l.Sink())
+    return s
+}
+
+func (m MultiSink) inner() []Sink { return m }
+
+func (s *SamplingSink) inner() []Sink { return []Sink{s.sink} }
+
+func (s *FilterSink) inner() []Sink { return []Sink{s.sink} }
+
+func (f *FallbackSink) inner() []Sink { return f.sinks }
+
+func (b *BreakerSink) inner() []Sink {
+    if b.fallback == nil {
+        return []Sink{b.primary}
+    }
+    return []Sink{b.primary, b.fallback}
+}
+
+func (s *DeadLetterSink) inner() []Sink { return []Sink{s.sink} }
+
+func (s *SpoolSink) inner() []Sink { return []Sink{s.sink} }
+
+func (s *bestEffortSink) inner() []Sink { return []Sink{s.Sink} }
+
+func (s *retrySink) inner() []Sink { return []Sink{s.Sink} }
+
+func (s *boundedSink) inner() []Sink { return []Sink{s.sink} }
+
+func (s *LineEndingSink) inner() []Sink { return []Sink{s.sink} }
+
+func (s *LineLengthSink) inner() []Sink { return []Sink{s.sink} }
+
+func (s *RouteSink) inner() []Sink {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    var sinks []Sink
+    for el := s.lru.Front(); el != nil; el = el.Next() {
+        sinks = append(sinks, el.Value.(*routeFile).sink)
+    }
+    return sinks
+}
@@ -0,0 +1,36 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file, err := NewRollFileSink(filepath.Join(dir, "app.log"), 1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewSinkLogger(NewMultiSink(NewDiscardSink(), NewSamplingSink(file, time.Second, 10, 10)), "", 0)
+	defer logger.Close()
+
+	file.size = file.maxsize
+	logger.Info("after rotation")
+	logger.Warn("second")
+
+	s := logger.Snapshot()
+	if s.Entries[LevelInfo] != 1 || s.Entries[LevelWarn] != 1 || s.Bytes != 22 {
+		t.Errorf("unexpected stats %+v", s.Stats)
+	}
+	if len(s.Files) != 1 || s.Files[0].Size != 22 || s.Files[0].LastRotation.IsZero() {
+		t.Errorf("unexpected files %+v", s.Files)
+	}
+}
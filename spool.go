@@ -0,0 +1,274 @@
+package aralog
+
+import (
+    "bufio"
+    "bytes"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "os"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// SpoolSink spools the entries of a remote sink to disk while it is
+// unreachable. Once a write fails, that entry and all the following ones
+// are appended to the spool file, so they survive restarts, and every
+// retry interval the spool is replayed to the sink in order. The position
+// of the last delivered entry is recorded in the file path + ".offset"
+// after every entry, so a replay cut by a failure or a restart resumes
+// without sending an entry twice; only an entry delivered right before the
+// process died may be. Once the spool is drained, entries go to the sink
+// directly again.
+type SpoolSink struct {
+    mu         sync.Mutex
+    sink       Sink
+    path       string
+    file       *os.File      // spool, opened for appending while spooling
+    reader     *bufio.Reader // of the spool, at offset
+    rfile      *os.File
+    offset     int64         // of the next entry to deliver
+    offsetFile *os.File
+    err        error         // last error of the spool files
+    retry      time.Duration // between replays
+    stop       chan struct{}
+    stopOnce   sync.Once
+}
+
+// NewSpoolSink creates a SpoolSink in front of sink, spooling to the file at
+// path and replaying it every retry, 10s if retry is not positive. Entries
+// left in the spool by a previous run are replayed first, except a last one
+// cut by a crash.
+func NewSpoolSink(sink Sink, path string, retry time.Duration) (*SpoolSink, error) {
+    if retry <= 0 {
+        retry = 10 * time.Second
+    }
+    s := &SpoolSink{sink: sink, path: path, retry: retry, stop: make(chan struct{})}
+    if _, err := os.Stat(path); err == nil {
+        if err := truncateTornLine(path); err != nil {
+            return nil, err
+        }
+        if b, err := ioutil.ReadFile(path + ".offset"); err == nil {
+            s.offset, _ = strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+        }
+        if err := s.open(); err != nil {
+            return nil, err
+        }
+    }
+    go s.run()
+    return s, nil
+}
+
+// truncateTornLine truncates the file at path after its last newline,
+// removing a line whose writing was cut by a crash.
+func truncateTornLine(path string) error {
+    f, err := os.OpenFile(path, os.O_RDWR, 0600)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+    fi, err := f.Stat()
+    if err != nil {
+        return err
+    }
+    end := fi.Size()
+    buf := make([]byte, 4096)
+    for end > 0 {
+        n := int64(len(buf))
+        if n > end {
+            n = end
+        }
+        if _, err := f.ReadAt(buf[:n], end - n); err != nil {
+            return err
+        }
+        if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+            end = end - n + int64(i) + 1
+            break
+        }
+        end -= n
+    }
+    if end == fi.Size() {
+        return nil
+    }
+    return f.Truncate(end)
+}
+
+// open opens the spool files. s.mu must be held unless s is new.
+func (s *SpoolSink) open() error {
+    f, err := os.OpenFile(s.path, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0600)
+    if err != nil {
+        return err
+    }
+    rf, err := os.Open(s.path)
+    if err == nil {
+        _, err = rf.Seek(s.offset, io.SeekStart)
+    }
+    var of *os.File
+    if err == nil {
+        of, err = os.OpenFile(s.path + ".offset", os.O_CREATE | os.O_WRONLY, 0600)
+    }
+    if err != nil {
+        f.Close()
+        if rf != nil {
+            rf.Close()
+        }
+        return err
+    }
+    s.file, s.rfile, s.reader, s.offsetFile = f, rf, bufio.NewReader(rf), of
+    return nil
+}
+
+// Write writes e to the sink, or to the spool if the sink failed or the
+// spool is not drained yet. The failure of the sink is passed to the error
+// handler; an error is only returned if the entry could not be spooled.
+func (s *SpoolSink) Write(e Entry) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if s.file == nil {
+        err := s.sink.Write(e)
+        if err == nil {
+            return nil
+        }
+        reportError(fmt.Errorf("aralog: %v, spooling to %s", err, s.path))
+        if s.err = s.open(); s.err != nil {
+            return err
+        }
+    }
+    b, err := marshalEntry(e)
+    if err == nil {
+        _, err = s.file.Write(b)
+    }
+    s.err = err
+    return err
+}
+
+func (s *SpoolSink) run() {
+    ticker := time.NewTicker(s.retry)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-s.stop:
+            return
+        case <-ticker.C:
+            if err := s.Replay(); err != nil {
+                internalError("spool %s: %v", s.path, err)
+            }
+        }
+    }
+}
+
+// Replay delivers the spooled entries to the sink in order, until the spool
+// is drained or the sink fails. It is called every retry interval.
+func (s *SpoolSink) Replay() error {
+    for {
+        done, err := s.replayOne()
+        if done || err != nil {
+            return err
+        }
+    }
+}
+
+// replayOne delivers the next spooled entry, and removes the spool once it
+// is drained.
+func (s *SpoolSink) replayOne() (bool, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.file == nil {
+        return true, nil
+    }
+
+    line, err := s.reader.ReadBytes('\n')
+    if err == io.EOF {
+        if len(line) > 0 {
+            reportError(fmt.Errorf("aralog: spool %s: skipping torn last entry", s.path))
+        }
+        return true, s.drained()
+    }
+    if err != nil {
+        return true, err
+    }
+    if e, err := unmarshalEntry(line); err != nil {
+        reportError(fmt.Errorf("aralog: spool %s: skipping corrupt entry: %v", s.path, err))
+    } else if err = s.sink.Write(e); err != nil {
+        // deliver it again on the next replay
+        s.rfile.Seek(s.offset, io.SeekStart)
+        s.reader.Reset(s.rfile)
+        return true, nil
+    }
+    s.offset += int64(len(line))
+    _, err = s.offsetFile.WriteAt([]byte(fmt.Sprintf("%020d\n", s.offset)), 0)
+    return false, err
+}
+
+// drained closes and removes the spool files. s.mu must be held.
+func (s *SpoolSink) drained() error {
+    s.closeFiles()
+    s.offset = 0
+    err := os.Remove(s.path)
+    if rerr := os.Remove(s.path + ".offset"); err == nil {
+        err = rerr
+    }
+    return err
+}
+
+func (s *SpoolSink) closeFiles() {
+    if s.file != nil {
+        s.file.Close()
+        s.rfile.Close()
+        s.offsetFile.Close()
+        s.file, s.rfile, s.reader, s.offsetFile = nil, nil, nil, nil
+    }
+}
+
+// Spooled reports whether entries are waiting in the spool.
+func (s *SpoolSink) Spooled() bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.file != nil
+}
+
+// Flush flushes the sink.
+func (s *SpoolSink) Flush() error {
+    return s.sink.Flush()
+}
+
+// Close stops the replays and closes the sink. The spooled entries are kept
+// for the next run.
+func (s *SpoolSink) Close() error {
+    s.stopOnce.Do(func() {
+        close(s.stop)
+    })
+    s.mu.Lock()
+    s.closeFiles()
+    s.mu.Unlock()
+    return s.sink.Close()
+}
+
+// Healthy reports whether the spool is drained and the sink healthy.
+func (s *SpoolSink) Healthy() bool {
+    return !s.Spooled() && s.sink.Healthy()
+}
+
+// LastError returns the last error of the sink, or of the spool files if
+// the sink has none.
+func (s *SpoolSink) LastError() error {
+    if err := SinkLastError(s.sink); err != nil {
+        return err
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err
+}
+
+// Describe describes the spool and the sink.
+func (s *SpoolSink) Describe() SinkDescription {
+    return SinkDescription{
+        Type:     "spool",
+        Healthy:  s.Healthy(),
+        Settings: map[string]interface{}{"path": s.path, "spooled": s.Spooled(), "retry": s.retry.String()},
+        Sinks:    []SinkDescription{DescribeSink(s.sink)},
+    }
+}
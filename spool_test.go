@@ -0,0 +1,128 @@
+package aralog
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakySink is a RingSink which fails while down is set, and for the
+// entries with the message reject.
+type flakySink struct {
+	*RingSink
+	mu     sync.Mutex
+	down   bool
+	reject string
+}
+
+func (s *flakySink) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func (s *flakySink) Write(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down || e.Message == s.reject {
+		return errors.New("collector unreachable")
+	}
+	return s.RingSink.Write(e)
+}
+
+func TestSpoolSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spool")
+
+	remote := &flakySink{RingSink: NewRingSink(10)}
+	sink, err := NewSpoolSink(remote, path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewSinkLogger(sink, "", 0)
+	logger.Info("1")
+	remote.setDown(true)
+	logger.Info("2")
+	remote.setDown(false)
+	logger.Info("3") // spooled behind 2 to keep the order
+	if !sink.Spooled() {
+		t.Fatal("nothing spooled")
+	}
+
+	// a restart with a partly replayed spool
+	remote.reject = "3"
+	sink.Replay()
+	sink.Close()
+	remote.reject = ""
+	sink, err = NewSpoolSink(remote, path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if err := sink.Replay(); err != nil {
+		t.Fatal(err)
+	}
+	logger = NewSinkLogger(sink, "", 0)
+	logger.Info("4")
+
+	var got []string
+	for _, e := range remote.Entries() {
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, ",") != "1,2,3,4" {
+		t.Errorf("delivered %v", got)
+	}
+	if sink.Spooled() {
+		t.Error("spool not drained")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("spool file not removed")
+	}
+}
+
+func TestSpoolSinkTornTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spool")
+
+	remote := &flakySink{RingSink: NewRingSink(10)}
+	remote.setDown(true)
+	sink, err := NewSpoolSink(remote, path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewSinkLogger(sink, "", 0).Info("1")
+	sink.Close()
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"msg":"2`)
+	f.Close()
+
+	remote.setDown(false)
+	if sink, err = NewSpoolSink(remote, path, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	NewSinkLogger(sink, "", 0).Info("3")
+	if err := sink.Replay(); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range remote.Entries() {
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, ",") != "1,3" || sink.Spooled() {
+		t.Errorf("delivered %v", got)
+	}
+}
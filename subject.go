@@ -0,0 +1,128 @@
+package aralog
+
+import (
+    "bufio"
+    "bytes"
+    "io"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+)
+
+// SubjectKey is the key of the field tagging entries with the data subject,
+// the person they are about, see WithSubject.
+const SubjectKey = "subject"
+
+// WithSubject creates a child Logger tagging every entry with the data
+// subject id, so the entries can later be erased by EraseSubject.
+func (l *Logger) WithSubject(id string) *Logger {
+    return l.With(F(SubjectKey, id))
+}
+
+// EraseSubject removes the entries tagged with the data subject id by
+// WithSubject from the log files at paths, in text or JSON format, to
+// honour erasure requests on retained logs. Each file is rewritten to a
+// temporary file which replaces it, keeping its mode. It returns the number
+// of entries removed. The files must not be written to meanwhile; rolled
+// files only, or the active one of a stopped logger. Signatures and audit
+// hash chains of rewritten files no longer verify.
+func EraseSubject(id string, paths ...string) (int, error) {
+    var text, js []byte
+    appendFields(&text, []Field{F(SubjectKey, id)})
+    js = append(js, ',')
+    appendJSON(&js, SubjectKey)
+    js = append(js, ':')
+    appendJSON(&js, id)
+
+    erased := 0
+    for _, path := range paths {
+        n, err := eraseFile(path, func(line []byte) bool {
+            return bytes.Contains(line, js) || hasTextField(line, text)
+        })
+        erased += n
+        if err != nil {
+            return erased, err
+        }
+    }
+    return erased, nil
+}
+
+// hasTextField reports whether line holds field, " key=value", followed by
+// a space or the end of the line.
+func hasTextField(line, field []byte) bool {
+    for {
+        i := bytes.Index(line, field[1:])
+        if i < 0 {
+            return false
+        }
+        end := i + len(field) - 1
+        if i > 0 && line[i - 1] == ' ' && (end == len(line) || line[end] == ' ' || line[end] == '\n') {
+            return true
+        }
+        line = line[i + 1:]
+    }
+}
+
+// eraseFile rewrites the file at path without the lines matching erase.
+func eraseFile(path string, erase func(line []byte) bool) (int, error) {
+    in, err := os.Open(path)
+    if err != nil {
+        return 0, err
+    }
+    defer in.Close()
+    fi, err := in.Stat()
+    if err != nil {
+        return 0, err
+    }
+    tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path) + ".erase")
+    if err != nil {
+        return 0, err
+    }
+    defer os.Remove(tmp.Name()) // fails once renamed
+
+    w := bufio.NewWriter(tmp)
+    erased, err := eraseLines(in, w, erase)
+    if err == nil {
+        err = w.Flush()
+    }
+    if err == nil {
+        err = tmp.Chmod(fi.Mode())
+    }
+    if err == nil {
+        err = tmp.Sync()
+    }
+    if cerr := tmp.Close(); err == nil {
+        err = cerr
+    }
+    if err != nil {
+        return 0, err
+    }
+    if erased == 0 {
+        return 0, nil
+    }
+    return erased, os.Rename(tmp.Name(), path)
+}
+
+// eraseLines copies the lines of r to w except those erase selects and
+// returns how many it left out. A read error other than io.EOF is
+// returned, the copy is incomplete then.
+func eraseLines(r io.Reader, w io.Writer, erase func(line []byte) bool) (int, error) {
+    erased := 0
+    br := bufio.NewReader(r)
+    for {
+        line, rerr := br.ReadBytes('\n')
+        if len(line) > 0 {
+            if erase(line) {
+                erased++
+            } else if _, err := w.Write(line); err != nil {
+                return 0, err
+            }
+        }
+        if rerr == io.EOF {
+            return erased, nil
+        }
+        if rerr != nil {
+            return 0, rerr
+        }
+    }
+}
@@ -0,0 +1,100 @@
+package aralog
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEraseSubject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	text, err := os.Create(filepath.Join(dir, "text.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(text, "", 0)
+	logger.WithSubject("u1").Info("signed up")
+	logger.WithSubject("u12").Info("other user")
+	logger.WithSubject("u1").With(F("plan", "pro")).Info("upgraded")
+	logger.Info("unrelated")
+	text.Close()
+
+	js, err := os.Create(filepath.Join(dir, "json.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger = New(js, "", 0)
+	logger.SetEncoder(&JSONEncoder{TimeLayout: "-"})
+	logger.WithSubject("u1").Info("signed up")
+	logger.WithSubject("u12").Info("other user")
+	js.Close()
+
+	n, err := EraseSubject("u1", text.Name(), js.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("erased %d entries, want 3", n)
+	}
+	b, _ := ioutil.ReadFile(text.Name())
+	if string(b) != "other user subject=u12\nunrelated\n" {
+		t.Errorf("text file: %q", b)
+	}
+	b, _ = ioutil.ReadFile(js.Name())
+	if string(b) != `{"time":"-","level":"INFO","msg":"other user","subject":"u12"}`+"\n" {
+		t.Errorf("json file: %q", b)
+	}
+}
+
+// failingReader returns its data, then err instead of io.EOF.
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestEraseLinesReadError(t *testing.T) {
+	readErr := errors.New("disk failure")
+	r := &failingReader{data: []byte("keep\nsubject=jane drop\npartial"), err: readErr}
+	var out bytes.Buffer
+	n, err := eraseLines(r, &out, func(line []byte) bool { return bytes.Contains(line, []byte("jane")) })
+	if err != readErr || n != 0 {
+		t.Errorf("got %d, %v, want the read error", n, err)
+	}
+}
+
+func TestEraseFileReadError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// reading a directory fails after it is opened
+	path := filepath.Join(dir, "app.log")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eraseFile(path, func([]byte) bool { return true }); err == nil {
+		t.Error("read error not returned")
+	}
+	if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+		t.Errorf("original replaced: %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package aralog
+
+import (
+    "bufio"
+    "io"
+    "os"
+    "strings"
+    "sync"
+    "time"
+)
+
+// tailInterval is how often a TailReader polls its file at its end.
+var tailInterval = 200 * time.Millisecond
+
+// TailReader follows a live log file like tail -F, decoding each new line
+// into an entry delivered on a channel. When the file is rolled, renamed
+// away and recreated, the rest of the old file is read before the new one
+// is followed from its start; a file truncated in place is read again from
+// its start. Lines which do not decode are skipped.
+type TailReader struct {
+    path     string
+    decode   func(line string) (Entry, error)
+    file     *os.File
+    info     os.FileInfo // of file
+    r        *bufio.Reader
+    offset   int64
+    partial  string // line read without its newline yet
+    entries  chan Entry
+    stop     chan struct{}
+    stopOnce sync.Once
+    mu       sync.Mutex
+    err      error
+}
+
+// NewTailReader follows the file at path, decoding lines by decode, ex:
+// NewParser("", aralog.LstdFlags).Parse. Only the lines written after the
+// call are read, unless fromStart is set.
+func NewTailReader(path string, decode func(line string) (Entry, error), fromStart bool) (*TailReader, error) {
+    t := &TailReader{path: path, decode: decode, entries: make(chan Entry), stop: make(chan struct{})}
+    if err := t.open(); err != nil {
+        return nil, err
+    }
+    if !fromStart {
+        off, err := t.file.Seek(0, io.SeekEnd)
+        if err != nil {
+            t.file.Close()
+            return nil, err
+        }
+        t.offset = off
+    }
+    go t.run()
+    return t, nil
+}
+
+func (t *TailReader) open() error {
+    f, err := os.Open(t.path)
+    if err != nil {
+        return err
+    }
+    fi, err := f.Stat()
+    if err != nil {
+        f.Close()
+        return err
+    }
+    t.file, t.info, t.r, t.offset, t.partial = f, fi, bufio.NewReader(f), 0, ""
+    return nil
+}
+
+// Entries returns the channel of the entries, closed once the reader stops.
+func (t *TailReader) Entries() <-chan Entry {
+    return t.entries
+}
+
+// Err returns the error which stopped the reader, nil if Stop did.
+func (t *TailReader) Err() error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return t.err
+}
+
+// Stop stops following the file and closes the channel of the entries.
+func (t *TailReader) Stop() {
+    t.stopOnce.Do(func() {
+        close(t.stop)
+    })
+}
+
+func (t *TailReader) run() {
+    defer close(t.entries)
+    defer func() {
+        t.file.Close()
+    }()
+    for {
+        if !t.readLines() || !t.follow() {
+            return
+        }
+        select {
+        case <-t.stop:
+            return
+        case <-time.After(tailInterval):
+        }
+    }
+}
+
+// readLines delivers the complete lines up to the end of the file. It
+// returns false if the reader was stopped meanwhile.
+func (t *TailReader) readLines() bool {
+    for {
+        line, err := t.r.ReadString('\n')
+        t.offset += int64(len(line))
+        if err != nil {
+            t.partial += line
+            return true
+        }
+        line, t.partial = t.partial + line, ""
+        if !t.deliver(line) {
+            return false
+        }
+    }
+}
+
+// deliver decodes line and sends its entry, lines which do not decode are
+// skipped. It returns false if the reader was stopped meanwhile.
+func (t *TailReader) deliver(line string) bool {
+    e, err := t.decode(strings.TrimSuffix(line, "\n"))
+    if err != nil {
+        return true
+    }
+    select {
+    case t.entries <- e:
+        return true
+    case <-t.stop:
+        return false
+    }
+}
+
+// follow switches to the file now at the path once the followed one was
+// rolled, after reading the lines written to it since the last read. It
+// returns false if the reader was stopped or failed.
+func (t *TailReader) follow() bool {
+    moved, err := t.checkRotation()
+    if err == nil && moved {
+        // the last line of the old file is complete even without newline
+        if !t.readLines() || len(t.partial) > 0 && !t.deliver(t.partial) {
+            return false
+        }
+        t.file.Close()
+        err = t.open()
+    }
+    if err != nil {
+        t.mu.Lock()
+        t.err = err
+        t.mu.Unlock()
+        return false
+    }
+    return true
+}
+
+// checkRotation reports whether another file is now at the path, the
+// followed one was renamed away, and starts over if it was truncated.
+func (t *TailReader) checkRotation() (bool, error) {
+    fi, err := os.Stat(t.path)
+    if os.IsNotExist(err) {
+        return false, nil // renamed, its successor is not created yet
+    }
+    if err != nil {
+        return false, err
+    }
+    if !os.SameFile(fi, t.info) {
+        return true, nil
+    }
+    if fi.Size() < t.offset {
+        if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+            return false, err
+        }
+        t.r.Reset(t.file)
+        t.offset, t.partial = 0, ""
+    }
+    return false, nil
+}
@@ -0,0 +1,112 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailReader(t *testing.T) {
+	defer func(d time.Duration) { tailInterval = d }(tailInterval)
+	tailInterval = 10 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+	if err := ioutil.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tail, err := NewTailReader(path, NewParser("", 0).Parse, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tail.Stop()
+
+	sink, err := NewRollFileSink(path, 1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	logger := NewSinkLogger(sink, "", 0)
+	next := func() string {
+		select {
+		case e := <-tail.Entries():
+			return e.Message
+		case <-time.After(2 * time.Second):
+			return "timeout"
+		}
+	}
+
+	logger.Info("first")
+	if got := next(); got != "first" {
+		t.Fatalf("got %q, want first", got)
+	}
+	logger.Info("before rotation")
+	sink.size = sink.maxsize
+	logger.Info("after rotation")
+	for _, want := range []string{"before rotation", "after rotation"} {
+		if got := next(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	tail.Stop()
+	for range tail.Entries() {
+	}
+	if tail.Err() != nil {
+		t.Error(tail.Err())
+	}
+}
+
+func TestTailReaderRenameBetweenPolls(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aralog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+	if err := ioutil.WriteFile(path, []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// driven by hand instead of by run, to write between the polls
+	tail := &TailReader{path: path, decode: NewParser("", 0).Parse, entries: make(chan Entry, 10), stop: make(chan struct{})}
+	if err := tail.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { tail.file.Close() }()
+	tail.readLines()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("before rename\nunterminated")
+	f.Close()
+	if err := os.Rename(path, path+"20090123012323"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte("new file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if !tail.follow() || !tail.readLines() {
+		t.Fatalf("reader stopped: %v", tail.Err())
+	}
+
+	close(tail.entries)
+	var got []string
+	for e := range tail.entries {
+		got = append(got, e.Message)
+	}
+	want := []string{"first", "before rename", "unterminated", "new file"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
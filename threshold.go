@@ -0,0 +1,57 @@
+package aralog
+
+import (
+    "sync"
+    "time"
+)
+
+// ThresholdHook is a Hook calling a function when more than N entries at or
+// above a level occur within a window, so a service can escalate by itself
+// during an incident. After an alert the count starts over.
+type ThresholdHook struct {
+    mu     sync.Mutex
+    level  Level
+    n      int
+    window time.Duration
+    times  []time.Time // of the recent entries, oldest first
+    alert  func(count int, last Entry)
+}
+
+// NewThresholdHook creates a ThresholdHook calling alert with the number of
+// entries and the last of them when more than n entries at level or above
+// occur within window. alert runs in its own goroutine and may log, ex:
+//
+//	l.AddHook(aralog.NewThresholdHook(aralog.LevelError, 100, time.Minute, page))
+func NewThresholdHook(level Level, n int, window time.Duration, alert func(count int, last Entry)) *ThresholdHook {
+    return &ThresholdHook{level: level, n: n, window: window, alert: alert}
+}
+
+// Levels returns the levels from the threshold level up.
+func (h *ThresholdHook) Levels() []Level {
+    var levels []Level
+    for lv := h.level; lv <= LevelFatal; lv++ {
+        levels = append(levels, lv)
+    }
+    return levels
+}
+
+// Fire counts e and calls the alert function if the threshold is exceeded.
+func (h *ThresholdHook) Fire(e *Entry) error {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    start := e.Time.Add(-h.window)
+    i := 0
+    for i < len(h.times) && !h.times[i].After(start) {
+        i++
+    }
+    h.times = append(h.times[i:], e.Time)
+    if len(h.times) > h.n {
+        count, last := len(h.times), *e
+        last.Fields = append([]Field(nil), e.Fields...)
+        last.Formatted = nil
+        h.times = nil
+        go h.alert(count, last)
+    }
+    return nil
+}
@@ -0,0 +1,38 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestThresholdHook(t *testing.T) {
+	logger := New(ioutil.Discard, "", 0)
+	alerts := make(chan int, 10)
+	logger.AddHook(NewThresholdHook(LevelError, 3, time.Minute, func(count int, last Entry) {
+		if last.Message != "boom 4" {
+			t.Errorf("last entry %q", last.Message)
+		}
+		alerts <- count
+	}))
+
+	for i := 1; i <= 4; i++ {
+		logger.Warn("ignored")
+		logger.Error("boom %d", i)
+	}
+	select {
+	case n := <-alerts:
+		if n != 4 {
+			t.Errorf("alert with %d entries, want 4", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no alert")
+	}
+
+	logger.Error("after alert")
+	select {
+	case <-alerts:
+		t.Error("alert without exceeding the threshold again")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
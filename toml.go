@@ -0,0 +1,242 @@
+package aralog
+
+import (
+    "encoding/json"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// parseTOML parses the subset of TOML used by configuration files: tables,
+// arrays of tables, dotted keys, and single line values (strings, numbers,
+// booleans and arrays of them). Multi-line strings, inline tables and dates
+// are not supported.
+func parseTOML(doc string) (map[string]interface{}, error) {
+    root := map[string]interface{}{}
+    table := root
+    for i, raw := range strings.Split(doc, "\n") {
+        num := i + 1
+        line := strings.TrimSpace(stripTOMLComment(raw))
+        if line == "" {
+            continue
+        }
+
+        var err error
+        switch {
+        case strings.HasPrefix(line, "[["):
+            if !strings.HasSuffix(line, "]]") {
+                return nil, fmt.Errorf("line %d: unterminated table header", num)
+            }
+            var keys []string
+            if keys, err = splitTOMLKey(line[2:len(line) - 2]); err == nil {
+                table, err = tomlArrayTable(root, keys)
+            }
+        case strings.HasPrefix(line, "["):
+            if !strings.HasSuffix(line, "]") {
+                return nil, fmt.Errorf("line %d: unterminated table header", num)
+            }
+            var keys []string
+            if keys, err = splitTOMLKey(line[1:len(line) - 1]); err == nil {
+                table, err = tomlTable(root, keys)
+            }
+        default:
+            eq := strings.IndexByte(line, '=')
+            if eq < 0 {
+                return nil, fmt.Errorf("line %d: expected key = value", num)
+            }
+            var keys []string
+            if keys, err = splitTOMLKey(line[:eq]); err != nil {
+                break
+            }
+            var v interface{}
+            if v, err = parseTOMLValue(strings.TrimSpace(line[eq + 1:])); err != nil {
+                break
+            }
+            var t map[string]interface{}
+            if t, err = tomlTable(table, keys[:len(keys) - 1]); err == nil {
+                t[keys[len(keys) - 1]] = v
+            }
+        }
+        if err != nil {
+            return nil, fmt.Errorf("line %d: %v", num, err)
+        }
+    }
+    return root, nil
+}
+
+// stripTOMLComment removes a # comment which is not inside a string.
+func stripTOMLComment(s string) string {
+    var quote byte
+    for i := 0; i < len(s); i++ {
+        switch c := s[i]; {
+        case quote == '"' && c == '\\':
+            i++
+        case quote != 0:
+            if c == quote {
+                quote = 0
+            }
+        case c == '"' || c == '\'':
+            quote = c
+        case c == '#':
+            return s[:i]
+        }
+    }
+    return s
+}
+
+// splitTOMLKey splits a dotted key, ex: logging."my.key".level
+func splitTOMLKey(s string) ([]string, error) {
+    var keys []string
+    for _, k := range splitTOMLList(s, '.') {
+        k = strings.TrimSpace(k)
+        if k == "" {
+            return nil, fmt.Errorf("empty key")
+        }
+        if len(k) >= 2 && (k[0] == '"' || k[0] == '\'') {
+            k = k[1:len(k) - 1]
+        }
+        keys = append(keys, k)
+    }
+    return keys, nil
+}
+
+// splitTOMLList splits s at sep, ignoring separators inside strings and
+// nested arrays. It also splits the YAML flow sequences.
+func splitTOMLList(s string, sep byte) []string {
+    var parts []string
+    var quote byte
+    depth, start := 0, 0
+    for i := 0; i < len(s); i++ {
+        switch c := s[i]; {
+        case quote == '"' && c == '\\':
+            i++
+        case quote != 0:
+            if c == quote {
+                quote = 0
+            }
+        case c == '"' || c == '\'':
+            quote = c
+        case c == '[':
+            depth++
+        case c == ']':
+            depth--
+        case c == sep && depth == 0:
+            parts = append(parts, s[start:i])
+            start = i + 1
+        }
+    }
+    return append(parts, s[start:])
+}
+
+// tomlTable returns the table at keys below t, creating missing tables.
+// Keys naming an array of tables refer to its last table.
+func tomlTable(t map[string]interface{}, keys []string) (map[string]interface{}, error) {
+    for _, k := range keys {
+        switch v := t[k].(type) {
+        case nil:
+            next := map[string]interface{}{}
+            t[k] = next
+            t = next
+        case map[string]interface{}:
+            t = v
+        case []interface{}:
+            if len(v) == 0 {
+                return nil, fmt.Errorf("cannot redefine array %q as table", k)
+            }
+            last, ok := v[len(v) - 1].(map[string]interface{})
+            if !ok {
+                return nil, fmt.Errorf("key %q is not a table", k)
+            }
+            t = last
+        default:
+            return nil, fmt.Errorf("key %q is not a table", k)
+        }
+    }
+    return t, nil
+}
+
+// tomlArrayTable appends a new table to the array of tables at keys.
+func tomlArrayTable(root map[string]interface{}, keys []string) (map[string]interface{}, error) {
+    parent, err := tomlTable(root, keys[:len(keys) - 1])
+    if err != nil {
+        return nil, err
+    }
+    k := keys[len(keys) - 1]
+    t := map[string]interface{}{}
+    switch v := parent[k].(type) {
+    case nil:
+        parent[k] = []interface{}{t}
+    case []interface{}:
+        parent[k] = append(v, t)
+    default:
+        return nil, fmt.Errorf("key %q is not an array of tables", k)
+    }
+    return t, nil
+}
+
+func parseTOMLValue(s string) (interface{}, error) {
+    switch {
+    case s == "":
+        return nil, fmt.Errorf("missing value")
+    case strings.HasPrefix(s, "\"\"\"") || strings.HasPrefix(s, "'''"):
+        return nil, fmt.Errorf("multi-line strings are not supported")
+    case s[0] == '"':
+        var v string
+        err := json.Unmarshal([]byte(s), &v)
+        return v, err
+    case s[0] == '\'':
+        if len(s) < 2 || s[len(s) - 1] != '\'' {
+            return nil, fmt.Errorf("unterminated string %s", s)
+        }
+        return s[1:len(s) - 1], nil
+    case s[0] == '[':
+        if s[len(s) - 1] != ']' {
+            return nil, fmt.Errorf("unterminated array %s", s)
+        }
+        arr := []interface{}{}
+        for _, item := range splitTOMLList(s[1:len(s) - 1], ',') {
+            item = strings.TrimSpace(item)
+            if item == "" {
+                continue // trailing comma
+            }
+            v, err := parseTOMLValue(item)
+            if err != nil {
+                return nil, err
+            }
+            arr = append(arr, v)
+        }
+        return arr, nil
+    case s[0] == '{':
+        return nil, fmt.Errorf("inline tables are not supported")
+    case s == "true":
+        return true, nil
+    case s == "false":
+        return false, nil
+    }
+
+    n := strings.Replace(s, "_", "", -1)
+    if i, err := parseInteger(n); err == nil {
+        return i, nil
+    }
+    if f, err := strconv.ParseFloat(n, 64); err == nil {
+        return f, nil
+    }
+    return nil, fmt.Errorf("invalid value %s", s)
+}
+
+// parseInteger parses a decimal integer, or a hexadecimal, octal or binary
+// one with a 0x, 0o or 0b prefix. Unlike strconv.ParseInt with base 0, a
+// leading 0 does not make it octal.
+func parseInteger(s string) (int64, error) {
+    if len(s) > 2 && s[0] == '0' {
+        switch s[1] {
+        case 'x':
+            return strconv.ParseInt(s[2:], 16, 64)
+        case 'o':
+            return strconv.ParseInt(s[2:], 8, 64)
+        case 'b':
+            return strconv.ParseInt(s[2:], 2, 64)
+        }
+    }
+    return strconv.ParseInt(s, 10, 64)
+}
@@ -0,0 +1,136 @@
+package aralog
+
+import (
+    "context"
+    "net/http"
+    "strings"
+)
+
+// TraceContext identifies a span of a distributed trace, as propagated by
+// the W3C traceparent or the B3 headers.
+type TraceContext struct {
+    TraceID string // 32 lowercase hexadecimal digits
+    SpanID  string // 16 lowercase hexadecimal digits
+    Sampled bool
+}
+
+// Fields returns the trace_id and span_id fields of t.
+func (t TraceContext) Fields() []Field {
+    return []Field{F("trace_id", t.TraceID), F("span_id", t.SpanID)}
+}
+
+// WithTrace returns a copy of ctx carrying t.
+func WithTrace(ctx context.Context, t TraceContext) context.Context {
+    return context.WithValue(ctx, traceKey, t)
+}
+
+// TraceFromContext returns the TraceContext carried by ctx.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+    t, ok := ctx.Value(traceKey).(TraceContext)
+    return t, ok
+}
+
+// ParseTraceparent parses a W3C traceparent header, ex:
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceparent(s string) (TraceContext, bool) {
+    s = strings.TrimSpace(s)
+    if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' || len(s) > 55 && s[55] != '-' {
+        return TraceContext{}, false
+    }
+    version, flags := s[0:2], s[53:55]
+    if !isHex(version) || version == "ff" || version == "00" && len(s) != 55 || !isHex(flags) {
+        return TraceContext{}, false
+    }
+    t := TraceContext{TraceID: s[3:35], SpanID: s[36:52], Sampled: hexDigit(flags[1]) & 1 != 0}
+    return t, validTraceIDs(t)
+}
+
+// ParseB3 parses the B3 propagation headers of h: the single b3 header,
+// ex: b3: 80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1, or else the
+// X-B3-TraceId, X-B3-SpanId and X-B3-Sampled headers. 64 bit trace IDs are
+// padded to 128 bits.
+func ParseB3(h http.Header) (TraceContext, bool) {
+    var traceID, spanID, sampled string
+    if b3 := strings.TrimSpace(h.Get("b3")); len(b3) > 0 {
+        parts := strings.Split(b3, "-")
+        if len(parts) < 2 {
+            return TraceContext{}, false // sampling decision only
+        }
+        traceID, spanID = parts[0], parts[1]
+        if len(parts) > 2 {
+            sampled = parts[2]
+        }
+    } else {
+        traceID, spanID = h.Get("X-B3-TraceId"), h.Get("X-B3-SpanId")
+        sampled = h.Get("X-B3-Sampled")
+        if h.Get("X-B3-Flags") == "1" {
+            sampled = "d"
+        }
+    }
+
+    traceID, spanID = strings.ToLower(traceID), strings.ToLower(spanID)
+    if len(traceID) == 16 {
+        traceID = "0000000000000000" + traceID
+    }
+    t := TraceContext{TraceID: traceID, SpanID: spanID}
+    switch sampled {
+    case "1", "d", "true":
+        t.Sampled = true
+    }
+    return t, validTraceIDs(t)
+}
+
+// TraceFromRequest returns the TraceContext propagated by the headers of
+// r, from traceparent if it is valid, from the B3 headers otherwise.
+func TraceFromRequest(r *http.Request) (TraceContext, bool) {
+    if t, ok := ParseTraceparent(r.Header.Get("traceparent")); ok {
+        return t, true
+    }
+    return ParseB3(r.Header)
+}
+
+// TraceMiddleware returns a middleware binding the trace of every request,
+// see TraceFromRequest, to its context with WithTrace, and the trace_id and
+// span_id fields to the logger of its context, see FromContext. l is used
+// if the context carries no logger yet.
+func TraceMiddleware(l *Logger) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            t, ok := TraceFromRequest(r)
+            if !ok {
+                next.ServeHTTP(w, r)
+                return
+            }
+            log := FromContext(r.Context())
+            if log == nil {
+                log = l
+            }
+            ctx := NewContext(WithTrace(r.Context(), t), log.With(t.Fields()...))
+            next.ServeHTTP(w, r.WithContext(ctx))
+        })
+    }
+}
+
+// validTraceIDs reports whether the IDs of t have the right length, are
+// lowercase hexadecimal and not all zeros.
+func validTraceIDs(t TraceContext) bool {
+    return len(t.TraceID) == 32 && len(t.SpanID) == 16 &&
+        isHex(t.TraceID) && isHex(t.SpanID) &&
+        strings.Trim(t.TraceID, "0") != "" && strings.Trim(t.SpanID, "0") != ""
+}
+
+func isHex(s string) bool {
+    for i := 0; i < len(s); i++ {
+        if c := s[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+            return false
+        }
+    }
+    return true
+}
+
+func hexDigit(c byte) byte {
+    if c >= 'a' {
+        return c - 'a' + 10
+    }
+    return c - '0'
+}
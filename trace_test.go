@@ -0,0 +1,58 @@
+package aralog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tc, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if !ok || tc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tc.SpanID != "00f067aa0ba902b7" || !tc.Sampled {
+		t.Errorf("unexpected trace %+v %v", tc, ok)
+	}
+	for _, bad := range []string{
+		"",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, ok := ParseTraceparent(bad); ok {
+			t.Errorf("%q: expected invalid", bad)
+		}
+	}
+}
+
+func TestParseB3(t *testing.T) {
+	h := http.Header{}
+	h.Set("b3", "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90")
+	if tc, ok := ParseB3(h); !ok || tc.TraceID != "80f198ee56343ba864fe8b2a57d3eff7" || !tc.Sampled {
+		t.Errorf("unexpected trace %+v %v", tc, ok)
+	}
+
+	h = http.Header{}
+	h.Set("X-B3-TraceId", "64FE8B2A57D3EFF7")
+	h.Set("X-B3-SpanId", "e457b5a2e4d86bd1")
+	if tc, ok := ParseB3(h); !ok || tc.TraceID != "000000000000000064fe8b2a57d3eff7" || tc.Sampled {
+		t.Errorf("unexpected trace %+v %v", tc, ok)
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	ring := NewRingSink(4)
+	logger := NewSinkLogger(ring, "", 0)
+	handler := CorrelationMiddleware(logger)(TraceMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handled")
+	})))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "abc")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := "handled correlation_id=abc trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7\n"
+	if entries := ring.Entries(); len(entries) != 1 || string(entries[0].Formatted) != want {
+		t.Errorf("unexpected entries %v", entries)
+	}
+}
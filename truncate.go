@@ -0,0 +1,64 @@
+package aralog
+
+import (
+    "unicode"
+    "unicode/utf8"
+)
+
+// Truncate cuts s to at most n characters, never splitting a multi-byte
+// rune, so the result stays valid UTF-8. If graphemes is set a character is
+// a grapheme cluster, approximated as a base rune followed by combining
+// marks, variation selectors and zero width joined runes, so accents and
+// emoji sequences are kept whole.
+func Truncate(s string, n int, graphemes bool) string {
+    if n <= 0 {
+        return ""
+    }
+    count := 0
+    for i := 0; i < len(s); {
+        if count == n {
+            return s[:i]
+        }
+        i += charLen(s[i:], graphemes)
+        count++
+    }
+    return s
+}
+
+// CharCount returns the number of characters in s, counted like Truncate.
+func CharCount(s string, graphemes bool) int {
+    if !graphemes {
+        return utf8.RuneCountInString(s)
+    }
+    count := 0
+    for i := 0; i < len(s); count++ {
+        i += charLen(s[i:], true)
+    }
+    return count
+}
+
+// charLen returns the byte length of the first character of s.
+func charLen(s string, graphemes bool) int {
+    _, size := utf8.DecodeRuneInString(s)
+    if !graphemes {
+        return size
+    }
+
+    i := size
+    for i < len(s) {
+        r, size := utf8.DecodeRuneInString(s[i:])
+        switch {
+        case r == '\u200d': // zero width joiner, the next rune joins too
+            i += size
+            if i < len(s) {
+                _, size = utf8.DecodeRuneInString(s[i:])
+                i += size
+            }
+        case unicode.In(r, unicode.Mn, unicode.Me) || unicode.Is(unicode.Variation_Selector, r):
+            i += size
+        default:
+            return i
+        }
+    }
+    return i
+}
@@ -0,0 +1,37 @@
+package aralog
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	s := "héllo wörld"
+	if got := Truncate(s, 2, false); got != "hé" {
+		t.Errorf("expected %q, got %q", "hé", got)
+	}
+
+	// e followed by a combining acute accent
+	s = "cafe\u0301!"
+	if got := Truncate(s, 4, false); got != "cafe" {
+		t.Errorf("runes: got %q", got)
+	}
+	if got := Truncate(s, 4, true); got != "cafe\u0301" {
+		t.Errorf("graphemes: got %q", got)
+	}
+	if n := CharCount("\U0001F469\u200d\U0001F4BB ok", true); n != 4 {
+		t.Errorf("expected 4 characters, got %d", n)
+	}
+}
+
+func TestSetMaxMessageLength(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "", 0)
+	logger.SetMaxMessageLength(3)
+	logger.Debug("日本語のテキスト")
+
+	if buf.String() != "日本語…\n" || !utf8.Valid(buf.Bytes()) {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
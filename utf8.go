@@ -0,0 +1,89 @@
+package aralog
+
+import (
+    "fmt"
+    "strings"
+    "unicode/utf8"
+)
+
+// UTF8Mode is the handling of invalid UTF-8 in messages and string fields,
+// see Logger.SetUTF8Mode.
+type UTF8Mode int
+
+const (
+    UTF8Keep    UTF8Mode = iota // write the bytes as they are
+    UTF8Replace                 // replace each invalid sequence with U+FFFD
+    UTF8Escape                  // replace each invalid byte with its escape: \xff
+)
+
+var utf8ModeNames = []string{"keep", "replace", "escape"}
+
+func (m UTF8Mode) String() string {
+    if m >= 0 && int(m) < len(utf8ModeNames) {
+        return utf8ModeNames[m]
+    }
+    return fmt.Sprintf("UTF8Mode(%d)", int(m))
+}
+
+// ParseUTF8Mode parses a UTF8Mode name, case insensitive: keep, replace or
+// escape.
+func ParseUTF8Mode(s string) (UTF8Mode, error) {
+    name := strings.ToLower(strings.TrimSpace(s))
+    for i, n := range utf8ModeNames {
+        if name == n {
+            return UTF8Mode(i), nil
+        }
+    }
+    return UTF8Keep, fmt.Errorf("aralog: unknown UTF-8 mode %q", s)
+}
+
+// SetUTF8Mode sets how the logger handles invalid UTF-8 in messages and
+// string fields, so one bad payload doesn't break the JSON consumers of
+// the log. It keeps the bytes by default.
+func (l *Logger) SetUTF8Mode(mode UTF8Mode) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.utf8Mode = mode
+}
+
+// ValidUTF8 returns s with its invalid UTF-8 handled as mode says.
+func ValidUTF8(s string, mode UTF8Mode) string {
+    if mode == UTF8Keep || utf8.ValidString(s) {
+        return s
+    }
+    if mode == UTF8Replace {
+        return strings.ToValidUTF8(s, "�")
+    }
+
+    var b strings.Builder
+    for i := 0; i < len(s); {
+        r, size := utf8.DecodeRuneInString(s[i:])
+        if r == utf8.RuneError && size == 1 {
+            fmt.Fprintf(&b, `\x%02x`, s[i])
+        } else {
+            b.WriteString(s[i:i + size])
+        }
+        i += size
+    }
+    return b.String()
+}
+
+// validFields returns fields with their invalid string values handled as
+// mode says, fields itself if all are valid.
+func validFields(fields []Field, mode UTF8Mode) []Field {
+    var valid []Field
+    for i, f := range fields {
+        s, ok := f.Value.(string)
+        if !ok || utf8.ValidString(s) {
+            continue
+        }
+        if valid == nil {
+            valid = append([]Field(nil), fields...)
+        }
+        valid[i].Value = ValidUTF8(s, mode)
+    }
+    if valid == nil {
+        return fields
+    }
+    return valid
+}
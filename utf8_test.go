@@ -0,0 +1,43 @@
+package aralog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestValidUTF8(t *testing.T) {
+	bad := "caf\xc3 \xff\xfeok é"
+	tests := []struct {
+		mode UTF8Mode
+		want string
+	}{
+		{UTF8Keep, bad},
+		{UTF8Replace, "caf� �ok é"},
+		{UTF8Escape, `caf\xc3 \xff\xfeok é`},
+	}
+	for _, tt := range tests {
+		if got := ValidUTF8(bad, tt.mode); got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+	if m, err := ParseUTF8Mode("Escape"); err != nil || m != UTF8Escape {
+		t.Errorf("ParseUTF8Mode: %v, %v", m, err)
+	}
+}
+
+func TestSetUTF8Mode(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "", 0)
+	l.SetEncoder(NewJSONEncoder())
+	l.SetUTF8Mode(UTF8Escape)
+	fields := []Field{F("payload", "\xff")}
+	l.With(fields...).Info("got \xc3")
+
+	want := `"msg":"got \\xc3","payload":"\\xff"}`
+	if !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Errorf("got %s, want %s", buf.Bytes(), want)
+	}
+	if fields[0].Value != "\xff" {
+		t.Error("bound fields modified")
+	}
+}
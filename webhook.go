@@ -0,0 +1,153 @@
+package aralog
+
+import (
+    "bytes"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "text/template"
+    "time"
+)
+
+// SlackTemplate is the default payload template of WebhookSink, a Slack
+// incoming webhook message.
+const SlackTemplate = `{"text":{{json (printf "%s on %s: %s" .Level .Host .Message)}}}`
+
+// WebhookSink is a Sink posting critical entries to a webhook, so on-call
+// is paged by the logging layer itself. Entries below MinLevel, fatal by
+// default, are ignored; it is meant to be combined with other sinks by
+// NewMultiSink. The payload is rendered by a text/template from a
+// WebhookData, with a json function encoding its argument as JSON. Each
+// entry is posted before Write returns, so fatal entries are delivered
+// before the process exits.
+type WebhookSink struct {
+    mu       sync.Mutex
+    client   *http.Client
+    url      string
+    host     string       // scheme and host of url, the path of webhooks is a credential
+    tmpl     *template.Template
+    err      error        // last post error
+    MinLevel Level        // lowest level posted
+    Retry    *RetryPolicy // retry failed posts, nil means no retry
+}
+
+// WebhookData is the data the payload template of a WebhookSink is executed
+// with.
+type WebhookData struct {
+    Time    time.Time
+    Level   Level
+    Logger  string
+    Host    string
+    Message string
+    Fields  map[string]interface{}
+}
+
+// NewWebhookSink creates a WebhookSink posting to url the payloads rendered
+// by the template tmpl, SlackTemplate if empty.
+func NewWebhookSink(url, tmpl string) (*WebhookSink, error) {
+    if tmpl == "" {
+        tmpl = SlackTemplate
+    }
+    t, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(tmpl)
+    if err != nil {
+        return nil, fmt.Errorf("aralog: webhook template: %v", err)
+    }
+    return &WebhookSink{client: &http.Client{Timeout: 10 * time.Second}, url: url, host: urlHost(url), tmpl: t, MinLevel: LevelFatal}, nil
+}
+
+// urlHost returns the scheme and host of the URL raw, leaving out the path
+// and query which may hold a token.
+func urlHost(raw string) string {
+    u, err := url.Parse(raw)
+    if err != nil || u.Host == "" {
+        return "[REDACTED]"
+    }
+    return u.Scheme + "://" + u.Host + "/..."
+}
+
+func toJSON(v interface{}) string {
+    var b []byte
+    appendJSON(&b, v)
+    return string(b)
+}
+
+// Write posts e if it is at MinLevel or above.
+func (s *WebhookSink) Write(e Entry) error {
+    if e.Level < s.MinLevel {
+        return nil
+    }
+
+    data := WebhookData{Time: e.Time, Level: e.Level, Logger: e.Logger, Host: hostname, Message: strings.TrimSuffix(e.Message, "\n")}
+    if len(e.Fields) > 0 {
+        data.Fields = map[string]interface{}{}
+        for _, f := range e.Fields {
+            data.Fields[f.Key] = f.Value
+        }
+    }
+    var payload bytes.Buffer
+    if err := s.tmpl.Execute(&payload, data); err != nil {
+        return fmt.Errorf("aralog: webhook template: %v", err)
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    post := func() error {
+        return s.post(payload.Bytes())
+    }
+    if s.Retry != nil {
+        s.err = s.Retry.Do(post)
+    } else {
+        s.err = post()
+    }
+    return s.err
+}
+
+func (s *WebhookSink) post(payload []byte) error {
+    resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(payload))
+    if ue, ok := err.(*url.Error); ok {
+        ue.URL = s.host
+    }
+    if err != nil {
+        return err
+    }
+    resp.Body.Close()
+    if resp.StatusCode >= 300 {
+        return fmt.Errorf("aralog: webhook %s: %s", s.host, resp.Status)
+    }
+    return nil
+}
+
+// Flush does nothing, entries are posted as they are written.
+func (s *WebhookSink) Flush() error {
+    return nil
+}
+
+// Close does nothing.
+func (s *WebhookSink) Close() error {
+    return nil
+}
+
+// Healthy reports whether the last post succeeded.
+func (s *WebhookSink) Healthy() bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err == nil
+}
+
+// LastError returns the error of the last post, nil if it succeeded.
+func (s *WebhookSink) LastError() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err
+}
+
+// Describe describes the sink with the host of its URL and its level.
+func (s *WebhookSink) Describe() SinkDescription {
+    return SinkDescription{
+        Type:     "webhook",
+        Healthy:  s.Healthy(),
+        Settings: map[string]interface{}{"url": s.host, "minLevel": s.MinLevel.String()},
+    }
+}
@@ -0,0 +1,52 @@
+package aralog
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookSink(t *testing.T) {
+	var payloads []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		payloads = append(payloads, string(b))
+	}))
+	defer srv.Close()
+
+	sink, err := NewWebhookSink(srv.URL, `{"level":"{{.Level}}","text":{{json .Message}},"order":{{json .Fields.order}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.MinLevel = LevelError
+	logger := NewSinkLogger(sink, "", 0)
+	logger.Warn("ignored")
+	logger.With(F("order", 7)).Error(`payment "failed"`)
+
+	want := `{"level":"ERROR","text":"payment \"failed\"","order":7}`
+	if len(payloads) != 1 || payloads[0] != want {
+		t.Errorf("got %q, want %q", payloads, want)
+	}
+}
+
+func TestWebhookSinkFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	sink, err := NewWebhookSink(srv.URL+"/services/T000/B000/XXXX", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(Entry{Level: LevelFatal, Message: "down"}); err == nil || sink.Healthy() {
+		t.Errorf("failed post not reported: %v", err)
+	} else if strings.Contains(err.Error(), "XXXX") {
+		t.Errorf("error reveals the webhook path: %v", err)
+	}
+	if u := sink.Describe().Settings["url"]; u != srv.URL+"/..." {
+		t.Errorf("described url %v", u)
+	}
+}
@@ -0,0 +1,239 @@
+package aralog
+
+import (
+    "encoding/json"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// yamlLine is a significant line of a YAML document.
+type yamlLine struct {
+    num    int    // line number, for errors
+    indent int    // leading spaces
+    text   string // content without indentation and comment
+}
+
+// parseYAML parses the block style subset of YAML used by configuration
+// files into maps, slices and scalars: nested mappings, sequences of
+// scalars or mappings, flow sequences of scalars, quoted and plain
+// scalars, and comments. Anchors, multi-line scalars and multiple
+// documents are not supported.
+func parseYAML(doc string) (interface{}, error) {
+    var lines []yamlLine
+    for i, raw := range strings.Split(doc, "\n") {
+        text := strings.TrimRight(stripYAMLComment(raw), " \t\r")
+        trimmed := strings.TrimLeft(text, " ")
+        if trimmed == "" || trimmed == "---" {
+            continue
+        }
+        if strings.HasPrefix(text, "\t") {
+            return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i + 1)
+        }
+        lines = append(lines, yamlLine{num: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+    }
+    if len(lines) == 0 {
+        return map[string]interface{}{}, nil
+    }
+
+    v, rest, err := parseYAMLBlock(lines, lines[0].indent)
+    if err == nil && len(rest) > 0 {
+        err = fmt.Errorf("line %d: unexpected indentation", rest[0].num)
+    }
+    return v, err
+}
+
+// stripYAMLComment removes a # comment which is not inside quotes.
+func stripYAMLComment(s string) string {
+    var quote byte
+    for i := 0; i < len(s); i++ {
+        switch c := s[i]; {
+        case quote != 0:
+            if c == quote {
+                quote = 0
+            }
+        case c == '"' || c == '\'':
+            quote = c
+        case c == '#' && (i == 0 || s[i - 1] == ' ' || s[i - 1] == '\t'):
+            return s[:i]
+        }
+    }
+    return s
+}
+
+// parseYAMLBlock parses the mapping or sequence starting at lines[0], whose
+// lines are indented by indent, and returns the lines after it.
+func parseYAMLBlock(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+    if isYAMLSeqItem(lines[0].text) {
+        return parseYAMLSeq(lines, indent)
+    }
+    return parseYAMLMap(lines, indent)
+}
+
+func isYAMLSeqItem(text string) bool {
+    return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func parseYAMLSeq(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+    seq := []interface{}{}
+    for len(lines) > 0 && lines[0].indent == indent && isYAMLSeqItem(lines[0].text) {
+        item := strings.TrimSpace(strings.TrimPrefix(lines[0].text, "-"))
+        num := lines[0].num
+        lines = lines[1:]
+
+        if item == "" {
+            // the item is the nested block on the next lines
+            if len(lines) == 0 || lines[0].indent <= indent {
+                seq = append(seq, nil)
+                continue
+            }
+            v, rest, err := parseYAMLBlock(lines, lines[0].indent)
+            if err != nil {
+                return nil, nil, err
+            }
+            seq = append(seq, v)
+            lines = rest
+            continue
+        }
+
+        if _, _, ok := splitYAMLKey(item); !ok {
+            v, err := parseYAMLScalar(item)
+            if err != nil {
+                return nil, nil, fmt.Errorf("line %d: %v", num, err)
+            }
+            seq = append(seq, v)
+            continue
+        }
+
+        // "- key: value" starts a mapping indented after the dash
+        inner := indent + len("- ")
+        block := []yamlLine{{num: num, indent: inner, text: item}}
+        for len(lines) > 0 && lines[0].indent > indent {
+            block = append(block, lines[0])
+            lines = lines[1:]
+        }
+        v, rest, err := parseYAMLMap(block, inner)
+        if err != nil {
+            return nil, nil, err
+        }
+        if len(rest) > 0 {
+            return nil, nil, fmt.Errorf("line %d: unexpected indentation", rest[0].num)
+        }
+        seq = append(seq, v)
+    }
+    return seq, lines, nil
+}
+
+func parseYAMLMap(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+    m := map[string]interface{}{}
+    for len(lines) > 0 && lines[0].indent == indent && !isYAMLSeqItem(lines[0].text) {
+        key, value, ok := splitYAMLKey(lines[0].text)
+        if !ok {
+            return nil, nil, fmt.Errorf("line %d: expected key: value", lines[0].num)
+        }
+        num := lines[0].num
+        lines = lines[1:]
+
+        if value != "" {
+            v, err := parseYAMLScalar(value)
+            if err != nil {
+                return nil, nil, fmt.Errorf("line %d: %v", num, err)
+            }
+            m[key] = v
+            continue
+        }
+
+        // nested block, a sequence may also start at the indentation of the key
+        if len(lines) > 0 && (lines[0].indent > indent || lines[0].indent == indent && isYAMLSeqItem(lines[0].text)) {
+            v, rest, err := parseYAMLBlock(lines, lines[0].indent)
+            if err != nil {
+                return nil, nil, err
+            }
+            m[key] = v
+            lines = rest
+        } else {
+            m[key] = nil
+        }
+    }
+    if len(lines) > 0 && lines[0].indent > indent {
+        return nil, nil, fmt.Errorf("line %d: unexpected indentation", lines[0].num)
+    }
+    return m, lines, nil
+}
+
+// splitYAMLKey splits "key: value" or "key:", the key may be quoted.
+func splitYAMLKey(text string) (key, value string, ok bool) {
+    if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") {
+        end := strings.IndexByte(text[1:], text[0])
+        if end < 0 {
+            return "", "", false
+        }
+        key = text[1:end + 1]
+        text = text[end + 2:]
+        if !strings.HasPrefix(text, ":") {
+            return "", "", false
+        }
+        return key, strings.TrimSpace(text[1:]), true
+    }
+
+    i := strings.Index(text, ": ")
+    if i < 0 {
+        if !strings.HasSuffix(text, ":") {
+            return "", "", false
+        }
+        i = len(text) - 1
+    }
+    return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i + 1:]), true
+}
+
+func parseYAMLScalar(s string) (interface{}, error) {
+    switch {
+    case strings.HasPrefix(s, "\""):
+        var v string
+        err := json.Unmarshal([]byte(s), &v)
+        return v, err
+    case strings.HasPrefix(s, "'"):
+        if len(s) < 2 || !strings.HasSuffix(s, "'") {
+            return nil, fmt.Errorf("unterminated string %s", s)
+        }
+        return strings.Replace(s[1:len(s) - 1], "''", "'", -1), nil
+    case strings.HasPrefix(s, "["):
+        if !strings.HasSuffix(s, "]") {
+            return nil, fmt.Errorf("unterminated sequence %s", s)
+        }
+        seq := []interface{}{}
+        inner := strings.TrimSpace(s[1:len(s) - 1])
+        if inner == "" {
+            return seq, nil
+        }
+        for _, item := range splitTOMLList(inner, ',') {
+            v, err := parseYAMLScalar(strings.TrimSpace(item))
+            if err != nil {
+                return nil, err
+            }
+            seq = append(seq, v)
+        }
+        return seq, nil
+    case strings.HasPrefix(s, "{"):
+        if s == "{}" {
+            return map[string]interface{}{}, nil
+        }
+        return nil, fmt.Errorf("flow mappings are not supported")
+    }
+
+    switch s {
+    case "~", "null", "Null", "NULL":
+        return nil, nil
+    case "true", "True", "TRUE":
+        return true, nil
+    case "false", "False", "FALSE":
+        return false, nil
+    }
+    if i, err := parseInteger(s); err == nil {
+        return i, nil
+    }
+    if f, err := strconv.ParseFloat(s, 64); err == nil {
+        return f, nil
+    }
+    return s, nil
+}